@@ -0,0 +1,362 @@
+package asyncapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/openapi"
+)
+
+// Operation actions from the point of view of the documented service
+const (
+	ActionSend    = "send"
+	ActionReceive = "receive"
+)
+
+// maxInlineDepth limits how deep references are expanded in payload schemas
+const maxInlineDepth = 8
+
+// ErrNotAsyncAPI is returned by Parse for documents without an asyncapi key
+var ErrNotAsyncAPI = errors.New("document is not an AsyncAPI specification")
+
+// Document is an AsyncAPI 2.x or 3.x document normalized to channels,
+// operations and messages
+type Document struct {
+	Version    string
+	Title      string
+	AppVersion string
+	Channels   []*Channel
+
+	raw map[string]interface{}
+}
+
+// Channel is a topic, queue or routing key
+type Channel struct {
+	Name        string
+	Address     string
+	Description string
+	Operations  []*Operation
+	Messages    []*Message
+}
+
+// Operation is something the service does on a channel. Action is always
+// expressed from the service's side: AsyncAPI 2.x "subscribe" operations
+// become sends and "publish" operations become receives.
+type Operation struct {
+	ID       string
+	Action   string
+	Keyword  string
+	Summary  string
+	Messages []*Message
+}
+
+// Message is a message definition with its references expanded
+type Message struct {
+	Name        string
+	Title       string
+	Summary     string
+	ContentType string
+	Payload     interface{}
+	Headers     interface{}
+	Examples    []interface{}
+}
+
+// Parse parses an AsyncAPI 2.x or 3.x document from YAML or JSON content
+func Parse(content []byte) (*Document, error) {
+	content, err := openapi.ToYAML(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse AsyncAPI document: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse AsyncAPI document: %w", err)
+	}
+
+	if raw["asyncapi"] == nil {
+		return nil, ErrNotAsyncAPI
+	}
+	version := fmt.Sprint(raw["asyncapi"])
+
+	d := &Document{Version: version, raw: raw}
+	if info := asMap(raw["info"]); info != nil {
+		d.Title = str(info["title"])
+		d.AppVersion = str(info["version"])
+	}
+
+	switch {
+	case strings.HasPrefix(version, "2."):
+		d.parseV2()
+	case strings.HasPrefix(version, "3."):
+		d.parseV3()
+	default:
+		return nil, fmt.Errorf("unsupported AsyncAPI version %s", version)
+	}
+
+	sort.Slice(d.Channels, func(i, j int) bool { return d.Channels[i].Name < d.Channels[j].Name })
+	return d, nil
+}
+
+// FindChannel returns the channel with the given name or address
+func (d *Document) FindChannel(name string) *Channel {
+	for _, ch := range d.Channels {
+		if ch.Name == name || ch.Address == name {
+			return ch
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the channel name or address contains the query
+func (c *Channel) Matches(query string) bool {
+	query = strings.ToLower(query)
+	return strings.Contains(strings.ToLower(c.Name), query) || strings.Contains(strings.ToLower(c.Address), query)
+}
+
+// Actions returns the distinct actions the service performs on the channel
+func (c *Channel) Actions() []string {
+	var actions []string
+	for _, action := range []string{ActionSend, ActionReceive} {
+		for _, op := range c.Operations {
+			if op.Action == action {
+				actions = append(actions, action)
+				break
+			}
+		}
+	}
+	return actions
+}
+
+// MarshalSchema renders an expanded schema as YAML
+func MarshalSchema(schema interface{}) (string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(schema); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// parseV2 reads channels with inline publish/subscribe operations
+func (d *Document) parseV2() {
+	for name, value := range asMap(d.raw["channels"]) {
+		raw := asMap(d.resolve(value))
+		ch := &Channel{Name: name, Address: name, Description: str(raw["description"])}
+
+		for _, keyword := range []string{"subscribe", "publish"} {
+			opRaw := asMap(d.resolve(raw[keyword]))
+			if opRaw == nil {
+				continue
+			}
+			op := &Operation{
+				ID:      str(opRaw["operationId"]),
+				Action:  ActionReceive,
+				Keyword: keyword,
+				Summary: str(opRaw["summary"]),
+			}
+			if keyword == "subscribe" {
+				op.Action = ActionSend
+			}
+
+			msg := opRaw["message"]
+			if oneOf, ok := asMap(d.resolve(msg))["oneOf"].([]interface{}); ok {
+				for _, m := range oneOf {
+					op.Messages = append(op.Messages, d.message(m, ""))
+				}
+			} else if msg != nil {
+				op.Messages = append(op.Messages, d.message(msg, op.ID))
+			}
+
+			ch.Operations = append(ch.Operations, op)
+			ch.Messages = appendMessages(ch.Messages, op.Messages)
+		}
+
+		d.Channels = append(d.Channels, ch)
+	}
+}
+
+// parseV3 reads channels with their messages and attaches the top-level
+// operations that reference them
+func (d *Document) parseV3() {
+	byID := make(map[string]*Channel)
+	for id, value := range asMap(d.raw["channels"]) {
+		raw := asMap(d.resolve(value))
+		ch := &Channel{Name: id, Address: str(raw["address"]), Description: str(raw["description"])}
+		if ch.Address == "" {
+			ch.Address = id
+		}
+
+		messages := asMap(raw["messages"])
+		for _, name := range sortedKeys(messages) {
+			ch.Messages = append(ch.Messages, d.message(messages[name], name))
+		}
+
+		byID[id] = ch
+		d.Channels = append(d.Channels, ch)
+	}
+
+	operations := asMap(d.raw["operations"])
+	for _, id := range sortedKeys(operations) {
+		raw := asMap(d.resolve(operations[id]))
+		ch := byID[refName(str(asMap(raw["channel"])["$ref"]))]
+		if ch == nil {
+			continue
+		}
+
+		op := &Operation{ID: id, Action: str(raw["action"]), Summary: str(raw["summary"])}
+		op.Keyword = op.Action
+
+		refs, _ := raw["messages"].([]interface{})
+		for _, ref := range refs {
+			op.Messages = append(op.Messages, d.message(ref, ""))
+		}
+		if len(refs) == 0 {
+			op.Messages = ch.Messages
+		}
+
+		ch.Operations = append(ch.Operations, op)
+	}
+}
+
+// message expands a message object or reference
+func (d *Document) message(value interface{}, fallback string) *Message {
+	name := fallback
+	if ref := str(asMap(value)["$ref"]); ref != "" {
+		name = refName(ref)
+	}
+
+	raw := asMap(d.resolve(value))
+	if n := str(raw["name"]); n != "" {
+		name = n
+	} else if n := str(raw["messageId"]); n != "" {
+		name = n
+	}
+
+	payload := raw["payload"]
+	// AsyncAPI 3 multi-format schemas wrap the payload
+	if multi := asMap(payload); multi != nil && multi["schemaFormat"] != nil && multi["schema"] != nil {
+		payload = multi["schema"]
+	}
+
+	msg := &Message{
+		Name:        name,
+		Title:       str(raw["title"]),
+		Summary:     str(raw["summary"]),
+		ContentType: str(raw["contentType"]),
+		Payload:     d.inline(payload, 0),
+		Headers:     d.inline(raw["headers"], 0),
+	}
+	if msg.ContentType == "" {
+		msg.ContentType = str(d.raw["defaultContentType"])
+	}
+	if examples, ok := raw["examples"].([]interface{}); ok {
+		msg.Examples = examples
+	}
+	return msg
+}
+
+// resolve follows local $ref pointers until it reaches a non-reference value
+func (d *Document) resolve(value interface{}) interface{} {
+	for i := 0; i < 32; i++ {
+		ref := str(asMap(value)["$ref"])
+		if !strings.HasPrefix(ref, "#/") {
+			return value
+		}
+		value = d.pointer(ref)
+	}
+	return value
+}
+
+// pointer looks up a JSON pointer such as #/components/messages/UserSignedUp
+func (d *Document) pointer(ref string) interface{} {
+	var current interface{} = d.raw
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		if unescaped, err := url.PathUnescape(part); err == nil {
+			part = unescaped
+		}
+		current = asMap(current)[part]
+		if current == nil {
+			return nil
+		}
+	}
+	return current
+}
+
+// inline returns a copy of a schema with local references expanded
+func (d *Document) inline(value interface{}, depth int) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if ref := str(v["$ref"]); strings.HasPrefix(ref, "#/") {
+			if depth >= maxInlineDepth {
+				return v
+			}
+			return d.inline(d.resolve(v), depth+1)
+		}
+		out := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			out[k] = d.inline(child, depth)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, child := range v {
+			out[i] = d.inline(child, depth)
+		}
+		return out
+	}
+	return value
+}
+
+// appendMessages adds messages not already present by name
+func appendMessages(existing, messages []*Message) []*Message {
+	for _, m := range messages {
+		found := false
+		for _, e := range existing {
+			if e.Name == m.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			existing = append(existing, m)
+		}
+	}
+	return existing
+}
+
+func asMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func str(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// refName returns the last segment of a reference
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
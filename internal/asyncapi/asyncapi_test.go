@@ -0,0 +1,187 @@
+package asyncapi
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const v2Spec = `
+asyncapi: 2.6.0
+info: {title: Users, version: 1.0.0}
+defaultContentType: application/json
+channels:
+  user/signedup:
+    description: Sign-ups
+    subscribe:
+      operationId: publishSignup
+      summary: Sent after sign-up
+      message: {$ref: '#/components/messages/UserSignedUp'}
+  user/deleted:
+    publish:
+      operationId: onDelete
+      message:
+        oneOf:
+          - {$ref: '#/components/messages/UserDeleted'}
+          - {name: UserPurged, payload: {type: object}}
+components:
+  messages:
+    UserSignedUp:
+      title: User signed up
+      payload: {$ref: '#/components/schemas/User'}
+      examples:
+        - payload: {id: u1}
+    UserDeleted:
+      messageId: userDeleted
+      contentType: application/avro
+      payload: {type: object}
+  schemas:
+    User:
+      type: object
+      properties:
+        id: {type: string}
+        friend: {$ref: '#/components/schemas/User'}
+`
+
+const v3Spec = `
+asyncapi: 3.0.0
+info: {title: Mailer, version: 2.0.0}
+channels:
+  emails:
+    address: mail.outbound
+    messages:
+      Email: {$ref: '#/components/messages/Email'}
+      Bounce: {payload: {type: object}}
+  audit:
+    messages:
+      Entry: {payload: {type: string}}
+operations:
+  sendEmail:
+    action: send
+    channel: {$ref: '#/channels/emails'}
+    messages:
+      - {$ref: '#/channels/emails/messages/Email'}
+  receiveEmails:
+    action: receive
+    channel: {$ref: '#/channels/emails'}
+  orphan:
+    action: send
+    channel: {$ref: '#/channels/missing'}
+components:
+  messages:
+    Email:
+      payload:
+        schemaFormat: application/vnd.aai.asyncapi+json;version=3.0.0
+        schema: {type: object, properties: {to: {type: string}}}
+`
+
+func parse(t *testing.T, spec string) *Document {
+	t.Helper()
+	doc, err := Parse([]byte(strings.TrimLeft(spec, "\n")))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return doc
+}
+
+func messageNames(messages []*Message) []string {
+	var names []string
+	for _, m := range messages {
+		names = append(names, m.Name)
+	}
+	return names
+}
+
+func TestParseV2(t *testing.T) {
+	doc := parse(t, v2Spec)
+	if doc.Version != "2.6.0" || doc.Title != "Users" || doc.AppVersion != "1.0.0" {
+		t.Errorf("document = %+v", doc)
+	}
+	if len(doc.Channels) != 2 || doc.Channels[0].Name != "user/deleted" {
+		t.Fatalf("channels = %+v", doc.Channels)
+	}
+
+	signup := doc.FindChannel("user/signedup")
+	if signup.Description != "Sign-ups" || !reflect.DeepEqual(signup.Actions(), []string{ActionSend}) {
+		t.Errorf("signup channel = %+v", signup)
+	}
+	op := signup.Operations[0]
+	if op.ID != "publishSignup" || op.Keyword != "subscribe" || op.Summary != "Sent after sign-up" {
+		t.Errorf("operation = %+v", op)
+	}
+	msg := op.Messages[0]
+	if msg.Name != "UserSignedUp" || msg.Title != "User signed up" || msg.ContentType != "application/json" || len(msg.Examples) != 1 {
+		t.Errorf("message = %+v", msg)
+	}
+	properties, _ := msg.Payload.(map[string]interface{})["properties"].(map[string]interface{})
+	if properties["id"] == nil || properties["friend"].(map[string]interface{})["type"] != "object" {
+		t.Errorf("payload = %v", msg.Payload)
+	}
+
+	deleted := doc.FindChannel("user/deleted")
+	if !reflect.DeepEqual(deleted.Actions(), []string{ActionReceive}) {
+		t.Errorf("actions = %v", deleted.Actions())
+	}
+	if got := messageNames(deleted.Messages); !reflect.DeepEqual(got, []string{"userDeleted", "UserPurged"}) {
+		t.Errorf("messages = %v", got)
+	}
+	if ct := deleted.Messages[0].ContentType; ct != "application/avro" {
+		t.Errorf("content type = %s", ct)
+	}
+}
+
+func TestParseV3(t *testing.T) {
+	doc := parse(t, v3Spec)
+
+	emails := doc.FindChannel("mail.outbound")
+	if emails == nil || emails.Name != "emails" {
+		t.Fatalf("channels = %+v", doc.Channels)
+	}
+	if got := messageNames(emails.Messages); !reflect.DeepEqual(got, []string{"Bounce", "Email"}) {
+		t.Errorf("channel messages = %v", got)
+	}
+	if !reflect.DeepEqual(emails.Actions(), []string{ActionSend, ActionReceive}) {
+		t.Errorf("actions = %v", emails.Actions())
+	}
+
+	ops := make(map[string][]string)
+	for _, op := range emails.Operations {
+		ops[op.ID] = messageNames(op.Messages)
+	}
+	want := map[string][]string{"receiveEmails": {"Bounce", "Email"}, "sendEmail": {"Email"}}
+	if !reflect.DeepEqual(ops, want) {
+		t.Errorf("operations = %v, want %v", ops, want)
+	}
+
+	// Multi-format payloads are unwrapped to their schema
+	payload, _ := emails.Messages[1].Payload.(map[string]interface{})
+	if payload["type"] != "object" || payload["schemaFormat"] != nil {
+		t.Errorf("payload = %v", payload)
+	}
+
+	if audit := doc.FindChannel("audit"); audit == nil || audit.Address != "audit" || len(audit.Operations) != 0 {
+		t.Errorf("audit channel = %+v", audit)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse([]byte("openapi: 3.0.3\n")); !errors.Is(err, ErrNotAsyncAPI) {
+		t.Errorf("OpenAPI document error = %v", err)
+	}
+	if _, err := Parse([]byte("asyncapi: 1.2.0\n")); err == nil || errors.Is(err, ErrNotAsyncAPI) {
+		t.Errorf("unsupported version error = %v", err)
+	}
+	if _, err := Parse([]byte("asyncapi: [")); err == nil {
+		t.Error("Parse accepted invalid YAML")
+	}
+}
+
+func TestChannelMatches(t *testing.T) {
+	ch := &Channel{Name: "emails", Address: "Mail.Outbound"}
+	for query, want := range map[string]bool{"EMAIL": true, "outbound": true, "users": false} {
+		if got := ch.Matches(query); got != want {
+			t.Errorf("Matches(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
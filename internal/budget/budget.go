@@ -0,0 +1,239 @@
+package budget
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CharsPerToken is the rough ratio used to turn a token budget into characters
+const CharsPerToken = 4
+
+// minCollapseDepth is the shallowest level at which YAML nodes are collapsed
+const minCollapseDepth = 2
+
+// jsonBlock matches fenced JSON code blocks, which hold examples in Markdown output
+var jsonBlock = regexp.MustCompile("(?s)```json\n.*?```\n?")
+
+// Text fits text into max characters. Example code blocks are elided first;
+// if that is not enough the text is cut at a line boundary. A notice with
+// the hint is appended whenever anything was removed; on small budgets it is
+// shortened or dropped so that the text keeps at least half of the budget.
+func Text(text string, max int, hint string) (string, bool) {
+	if max <= 0 || len(text) <= max {
+		return text, false
+	}
+
+	elided := jsonBlock.ReplaceAllString(text, "_(example elided)_\n")
+	notice := fitNotice(len(text), max, hint)
+	if len(elided)+len(notice) <= max {
+		return elided + notice, true
+	}
+
+	return cut(elided, max-len(notice)) + notice, true
+}
+
+// YAML fits a YAML document into max characters by eliding examples, then
+// collapsing the deepest nodes level by level. Collapsed nodes name the
+// pointer to read them with. As a last resort the document is cut.
+func YAML(content []byte, max int, hint string) ([]byte, bool, error) {
+	if max <= 0 || len(content) <= max {
+		return content, false, nil
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, false, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	notice := fitNotice(len(content), max, hint)
+
+	elideExamples(&root)
+	out, err := encode(&root)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for depth := maxDepth(&root, 0); len(out)+len(notice) > max && depth >= minCollapseDepth; depth-- {
+		collapse(&root, "", 0, depth)
+		if out, err = encode(&root); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if len(out)+len(notice) > max {
+		out = []byte(cut(string(out), max-len(notice)))
+	}
+	return append(out, []byte(notice)...), true, nil
+}
+
+// Select returns the YAML subtree at a JSON pointer such as
+// /paths/~1cards/get or /components/schemas/Card
+func Select(content []byte, pointer string) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	node := &root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for _, part := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		node = child(node, part)
+		if node == nil {
+			return nil, fmt.Errorf("pointer %s not found", pointer)
+		}
+	}
+	return encode(node)
+}
+
+// Notice explains what was cut and how to get the rest
+func Notice(size, max int, hint string) string {
+	notice := fmt.Sprintf("\n\n✂️ Output truncated: %d characters (~%d tokens) exceeded the budget of %d characters.", size, size/CharsPerToken, max)
+	if hint != "" {
+		notice += " " + hint
+	}
+	return notice + "\n"
+}
+
+// fitNotice returns the longest notice taking at most half of the budget:
+// the full notice, a short one without the hint, or none at all
+func fitNotice(size, max int, hint string) string {
+	if notice := Notice(size, max, hint); len(notice) <= max/2 {
+		return notice
+	}
+	if notice := fmt.Sprintf("\n✂️ Truncated from %d characters\n", size); len(notice) <= max/2 {
+		return notice
+	}
+	return ""
+}
+
+// cut shortens text to at most max characters, ending on a full line when
+// there is one and never splitting a UTF-8 sequence
+func cut(text string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+	if len(text) <= max {
+		return text
+	}
+	for max > 0 && !utf8.RuneStart(text[max]) {
+		max--
+	}
+	text = text[:max]
+	if i := strings.LastIndex(text, "\n"); i > 0 {
+		return text[:i+1]
+	}
+	return text
+}
+
+// child returns the value of a mapping key or sequence index
+func child(node *yaml.Node, key string) *yaml.Node {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return node.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		var index int
+		if _, err := fmt.Sscanf(key, "%d", &index); err == nil && index >= 0 && index < len(node.Content) {
+			return node.Content[index]
+		}
+	}
+	return nil
+}
+
+// elideExamples replaces example values with a placeholder
+func elideExamples(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			if (key == "example" || key == "examples" || key == "x-example") && !isScalar(value) {
+				*value = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "(example elided)"}
+			}
+		}
+	}
+	for _, c := range node.Content {
+		elideExamples(c)
+	}
+}
+
+// collapse replaces non-scalar nodes at the given depth with a placeholder
+// naming the pointer of the collapsed value
+func collapse(node *yaml.Node, pointer string, depth, at int) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, c := range node.Content {
+			collapse(c, pointer, depth, at)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			childPointer := pointer + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+			if depth+1 >= at && !isScalar(value) {
+				*value = placeholder(value, childPointer)
+				continue
+			}
+			collapse(value, childPointer, depth+1, at)
+		}
+	case yaml.SequenceNode:
+		for i, value := range node.Content {
+			childPointer := fmt.Sprintf("%s/%d", pointer, i)
+			if depth+1 >= at && !isScalar(value) {
+				*value = placeholder(value, childPointer)
+				continue
+			}
+			collapse(value, childPointer, depth+1, at)
+		}
+	}
+}
+
+// placeholder summarizes a collapsed node
+func placeholder(node *yaml.Node, pointer string) yaml.Node {
+	size := len(node.Content)
+	if node.Kind == yaml.MappingNode {
+		size /= 2
+	}
+	entries := "entries"
+	if size == 1 {
+		entries = "entry"
+	}
+	return yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: fmt.Sprintf("(%d %s collapsed, read with pointer=%s)", size, entries, pointer)}
+}
+
+func maxDepth(node *yaml.Node, depth int) int {
+	deepest := depth
+	for _, c := range node.Content {
+		if d := maxDepth(c, depth+1); d > deepest {
+			deepest = d
+		}
+	}
+	return deepest
+}
+
+func isScalar(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode || node.Kind == yaml.AliasNode
+}
+
+func encode(node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
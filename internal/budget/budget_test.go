@@ -0,0 +1,111 @@
+package budget
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+const spec = `openapi: 3.0.3
+info:
+  title: Cards API
+  version: 1.0.0
+paths:
+  /cards:
+    get:
+      summary: List cards
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              example:
+                cards:
+                  - {id: c1, name: Travel, limit: 1000, currency: EUR}
+                  - {id: c2, name: Groceries, limit: 500, currency: EUR}
+                  - {id: c3, name: Fuel, limit: 300, currency: EUR}
+`
+
+func TestText(t *testing.T) {
+	text := strings.Repeat("line of text\n", 40) + "```json\n{\"id\": 1}\n```\n"
+
+	tests := []struct {
+		name    string
+		max     int
+		want    []string
+		notWant []string
+	}{
+		{name: "within budget", max: 0, want: []string{"line of text", `"id": 1`}, notWant: []string{"✂️"}},
+		{name: "large budget", max: 400, want: []string{"line of text", "Output truncated", "the hint"}},
+		{name: "small budget", max: 120, want: []string{"line of text", "Truncated from"}, notWant: []string{"the hint"}},
+		{name: "tiny budget", max: 40, want: []string{"line of text"}, notWant: []string{"✂️"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := Text(text, tt.max, "the hint")
+			if truncated != (tt.max > 0) {
+				t.Errorf("truncated = %v", truncated)
+			}
+			if tt.max > 0 && len(got) > tt.max {
+				t.Errorf("got %d characters, over the budget of %d", len(got), tt.max)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("output lacks %q:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("output contains %q:\n%s", notWant, got)
+				}
+			}
+		})
+	}
+}
+
+func TestYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		max  int
+		want []string
+	}{
+		{name: "examples elided", max: 450, want: []string{"(example elided)", "description: OK"}},
+		{name: "deep nodes collapsed", max: 260, want: []string{"read with pointer=/paths/~1cards", "Output truncated"}},
+		{name: "small budget", max: 100, want: []string{"openapi: 3.0.3", "Truncated from"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated, err := YAML([]byte(spec), tt.max, "the hint")
+			if err != nil {
+				t.Fatalf("YAML: %v", err)
+			}
+			if !truncated || len(got) > tt.max {
+				t.Errorf("truncated = %v with %d characters, budget %d", truncated, len(got), tt.max)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(got), want) {
+					t.Errorf("output lacks %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCut(t *testing.T) {
+	if got := cut("first\nsecond\n", 9); got != "first\n" {
+		t.Errorf("cut at a line = %q", got)
+	}
+	if got := cut("ñññ", 3); got != "ñ" || !utf8.ValidString(got) {
+		t.Errorf("cut within a rune = %q", got)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	got, err := Select([]byte(spec), "/paths/~1cards/get/summary")
+	if err != nil || strings.TrimSpace(string(got)) != "List cards" {
+		t.Errorf("Select = %q, %v", got, err)
+	}
+	if _, err := Select([]byte(spec), "/paths/~1missing"); err == nil {
+		t.Error("Select found a missing pointer")
+	}
+}
@@ -0,0 +1,629 @@
+package codegen
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/openapi"
+)
+
+// goClientRuntime is the fixed part of every generated client
+const goClientRuntime = `
+// Client calls the API over HTTP
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client for the given base URL
+func NewClient(baseURL string) *Client {
+	return &Client{BaseURL: baseURL, HTTPClient: http.DefaultClient}
+}
+
+// APIError is returned when the API answers with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error: status %d: %s", e.StatusCode, e.Body)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, body, out interface{}) error {
+	target := strings.TrimSuffix(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &APIError{StatusCode: resp.StatusCode, Body: data}
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+`
+
+// goInitialisms are rendered in upper case inside Go identifiers
+var goInitialisms = map[string]bool{
+	"ID": true, "URL": true, "URI": true, "HTTP": true, "API": true,
+	"JSON": true, "UUID": true, "IP": true, "SQL": true, "XML": true,
+}
+
+// goRuntimeNames are the package-level identifiers of goClientRuntime
+var goRuntimeNames = []string{"Client", "NewClient", "APIError"}
+
+// goClientMembers are the fields and methods of the runtime Client, which
+// operation methods must not reuse
+var goClientMembers = []string{"BaseURL", "HTTPClient", "do"}
+
+// goGenerator accumulates type declarations while walking schemas
+type goGenerator struct {
+	doc      *openapi.Document
+	decls    []string
+	declared map[string]bool
+	imports  map[string]bool
+
+	taken      map[string]bool            // Package-level identifiers in use
+	methods    map[string]bool            // Client fields and methods in use
+	components map[string]string          // Go type name of each component schema
+	inline     map[*openapi.Schema]string // Go type name of each inline schema declared
+}
+
+// GoClient generates Go types and a client with one method per operation.
+// The output is gofmt'ed, which only checks its syntax; an error means the
+// generator produced code that does not parse.
+func GoClient(doc *openapi.Document, ops []openapi.OperationRef, pkg string) (string, error) {
+	if pkg == "" {
+		pkg = "client"
+	}
+	g := &goGenerator{
+		doc:      doc,
+		declared: make(map[string]bool),
+		imports: map[string]bool{
+			"bytes": true, "context": true, "encoding/json": true, "fmt": true,
+			"io": true, "net/http": true, "net/url": true, "strings": true,
+		},
+		taken:      make(map[string]bool),
+		methods:    make(map[string]bool),
+		components: make(map[string]string),
+		inline:     make(map[*openapi.Schema]string),
+	}
+	for _, name := range goRuntimeNames {
+		g.taken[name] = true
+	}
+	for _, name := range goClientMembers {
+		g.methods[name] = true
+	}
+
+	// Components claim their names first, so inline types that collide
+	// with them are the ones renamed
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g.components[name] = g.unique(GoName(name))
+	}
+
+	var methods strings.Builder
+	for _, op := range ops {
+		methods.WriteString(g.operation(op))
+	}
+
+	var b strings.Builder
+	if doc.Info.Title != "" {
+		b.WriteString(fmt.Sprintf("// Package %s is a client for %s %s.\n", pkg, doc.Info.Title, doc.Info.Version))
+	}
+	b.WriteString("// Code generated by s3-mcp-server. DO NOT EDIT.\n\n")
+	b.WriteString(fmt.Sprintf("package %s\n\nimport (\n", pkg))
+	imports := make([]string, 0, len(g.imports))
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	for _, imp := range imports {
+		b.WriteString(fmt.Sprintf("\t%q\n", imp))
+	}
+	b.WriteString(")\n")
+	b.WriteString(goClientRuntime)
+	for _, decl := range g.decls {
+		b.WriteString("\n")
+		b.WriteString(decl)
+	}
+	b.WriteString(methods.String())
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return b.String(), fmt.Errorf("generated code is not valid Go: %w", err)
+	}
+	return string(src), nil
+}
+
+// operation renders the params type and client method for an operation
+func (g *goGenerator) operation(op openapi.OperationRef) string {
+	name := uniqueIn(g.methods, OperationName(op))
+	params := g.doc.Parameters(op)
+
+	var b strings.Builder
+	var args []string
+
+	fieldNames := make([]string, len(params))
+	fieldTypes := make([]string, len(params))
+	if len(params) > 0 {
+		paramsType := g.unique(name + "Params")
+		var fields strings.Builder
+		taken := make(map[string]bool)
+		for i, p := range params {
+			fieldNames[i] = uniqueIn(taken, GoName(p.Name))
+			fieldTypes[i] = g.typeFor(p.Schema, paramsType+fieldNames[i])
+			fieldType := fieldTypes[i]
+			if !p.Required && p.In != "path" && !isReferenceType(fieldType) {
+				fieldType = "*" + fieldType
+			}
+			if p.Description != "" {
+				fields.WriteString(goComment(p.Description, "\t"))
+			}
+			fields.WriteString(fmt.Sprintf("\t%s %s\n", fieldNames[i], fieldType))
+		}
+		g.decls = append(g.decls, fmt.Sprintf("// %s holds the parameters of %s\ntype %s struct {\n%s}\n", paramsType, name, paramsType, fields.String()))
+		args = append(args, "params *"+paramsType)
+	}
+
+	bodyType := ""
+	if body := g.doc.ResolveRequestBody(op.Operation.RequestBody); body != nil {
+		if contentType, mt := openapi.JSONMediaType(body.Content); mt != nil && strings.Contains(contentType, "json") && mt.Schema != nil {
+			bodyType = g.typeFor(mt.Schema, name+"Request")
+			args = append(args, "body "+pointerTo(bodyType))
+		}
+	}
+
+	responseType := ""
+	if _, resp := SuccessResponse(g.doc, op.Operation); resp != nil {
+		if contentType, mt := openapi.JSONMediaType(resp.Content); mt != nil && strings.Contains(contentType, "json") && mt.Schema != nil {
+			responseType = g.typeFor(mt.Schema, name+"Response")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf("// %s calls %s %s.\n", name, op.Method, op.Path))
+	if op.Operation.Summary != "" {
+		b.WriteString("//\n" + goComment(op.Operation.Summary, ""))
+	}
+	if op.Operation.Deprecated {
+		b.WriteString("//\n// Deprecated: the operation is marked deprecated in the spec.\n")
+	}
+
+	returns := "error"
+	if responseType != "" {
+		returns = fmt.Sprintf("(%s, error)", pointerTo(responseType))
+	}
+	b.WriteString(fmt.Sprintf("func (c *Client) %s(%s) %s {\n", name, strings.Join(append([]string{"ctx context.Context"}, args...), ", "), returns))
+
+	b.WriteString(fmt.Sprintf("\tpath := %q\n", op.Path))
+	b.WriteString("\tquery := url.Values{}\n\theader := http.Header{}\n")
+	for i, p := range params {
+		field := "params." + fieldNames[i]
+		fieldType := fieldTypes[i]
+		optional := !p.Required && p.In != "path" && !isReferenceType(fieldType)
+		repeated := strings.HasPrefix(fieldType, "[]") && p.In != "path"
+		value := paramString(field, fieldType)
+		switch {
+		case repeated:
+			value = paramString("v", strings.TrimPrefix(fieldType, "[]"))
+		case optional:
+			value = paramString("*"+field, fieldType)
+		}
+
+		var set string
+		switch p.In {
+		case "path":
+			set = fmt.Sprintf("path = strings.ReplaceAll(path, %q, url.PathEscape(%s))", "{"+p.Name+"}", value)
+		case "query":
+			set = fmt.Sprintf("query.Add(%q, %s)", p.Name, value)
+		case "header":
+			set = fmt.Sprintf("header.Add(%q, %s)", p.Name, value)
+		case "cookie":
+			set = fmt.Sprintf("header.Add(\"Cookie\", %q+%s)", p.Name+"=", value)
+		default:
+			continue
+		}
+
+		switch {
+		case repeated:
+			b.WriteString(fmt.Sprintf("\tfor _, v := range %s {\n\t\t%s\n\t}\n", field, set))
+		case optional:
+			b.WriteString(fmt.Sprintf("\tif %s != nil {\n\t\t%s\n\t}\n", field, set))
+		default:
+			b.WriteString("\t" + set + "\n")
+		}
+	}
+
+	bodyArg := "nil"
+	if bodyType != "" {
+		bodyArg = "body"
+	}
+	if responseType != "" {
+		b.WriteString(fmt.Sprintf("\tvar out %s\n", responseType))
+		b.WriteString(fmt.Sprintf("\tif err := c.do(ctx, %q, path, query, header, %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n", op.Method, bodyArg))
+		if isReferenceType(responseType) {
+			b.WriteString("\treturn out, nil\n}\n")
+		} else {
+			b.WriteString("\treturn &out, nil\n}\n")
+		}
+	} else {
+		b.WriteString(fmt.Sprintf("\treturn c.do(ctx, %q, path, query, header, %s, nil)\n}\n", op.Method, bodyArg))
+	}
+
+	return b.String()
+}
+
+// typeFor returns the Go type for a schema, declaring named types for
+// components and inline objects as needed
+func (g *goGenerator) typeFor(s *openapi.Schema, context string) string {
+	if s == nil {
+		return "interface{}"
+	}
+	if s.Ref != "" {
+		return g.component(openapi.RefName(s.Ref))
+	}
+
+	switch {
+	case len(s.OneOf) > 0 || len(s.AnyOf) > 0:
+		g.imports["encoding/json"] = true
+		return "json.RawMessage"
+	case len(s.AllOf) > 0 || len(s.Properties) > 0 || (len(s.Enum) > 0 && s.Type == "string"):
+		if name, ok := g.inline[s]; ok {
+			return name
+		}
+		name := g.unique(context)
+		g.inline[s] = name
+		g.declare(name, s)
+		return name
+	}
+
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			g.imports["time"] = true
+			return "time.Time"
+		}
+		return "string"
+	case "integer":
+		if s.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if s.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + g.typeFor(s.Items, context+"Item")
+	case "object":
+		if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+			return "map[string]" + g.typeFor(s.AdditionalProperties.Schema, context+"Value")
+		}
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
+
+// component declares the Go type for a named component schema and returns
+// its name
+func (g *goGenerator) component(name string) string {
+	typeName, ok := g.components[name]
+	if !ok {
+		typeName = g.unique(GoName(name))
+		g.components[name] = typeName
+	}
+	if g.declared[typeName] {
+		return typeName
+	}
+	schema := g.doc.Components.Schemas[name]
+	if schema == nil {
+		g.declared[typeName] = true
+		g.decls = append(g.decls, fmt.Sprintf("// %s is referenced by the spec but not defined\ntype %s = interface{}\n", typeName, typeName))
+		return typeName
+	}
+	if schema.Ref != "" {
+		g.declared[typeName] = true
+		target := g.typeFor(schema, typeName)
+		g.decls = append(g.decls, fmt.Sprintf("// %s is an alias of %s\ntype %s = %s\n", typeName, target, typeName, target))
+		return typeName
+	}
+	g.declare(typeName, schema)
+	return typeName
+}
+
+// unique claims a package-level identifier, adding a numeric suffix when
+// base is already in use
+func (g *goGenerator) unique(base string) string {
+	return uniqueIn(g.taken, base)
+}
+
+// uniqueIn claims an identifier in a scope such as a struct or the Client
+// method set, adding a numeric suffix when base is already in use
+func uniqueIn(taken map[string]bool, base string) string {
+	name := base
+	for i := 2; taken[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	taken[name] = true
+	return name
+}
+
+// declare emits a named type declaration for a schema
+func (g *goGenerator) declare(name string, s *openapi.Schema) {
+	if g.declared[name] {
+		return
+	}
+	g.declared[name] = true
+
+	var b strings.Builder
+	if s.Description != "" {
+		b.WriteString(goComment(fmt.Sprintf("%s %s", name, s.Description), ""))
+	} else {
+		b.WriteString(fmt.Sprintf("// %s is generated from the spec\n", name))
+	}
+
+	if len(s.Enum) > 0 && s.Type == "string" {
+		b.WriteString(fmt.Sprintf("type %s string\n\nconst (\n", name))
+		seen := make(map[string]bool)
+		for _, v := range s.Enum {
+			value := fmt.Sprint(v)
+			if value == "" || seen[value] {
+				continue
+			}
+			seen[value] = true
+			constName := g.unique(name + GoName(value))
+			b.WriteString(fmt.Sprintf("\t%s %s = %q\n", constName, name, value))
+		}
+		b.WriteString(")\n")
+		g.decls = append(g.decls, b.String())
+		return
+	}
+
+	if len(s.AllOf) == 0 && len(s.Properties) == 0 && s.Type != "object" {
+		// Reserve the slot before recursing so declarations stay in order
+		index := len(g.decls)
+		g.decls = append(g.decls, "")
+		underlying := g.typeFor(s, name+"Value")
+		b.WriteString(fmt.Sprintf("type %s %s\n", name, underlying))
+		g.decls[index] = b.String()
+		return
+	}
+
+	properties, required := g.collectProperties(s)
+	index := len(g.decls)
+	g.decls = append(g.decls, "")
+
+	var fields strings.Builder
+	fieldNames := make(map[string]bool)
+	propNames := make([]string, 0, len(properties))
+	for prop := range properties {
+		propNames = append(propNames, prop)
+	}
+	sort.Strings(propNames)
+	for _, prop := range propNames {
+		schema := properties[prop]
+		fieldName := uniqueIn(fieldNames, GoName(prop))
+		fieldType := g.typeFor(schema, name+fieldName)
+		resolved := g.doc.ResolveSchema(schema)
+
+		isRequired := required[prop]
+		nullable := resolved != nil && resolved.Nullable
+		if (!isRequired || nullable || fieldType == name) && !isReferenceType(fieldType) {
+			fieldType = "*" + fieldType
+		}
+		tag := prop
+		if !isRequired {
+			tag += ",omitempty"
+		}
+		if resolved != nil && resolved.Description != "" {
+			fields.WriteString(goComment(resolved.Description, "\t"))
+		}
+		if resolved != nil && resolved.Deprecated {
+			fields.WriteString("\t// Deprecated: marked deprecated in the spec.\n")
+		}
+		fields.WriteString(fmt.Sprintf("\t%s %s `json:%q`\n", fieldName, fieldType, tag))
+	}
+	if len(propNames) == 0 {
+		valueType := "interface{}"
+		if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+			valueType = g.typeFor(s.AdditionalProperties.Schema, name+"Value")
+		}
+		b.WriteString(fmt.Sprintf("type %s map[string]%s\n", name, valueType))
+		g.decls[index] = b.String()
+		return
+	}
+
+	b.WriteString(fmt.Sprintf("type %s struct {\n%s}\n", name, fields.String()))
+	g.decls[index] = b.String()
+}
+
+// collectProperties flattens allOf members into a single property set
+func (g *goGenerator) collectProperties(s *openapi.Schema) (map[string]*openapi.Schema, map[string]bool) {
+	properties := make(map[string]*openapi.Schema)
+	required := make(map[string]bool)
+
+	var walk func(schema *openapi.Schema, depth int)
+	walk = func(schema *openapi.Schema, depth int) {
+		schema = g.doc.ResolveSchema(schema)
+		if schema == nil || depth > 16 {
+			return
+		}
+		for _, sub := range schema.AllOf {
+			walk(sub, depth+1)
+		}
+		for name, prop := range schema.Properties {
+			properties[name] = prop
+		}
+		for _, name := range schema.Required {
+			required[name] = true
+		}
+	}
+	walk(s, 0)
+	return properties, required
+}
+
+// SuccessResponse returns the first 2xx response of an operation, falling
+// back to the default response
+func SuccessResponse(doc *openapi.Document, op *openapi.Operation) (string, *openapi.Response) {
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if strings.HasPrefix(code, "2") {
+			return code, doc.ResolveResponse(op.Responses[code])
+		}
+	}
+	if resp, ok := op.Responses["default"]; ok {
+		return "default", doc.ResolveResponse(resp)
+	}
+	return "", nil
+}
+
+// OperationName returns the exported Go name for an operation, derived from
+// its operationId or from the method and path
+func OperationName(op openapi.OperationRef) string {
+	if op.Operation.OperationID != "" {
+		return GoName(op.Operation.OperationID)
+	}
+	name := GoName(strings.ToLower(op.Method))
+	for _, segment := range strings.Split(op.Path, "/") {
+		if strings.HasPrefix(segment, "{") {
+			name += "By" + GoName(strings.Trim(segment, "{}"))
+		} else {
+			name += GoName(segment)
+		}
+	}
+	return name
+}
+
+// GoName converts an arbitrary spec identifier to an exported Go identifier
+func GoName(s string) string {
+	var words []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = nil
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		isLetter := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		isDigit := r >= '0' && r <= '9'
+		if !isLetter && !isDigit {
+			flush()
+			continue
+		}
+		if r >= 'A' && r <= 'Z' && i > 0 {
+			prev := runes[i-1]
+			if prev >= 'a' && prev <= 'z' {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+
+	var b strings.Builder
+	for _, word := range words {
+		upper := strings.ToUpper(word)
+		if goInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+
+	name := b.String()
+	if name == "" {
+		return "Value"
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "N" + name
+	}
+	return name
+}
+
+// paramString returns the expression formatting a parameter value of the
+// given Go type for a URL or header. Times are sent as RFC 3339.
+func paramString(value, goType string) string {
+	if goType == "time.Time" {
+		if strings.HasPrefix(value, "*") {
+			value = "(" + value + ")"
+		}
+		return value + ".Format(time.RFC3339)"
+	}
+	return "fmt.Sprint(" + value + ")"
+}
+
+// isReferenceType reports whether a Go type already has a nil value
+func isReferenceType(t string) bool {
+	return strings.HasPrefix(t, "[]") || strings.HasPrefix(t, "map[") ||
+		strings.HasPrefix(t, "*") || t == "interface{}" || t == "json.RawMessage"
+}
+
+// pointerTo returns a pointer type unless the type is already nillable
+func pointerTo(t string) string {
+	if isReferenceType(t) {
+		return t
+	}
+	return "*" + t
+}
+
+// goComment renders text as a line comment with the given indent
+func goComment(text, indent string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		b.WriteString(indent + "// " + strings.TrimSpace(line) + "\n")
+	}
+	return b.String()
+}
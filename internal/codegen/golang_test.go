@@ -0,0 +1,225 @@
+package codegen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+// typeCheck compiles generated Go source against the standard library
+func typeCheck(t *testing.T, src string) *types.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "client.go", src, 0)
+	if err != nil {
+		t.Fatalf("parse: %v\n%s", err, src)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("client", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatalf("generated client does not compile: %v\n%s", err, src)
+	}
+	return pkg
+}
+
+const goClientSpec = `
+openapi: 3.0.3
+info: {title: Graph, version: '2'}
+paths:
+  /nodes/{id}:
+    get:
+      operationId: getNode
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+        - {name: since, in: query, schema: {type: string, format: date-time}}
+        - {name: at, in: header, required: true, schema: {type: string, format: date-time}}
+        - {name: seen, in: query, schema: {type: array, items: {type: string, format: date-time}}}
+        - {name: kind, in: query, schema: {type: string, enum: [leaf, branch]}}
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Node'}
+  /nodes:
+    post:
+      operationId: createNode
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                kind: {$ref: '#/components/schemas/NodeKind'}
+      responses:
+        '201':
+          description: Created
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Client'}
+components:
+  schemas:
+    Node:
+      type: object
+      required: [kind]
+      properties:
+        kind: {type: string, enum: [root, inner]}
+        type: {$ref: '#/components/schemas/NodeKind'}
+        parent: {$ref: '#/components/schemas/Node'}
+    NodeKind:
+      type: string
+      enum: [leaf, branch]
+    Client:
+      type: object
+      properties:
+        name: {type: string}
+`
+
+func TestGoClient(t *testing.T) {
+	doc := parseSpec(t, goClientSpec)
+	src, err := GoClient(doc, doc.Operations(), "graph")
+	if err != nil {
+		t.Fatalf("GoClient: %v\n%s", err, src)
+	}
+	pkg := typeCheck(t, src)
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "required time parameter", want: `header.Add("at", params.At.Format(time.RFC3339))`},
+		{name: "optional time parameter", want: `query.Add("since", (*params.Since).Format(time.RFC3339))`},
+		{name: "repeated time parameter", want: `query.Add("seen", v.Format(time.RFC3339))`},
+		{name: "other parameters", want: `url.PathEscape(fmt.Sprint(params.ID))`},
+		{name: "inline enum renamed", want: "Kind NodeKind2 `json:\"kind\"`"},
+		{name: "component enum kept", want: "Type *NodeKind `json:\"type,omitempty\"`"},
+		{name: "component enum constants", want: `NodeKindLeaf NodeKind = "leaf"`},
+		{name: "inline enum constants", want: `NodeKind2Root NodeKind2 = "root"`},
+		{name: "inline parameter enum", want: `Kind *GetNodeParamsKind`},
+		{name: "component named like the runtime", want: `(*Client2, error)`},
+		{name: "self reference", want: "Parent *Node `json:\"parent,omitempty\"`"},
+	}
+
+	// gofmt aligns fields and constants, so compare with spaces collapsed
+	flat := strings.Join(strings.Fields(src), " ")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(flat, tt.want) {
+				t.Errorf("generated client lacks %s:\n%s", tt.want, src)
+			}
+		})
+	}
+
+	for _, name := range []string{"Node", "NodeKind", "NodeKind2", "Client", "Client2", "GetNodeParams", "CreateNodeRequest"} {
+		if pkg.Scope().Lookup(name) == nil {
+			t.Errorf("generated client does not declare %s", name)
+		}
+	}
+}
+
+// goCollisionSpec has parameters, properties and operations whose Go names
+// collide with each other or with the runtime Client
+const goCollisionSpec = `
+openapi: 3.0.3
+info: {title: Items, version: '1'}
+paths:
+  /items/{user_id}:
+    get:
+      operationId: get-item
+      parameters:
+        - {name: user_id, in: path, required: true, schema: {type: string}}
+        - {name: userId, in: query, schema: {type: string}}
+        - {name: user-id, in: header, schema: {type: integer}}
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Item'}
+  /items:
+    get:
+      operationId: getItem
+      responses:
+        '204': {description: Empty}
+  /base:
+    get:
+      operationId: BaseURL
+      responses:
+        '204': {description: Empty}
+  /http:
+    get:
+      operationId: httpClient
+      responses:
+        '204': {description: Empty}
+components:
+  schemas:
+    Item:
+      type: object
+      properties:
+        item_id: {type: string}
+        itemId: {type: integer}
+`
+
+func TestGoClientCollisions(t *testing.T) {
+	doc := parseSpec(t, goCollisionSpec)
+	src, err := GoClient(doc, doc.Operations(), "items")
+	if err != nil {
+		t.Fatalf("GoClient: %v\n%s", err, src)
+	}
+	pkg := typeCheck(t, src)
+
+	client, ok := pkg.Scope().Lookup("Client").Type().(*types.Named)
+	if !ok {
+		t.Fatal("no Client type")
+	}
+	methods := make(map[string]bool)
+	for i := 0; i < client.NumMethods(); i++ {
+		methods[client.Method(i).Name()] = true
+	}
+	for _, name := range []string{"GetItem", "GetItem2", "BaseURL2", "HTTPClient2"} {
+		if !methods[name] {
+			t.Errorf("Client lacks method %s; has %v", name, methods)
+		}
+	}
+
+	fields := func(typeName string) []string {
+		st, ok := pkg.Scope().Lookup(typeName).Type().Underlying().(*types.Struct)
+		if !ok {
+			t.Fatalf("%s is not a struct", typeName)
+		}
+		var names []string
+		for i := 0; i < st.NumFields(); i++ {
+			names = append(names, st.Field(i).Name())
+		}
+		return names
+	}
+	for typeName, want := range map[string]string{
+		"GetItem2Params": "UserID UserID2 UserID3",
+		"Item":           "ItemID ItemID2",
+	} {
+		if got := strings.Join(fields(typeName), " "); got != want {
+			t.Errorf("%s fields = %s, want %s", typeName, got, want)
+		}
+	}
+	if flat := strings.Join(strings.Fields(src), " "); !strings.Contains(flat, `query.Add("userId", fmt.Sprint(*params.UserID2))`) {
+		t.Errorf("query parameter not sent from its renamed field:\n%s", src)
+	}
+}
+
+func TestGoName(t *testing.T) {
+	for in, want := range map[string]string{
+		"user_id":      "UserID",
+		"get-api-keys": "GetAPIKeys",
+		"createNode":   "CreateNode",
+		"2fa":          "N2fa",
+		"":             "Value",
+	} {
+		if got := GoName(in); got != want {
+			t.Errorf("GoName(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,485 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/openapi"
+)
+
+// maxTableDepth bounds how deep nested properties are flattened into tables
+const maxTableDepth = 3
+
+// markdownRenderer tracks anchors while rendering a document
+type markdownRenderer struct {
+	doc           *openapi.Document
+	anchors       map[string]int
+	schemaAnchors map[string]string
+}
+
+// Markdown renders a spec as a Markdown API reference with a table of
+// contents by tag, one section per operation and a schema appendix
+func Markdown(doc *openapi.Document) string {
+	r := &markdownRenderer{doc: doc, anchors: make(map[string]int), schemaAnchors: make(map[string]string)}
+	var b strings.Builder
+
+	title := doc.Info.Title
+	if title == "" {
+		title = "API Reference"
+	}
+	b.WriteString(fmt.Sprintf("# %s", title))
+	if doc.Info.Version != "" {
+		b.WriteString(fmt.Sprintf(" (v%s)", strings.TrimPrefix(doc.Info.Version, "v")))
+	}
+	b.WriteString("\n\n")
+	if doc.Info.Description != "" {
+		b.WriteString(strings.TrimSpace(doc.Info.Description) + "\n\n")
+	}
+
+	if len(doc.Servers) > 0 {
+		b.WriteString("**Servers:**\n\n")
+		for _, server := range doc.Servers {
+			b.WriteString(fmt.Sprintf("- `%s`", server.URL))
+			if server.Description != "" {
+				b.WriteString(" — " + server.Description)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	tags, grouped := groupByEveryTag(doc)
+
+	// Each operation is documented once, under its first tag, and linked
+	// from its other tags. Anchors are assigned in document order so the
+	// table of contents links match the headings rendered below.
+	opAnchors := make(map[string]string)
+	tagAnchors := make(map[string]string)
+	for _, tag := range tags {
+		tagAnchors[tag] = r.anchor(tag)
+		for _, op := range grouped[tag] {
+			if primaryTag(op) == tag {
+				opAnchors[operationKey(op)] = r.anchor(op.Method + " " + op.Path)
+			}
+		}
+	}
+	schemaNames := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		schemaNames = append(schemaNames, name)
+	}
+	sort.Strings(schemaNames)
+	schemasAnchor := ""
+	if len(schemaNames) > 0 {
+		schemasAnchor = r.anchor("Schemas")
+		for _, name := range schemaNames {
+			r.schemaAnchors[name] = r.anchor(name)
+		}
+	}
+
+	b.WriteString("## Table of Contents\n\n")
+	for _, tag := range tags {
+		b.WriteString(fmt.Sprintf("- [%s](#%s)\n", tag, tagAnchors[tag]))
+		for _, op := range grouped[tag] {
+			label := fmt.Sprintf("%s %s", op.Method, op.Path)
+			if op.Operation.Summary != "" {
+				label += " — " + op.Operation.Summary
+			}
+			b.WriteString(fmt.Sprintf("  - [%s](#%s)\n", escapeLinkText(label), opAnchors[operationKey(op)]))
+		}
+	}
+	if schemasAnchor != "" {
+		b.WriteString(fmt.Sprintf("- [Schemas](#%s)\n", schemasAnchor))
+	}
+	b.WriteString("\n")
+
+	descriptions := make(map[string]string)
+	for _, tag := range doc.Tags {
+		descriptions[tag.Name] = tag.Description
+	}
+
+	for _, tag := range tags {
+		b.WriteString(fmt.Sprintf("## %s\n\n", tag))
+		if descriptions[tag] != "" {
+			b.WriteString(strings.TrimSpace(descriptions[tag]) + "\n\n")
+		}
+		var elsewhere []string
+		for _, op := range grouped[tag] {
+			if primary := primaryTag(op); primary != tag {
+				elsewhere = append(elsewhere, fmt.Sprintf("- [%s %s](#%s) (under %s)\n",
+					op.Method, escapeLinkText(op.Path), opAnchors[operationKey(op)], primary))
+				continue
+			}
+			b.WriteString(r.operation(op))
+		}
+		if len(elsewhere) > 0 {
+			b.WriteString("Also tagged " + tag + ":\n\n" + strings.Join(elsewhere, "") + "\n")
+		}
+	}
+
+	if len(schemaNames) > 0 {
+		b.WriteString("## Schemas\n\n")
+		for _, name := range schemaNames {
+			b.WriteString(r.schemaSection(name))
+		}
+	}
+
+	return b.String()
+}
+
+// operation renders the section for a single operation
+func (r *markdownRenderer) operation(op openapi.OperationRef) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("### %s %s\n\n", op.Method, op.Path))
+
+	if op.Operation.Deprecated {
+		b.WriteString("> **Deprecated.**\n\n")
+	}
+	if op.Operation.Summary != "" {
+		b.WriteString(fmt.Sprintf("**%s**\n\n", strings.TrimSpace(op.Operation.Summary)))
+	}
+	if op.Operation.Description != "" {
+		b.WriteString(strings.TrimSpace(op.Operation.Description) + "\n\n")
+	}
+	if op.Operation.OperationID != "" {
+		b.WriteString(fmt.Sprintf("Operation ID: `%s`\n\n", op.Operation.OperationID))
+	}
+
+	if params := r.doc.Parameters(op); len(params) > 0 {
+		b.WriteString("#### Parameters\n\n")
+		b.WriteString("| Name | In | Type | Required | Description |\n")
+		b.WriteString("| --- | --- | --- | --- | --- |\n")
+		for _, p := range params {
+			description := p.Description
+			if p.Deprecated {
+				description = strings.TrimSpace("**Deprecated.** " + description)
+			}
+			b.WriteString(fmt.Sprintf("| `%s` | %s | %s | %s | %s |\n",
+				p.Name, p.In, r.typeName(p.Schema), yesNo(p.Required), cell(description)))
+		}
+		b.WriteString("\n")
+	}
+
+	if body := r.doc.ResolveRequestBody(op.Operation.RequestBody); body != nil {
+		b.WriteString("#### Request Body\n\n")
+		if body.Description != "" {
+			b.WriteString(strings.TrimSpace(body.Description) + "\n\n")
+		}
+		contentType, mt := openapi.JSONMediaType(body.Content)
+		if mt != nil {
+			required := "optional"
+			if body.Required {
+				required = "required"
+			}
+			b.WriteString(fmt.Sprintf("Content type: `%s` (%s)\n\n", contentType, required))
+			b.WriteString(r.payload(mt, true))
+		}
+	}
+
+	if len(op.Operation.Responses) > 0 {
+		b.WriteString("#### Responses\n\n")
+		codes := make([]string, 0, len(op.Operation.Responses))
+		for code := range op.Operation.Responses {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			resp := r.doc.ResolveResponse(op.Operation.Responses[code])
+			if resp == nil {
+				continue
+			}
+			b.WriteString(fmt.Sprintf("##### %s", code))
+			if resp.Description != "" {
+				b.WriteString(" — " + strings.TrimSpace(resp.Description))
+			}
+			b.WriteString("\n\n")
+			contentType, mt := openapi.JSONMediaType(resp.Content)
+			if mt != nil {
+				b.WriteString(fmt.Sprintf("Content type: `%s`\n\n", contentType))
+				b.WriteString(r.payload(mt, false))
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// payload renders the schema table and example of a request or response
+func (r *markdownRenderer) payload(mt *openapi.MediaType, request bool) string {
+	var b strings.Builder
+	if mt.Schema != nil {
+		b.WriteString(fmt.Sprintf("Schema: %s\n\n", r.typeName(mt.Schema)))
+		b.WriteString(r.propertyTable(mt.Schema))
+	}
+
+	var example interface{}
+	if request {
+		example = r.doc.RequestMediaTypeExample(mt)
+	} else {
+		example = r.doc.MediaTypeExample(mt)
+	}
+	if example != nil {
+		if data, err := json.MarshalIndent(example, "", "  "); err == nil {
+			b.WriteString("Example:\n\n```json\n" + string(data) + "\n```\n\n")
+		}
+	}
+	return b.String()
+}
+
+// schemaSection renders a component schema in the appendix
+func (r *markdownRenderer) schemaSection(name string) string {
+	schema := r.doc.Components.Schemas[name]
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("### %s\n\n", name))
+	if schema == nil {
+		return b.String()
+	}
+	if schema.Deprecated {
+		b.WriteString("> **Deprecated.**\n\n")
+	}
+	if schema.Description != "" {
+		b.WriteString(strings.TrimSpace(schema.Description) + "\n\n")
+	}
+	if table := r.propertyTable(schema); table != "" {
+		b.WriteString(table)
+	} else {
+		b.WriteString(fmt.Sprintf("Type: %s\n\n", r.typeName(schema)))
+	}
+	return b.String()
+}
+
+// propertyTable flattens an object schema into a field table
+func (r *markdownRenderer) propertyTable(s *openapi.Schema) string {
+	var rows []string
+	r.collectRows(s, "", 0, &rows)
+	if len(rows) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("| Field | Type | Required | Description |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	for _, row := range rows {
+		b.WriteString(row)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func (r *markdownRenderer) collectRows(s *openapi.Schema, prefix string, depth int, rows *[]string) {
+	// Nested component references are linked rather than expanded
+	if depth > 0 && s != nil && s.Ref != "" {
+		return
+	}
+	s = r.doc.ResolveSchema(s)
+	if s == nil || depth > maxTableDepth {
+		return
+	}
+	if s.Type == "array" {
+		r.collectRows(s.Items, prefix+"[]", depth, rows)
+		return
+	}
+
+	properties := make(map[string]*openapi.Schema)
+	required := make(map[string]bool)
+	for _, sub := range s.AllOf {
+		resolved := r.doc.ResolveSchema(sub)
+		if resolved == nil {
+			continue
+		}
+		for name, prop := range resolved.Properties {
+			properties[name] = prop
+		}
+		for _, name := range resolved.Required {
+			required[name] = true
+		}
+	}
+	for name, prop := range s.Properties {
+		properties[name] = prop
+	}
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop := properties[name]
+		field := name
+		if prefix != "" {
+			field = prefix + "." + name
+		}
+		resolved := r.doc.ResolveSchema(prop)
+		description := ""
+		if resolved != nil {
+			description = resolved.Description
+			if resolved.Deprecated {
+				description = strings.TrimSpace("**Deprecated.** " + description)
+			}
+		}
+		*rows = append(*rows, fmt.Sprintf("| `%s` | %s | %s | %s |\n", field, r.typeName(prop), yesNo(required[name]), cell(description)))
+		r.collectRows(prop, field, depth+1, rows)
+	}
+}
+
+// typeName renders a short, linked type description for a table cell
+func (r *markdownRenderer) typeName(s *openapi.Schema) string {
+	if s == nil {
+		return "any"
+	}
+	if s.Ref != "" {
+		name := openapi.RefName(s.Ref)
+		if anchor, ok := r.schemaAnchors[name]; ok {
+			return fmt.Sprintf("[%s](#%s)", name, anchor)
+		}
+		return fmt.Sprintf("`%s`", name)
+	}
+
+	var t string
+	switch {
+	case len(s.OneOf) > 0:
+		t = "oneOf(" + r.typeList(s.OneOf) + ")"
+	case len(s.AnyOf) > 0:
+		t = "anyOf(" + r.typeList(s.AnyOf) + ")"
+	case len(s.AllOf) > 0:
+		t = "allOf(" + r.typeList(s.AllOf) + ")"
+	case s.Type == "array":
+		t = "array<" + r.typeName(s.Items) + ">"
+	case s.Type == "":
+		t = "object"
+	default:
+		t = s.Type
+	}
+	if s.Format != "" {
+		t += fmt.Sprintf(" (%s)", s.Format)
+	}
+	if len(s.Enum) > 0 {
+		values := make([]string, 0, len(s.Enum))
+		for _, v := range s.Enum {
+			values = append(values, fmt.Sprintf("`%v`", v))
+		}
+		t += " enum: " + strings.Join(values, ", ")
+	}
+	if s.Nullable {
+		t += ", nullable"
+	}
+	return cell(t)
+}
+
+func (r *markdownRenderer) typeList(schemas []*openapi.Schema) string {
+	names := make([]string, 0, len(schemas))
+	for _, s := range schemas {
+		names = append(names, r.typeName(s))
+	}
+	return strings.Join(names, ", ")
+}
+
+// anchor reserves a unique GitHub-style anchor for a heading
+func (r *markdownRenderer) anchor(heading string) string {
+	base := githubAnchor(heading)
+	n := r.anchors[base]
+	r.anchors[base] = n + 1
+	if n == 0 {
+		return base
+	}
+	return fmt.Sprintf("%s-%d", base, n)
+}
+
+// groupByTag groups operations by their first tag, following the order of
+// the document's tag list
+func groupByTag(doc *openapi.Document) ([]string, map[string][]openapi.OperationRef) {
+	return groupOperations(doc, func(op openapi.OperationRef) []string {
+		return []string{primaryTag(op)}
+	})
+}
+
+// groupByEveryTag is groupByTag listing each operation under all of its
+// tags rather than only the first
+func groupByEveryTag(doc *openapi.Document) ([]string, map[string][]openapi.OperationRef) {
+	return groupOperations(doc, func(op openapi.OperationRef) []string {
+		if len(op.Operation.Tags) == 0 {
+			return []string{"default"}
+		}
+		return op.Operation.Tags
+	})
+}
+
+func groupOperations(doc *openapi.Document, tagsOf func(openapi.OperationRef) []string) ([]string, map[string][]openapi.OperationRef) {
+	grouped := make(map[string][]openapi.OperationRef)
+	var order []string
+	seen := make(map[string]bool)
+	for _, tag := range doc.Tags {
+		seen[tag.Name] = true
+		order = append(order, tag.Name)
+	}
+	for _, op := range doc.Operations() {
+		listed := make(map[string]bool)
+		for _, tag := range tagsOf(op) {
+			if listed[tag] {
+				continue
+			}
+			listed[tag] = true
+			if !seen[tag] {
+				seen[tag] = true
+				order = append(order, tag)
+			}
+			grouped[tag] = append(grouped[tag], op)
+		}
+	}
+
+	var tags []string
+	for _, tag := range order {
+		if len(grouped[tag]) > 0 {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, grouped
+}
+
+// primaryTag is the tag an operation is documented under
+func primaryTag(op openapi.OperationRef) string {
+	if len(op.Operation.Tags) == 0 {
+		return "default"
+	}
+	return op.Operation.Tags[0]
+}
+
+// operationKey identifies an operation independently of how the spec
+// shares operation objects between paths
+func operationKey(op openapi.OperationRef) string {
+	return op.Method + " " + op.Path
+}
+
+// githubAnchor converts a heading to the anchor GitHub generates for it
+func githubAnchor(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func escapeLinkText(s string) string {
+	return strings.NewReplacer("[", "\\[", "]", "\\]").Replace(s)
+}
+
+func cell(s string) string {
+	s = strings.ReplaceAll(strings.TrimSpace(s), "\n", " ")
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,131 @@
+package codegen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const markdownSpec = `
+openapi: 3.0.3
+info: {title: Cards, version: v1.2.0, description: Card management.}
+servers:
+  - {url: https://api.example.com, description: Production}
+tags:
+  - {name: Card, description: Card operations.}
+  - {name: Admin}
+paths:
+  /cards:
+    get:
+      tags: [Card, Admin]
+      summary: List cards
+      parameters:
+        - {name: limit, in: query, required: true, schema: {type: integer}, description: Page size}
+        - {name: cursor, in: query, deprecated: true, schema: {type: string}}
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/Card'}
+              example: [{id: c1}]
+  /cards/{id}:
+    delete:
+      tags: [Admin]
+      deprecated: true
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      responses:
+        '204': {description: Deleted}
+  /health:
+    get:
+      responses:
+        '200': {description: OK}
+components:
+  schemas:
+    Card:
+      type: object
+      required: [id]
+      properties:
+        id: {type: string, description: Card ID}
+        status: {type: string, enum: [active, frozen]}
+`
+
+// headingAnchors returns the anchors GitHub generates for the headings of a
+// Markdown document
+func headingAnchors(md string) map[string]bool {
+	anchors := make(map[string]bool)
+	counts := make(map[string]int)
+	for _, line := range strings.Split(md, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			continue
+		}
+		base := githubAnchor(strings.TrimSpace(strings.TrimLeft(line, "#")))
+		anchor := base
+		if n := counts[base]; n > 0 {
+			anchor = fmt.Sprintf("%s-%d", base, n)
+		}
+		counts[base]++
+		anchors[anchor] = true
+	}
+	return anchors
+}
+
+func TestMarkdown(t *testing.T) {
+	md := Markdown(parseSpec(t, markdownSpec))
+
+	for _, want := range []string{
+		"# Cards (v1.2.0)\n\nCard management.",
+		"- `https://api.example.com` — Production",
+		"## Card\n\nCard operations.",
+		"  - [GET /cards — List cards](#get-cards)",
+		"| `limit` | query | integer | yes | Page size |",
+		"| `cursor` | query | string | no | **Deprecated.** |",
+		"Schema: array<[Card](#card-1)>",
+		"| `[].id` | string | yes | Card ID |",
+		"\"id\": \"c1\"",
+		"### DELETE /cards/{id}\n\n> **Deprecated.**",
+		"##### 204 — Deleted",
+		"Also tagged Admin:\n\n- [GET /cards](#get-cards) (under Card)",
+		"## default",
+		"| `status` | string enum: `active`, `frozen` | no |  |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown lacks %q:\n%s", want, md)
+		}
+	}
+	if n := strings.Count(md, "### GET /cards\n"); n != 1 {
+		t.Errorf("GET /cards documented %d times", n)
+	}
+}
+
+func TestMarkdownLinksResolve(t *testing.T) {
+	md := Markdown(parseSpec(t, markdownSpec))
+	anchors := headingAnchors(md)
+
+	links := regexp.MustCompile(`\]\(#([^)]+)\)`).FindAllStringSubmatch(md, -1)
+	if len(links) == 0 {
+		t.Fatal("no links rendered")
+	}
+	for _, link := range links {
+		if !anchors[link[1]] {
+			t.Errorf("link to #%s has no heading", link[1])
+		}
+	}
+}
+
+func TestGithubAnchor(t *testing.T) {
+	tests := map[string]string{
+		"GET /cards/{id}": "get-cardsid",
+		"Card_Status v2":  "card_status-v2",
+		"Cards — admin":   "cards--admin",
+	}
+	for heading, want := range tests {
+		if got := githubAnchor(heading); got != want {
+			t.Errorf("githubAnchor(%q) = %s, want %s", heading, got, want)
+		}
+	}
+}
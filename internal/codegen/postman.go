@@ -0,0 +1,284 @@
+package codegen
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/openapi"
+)
+
+// postmanSchema identifies the Postman collection format version
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// NamedSpec is a parsed spec together with the name it is exported under
+type NamedSpec struct {
+	Name string
+	Doc  *openapi.Document
+}
+
+// PostmanCollection is a Postman v2.1 collection
+type PostmanCollection struct {
+	Info     PostmanInfo       `json:"info"`
+	Item     []PostmanItem     `json:"item"`
+	Variable []PostmanVariable `json:"variable,omitempty"`
+}
+
+// PostmanInfo holds collection metadata
+type PostmanInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+// PostmanItem is either a folder (Item set) or a request (Request set)
+type PostmanItem struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Item        []PostmanItem   `json:"item,omitempty"`
+	Request     *PostmanRequest `json:"request,omitempty"`
+}
+
+// PostmanRequest describes a single request
+type PostmanRequest struct {
+	Method      string            `json:"method"`
+	Header      []PostmanKeyValue `json:"header"`
+	URL         PostmanURL        `json:"url"`
+	Body        *PostmanBody      `json:"body,omitempty"`
+	Auth        *PostmanAuth      `json:"auth,omitempty"`
+	Description string            `json:"description,omitempty"`
+}
+
+// PostmanURL is a structured request URL
+type PostmanURL struct {
+	Raw      string            `json:"raw"`
+	Host     []string          `json:"host"`
+	Path     []string          `json:"path"`
+	Query    []PostmanKeyValue `json:"query,omitempty"`
+	Variable []PostmanKeyValue `json:"variable,omitempty"`
+}
+
+// PostmanKeyValue is a header, query parameter or path variable
+type PostmanKeyValue struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// PostmanBody is a raw request body
+type PostmanBody struct {
+	Mode    string                 `json:"mode"`
+	Raw     string                 `json:"raw"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// PostmanAuth configures request authentication
+type PostmanAuth struct {
+	Type   string            `json:"type"`
+	Bearer []PostmanKeyValue `json:"bearer,omitempty"`
+	APIKey []PostmanKeyValue `json:"apikey,omitempty"`
+	Basic  []PostmanKeyValue `json:"basic,omitempty"`
+}
+
+// PostmanVariable is a collection-level variable
+type PostmanVariable struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type,omitempty"`
+}
+
+// Postman converts specs into a Postman v2.1 collection with one folder per
+// tag. When several specs are exported each gets its own top-level folder
+// and base URL variable, numbered when specs share a name.
+func Postman(name string, specs []NamedSpec) *PostmanCollection {
+	collection := &PostmanCollection{
+		Info: PostmanInfo{Name: name, Schema: postmanSchema},
+	}
+	if len(specs) == 1 && specs[0].Doc.Info.Description != "" {
+		collection.Info.Description = specs[0].Doc.Info.Description
+	}
+
+	variables := make(map[string]string)
+	baseVars := make(map[string]bool)
+	for _, spec := range specs {
+		baseVar := "baseUrl"
+		if len(specs) > 1 {
+			baseVar = uniqueIn(baseVars, lowerFirst(GoName(spec.Name))+"BaseUrl")
+		}
+		baseURL, err := ServerURL(spec.Doc, 0)
+		if err != nil {
+			baseURL = "http://localhost"
+		}
+		variables[baseVar] = baseURL
+
+		folders := postmanFolders(spec.Doc, baseVar, variables)
+		if len(specs) == 1 {
+			collection.Item = folders
+			continue
+		}
+		collection.Item = append(collection.Item, PostmanItem{
+			Name:        spec.Name,
+			Description: spec.Doc.Info.Description,
+			Item:        folders,
+		})
+	}
+
+	keys := make([]string, 0, len(variables))
+	for k := range variables {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		collection.Variable = append(collection.Variable, PostmanVariable{Key: k, Value: variables[k], Type: "string"})
+	}
+	return collection
+}
+
+// postmanFolders groups a spec's operations into one folder per tag
+func postmanFolders(doc *openapi.Document, baseVar string, variables map[string]string) []PostmanItem {
+	descriptions := make(map[string]string)
+	for _, tag := range doc.Tags {
+		descriptions[tag.Name] = tag.Description
+	}
+
+	tags, grouped := groupByTag(doc)
+	folders := make([]PostmanItem, 0, len(tags))
+	for _, tag := range tags {
+		folder := PostmanItem{Name: tag, Description: descriptions[tag]}
+		for _, op := range grouped[tag] {
+			folder.Item = append(folder.Item, postmanRequest(doc, op, baseVar, variables))
+		}
+		folders = append(folders, folder)
+	}
+	return folders
+}
+
+// postmanRequest converts a single operation
+func postmanRequest(doc *openapi.Document, op openapi.OperationRef, baseVar string, variables map[string]string) PostmanItem {
+	name := op.Operation.Summary
+	if name == "" {
+		name = op.Operation.OperationID
+	}
+	if name == "" {
+		name = op.Method + " " + op.Path
+	}
+
+	req := &PostmanRequest{
+		Method:      op.Method,
+		Header:      []PostmanKeyValue{},
+		Description: op.Operation.Description,
+		URL:         PostmanURL{Host: []string{"{{" + baseVar + "}}"}},
+	}
+
+	for _, segment := range strings.Split(strings.Trim(op.Path, "/"), "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segment = ":" + strings.Trim(segment, "{}")
+		}
+		req.URL.Path = append(req.URL.Path, segment)
+	}
+
+	for _, p := range doc.Parameters(op) {
+		kv := PostmanKeyValue{Key: p.Name, Value: scalarString(doc.ParameterExample(p)), Description: p.Description}
+		switch p.In {
+		case "path":
+			req.URL.Variable = append(req.URL.Variable, kv)
+		case "query":
+			kv.Disabled = !p.Required
+			req.URL.Query = append(req.URL.Query, kv)
+		case "header":
+			kv.Disabled = !p.Required
+			req.Header = append(req.Header, kv)
+		}
+	}
+
+	if body := doc.ResolveRequestBody(op.Operation.RequestBody); body != nil {
+		contentType, mt := openapi.JSONMediaType(body.Content)
+		if mt != nil {
+			req.Header = append(req.Header, PostmanKeyValue{Key: "Content-Type", Value: contentType})
+			raw := ""
+			if example := doc.RequestMediaTypeExample(mt); example != nil {
+				if data, err := json.MarshalIndent(example, "", "  "); err == nil {
+					raw = string(data)
+				}
+			}
+			req.Body = &PostmanBody{Mode: "raw", Raw: raw}
+			if strings.Contains(contentType, "json") {
+				req.Body.Options = map[string]interface{}{"raw": map[string]string{"language": "json"}}
+			}
+		}
+	}
+
+	req.Auth = postmanAuth(doc, op, variables)
+
+	raw := "{{" + baseVar + "}}/" + strings.Join(req.URL.Path, "/")
+	var query []string
+	for _, q := range req.URL.Query {
+		if !q.Disabled {
+			query = append(query, q.Key+"="+q.Value)
+		}
+	}
+	if len(query) > 0 {
+		raw += "?" + strings.Join(query, "&")
+	}
+	req.URL.Raw = raw
+
+	return PostmanItem{Name: name, Request: req}
+}
+
+// postmanAuth maps the operation's first security requirement onto Postman
+// auth, registering a collection variable for each credential
+func postmanAuth(doc *openapi.Document, op openapi.OperationRef, variables map[string]string) *PostmanAuth {
+	requirements := doc.EffectiveSecurity(op.Operation)
+	if op.Operation.Security != nil && len(requirements) == 0 {
+		return &PostmanAuth{Type: "noauth"}
+	}
+	if len(requirements) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(requirements[0]))
+	for name := range requirements[0] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		scheme := doc.Components.SecuritySchemes[name]
+		if scheme == nil {
+			continue
+		}
+		variable := lowerFirst(GoName(name))
+		switch {
+		case scheme.Type == "apiKey" && scheme.In != "cookie":
+			variables[variable] = ""
+			return &PostmanAuth{Type: "apikey", APIKey: []PostmanKeyValue{
+				{Key: "key", Value: scheme.Name, Type: "string"},
+				{Key: "value", Value: "{{" + variable + "}}", Type: "string"},
+				{Key: "in", Value: scheme.In, Type: "string"},
+			}}
+		case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic"):
+			variables[variable+"Username"] = ""
+			variables[variable+"Password"] = ""
+			return &PostmanAuth{Type: "basic", Basic: []PostmanKeyValue{
+				{Key: "username", Value: "{{" + variable + "Username}}", Type: "string"},
+				{Key: "password", Value: "{{" + variable + "Password}}", Type: "string"},
+			}}
+		case scheme.Type == "http" || scheme.Type == "oauth2" || scheme.Type == "openIdConnect":
+			variables[variable] = ""
+			return &PostmanAuth{Type: "bearer", Bearer: []PostmanKeyValue{
+				{Key: "token", Value: "{{" + variable + "}}", Type: "string"},
+			}}
+		}
+	}
+	return nil
+}
+
+// lowerFirst lower-cases the first letter of an identifier
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,103 @@
+package codegen
+
+import (
+	"reflect"
+	"testing"
+)
+
+// postmanRequests indexes the requests of a collection's folders by name
+func postmanRequests(items []PostmanItem) map[string]*PostmanRequest {
+	requests := make(map[string]*PostmanRequest)
+	for _, item := range items {
+		if item.Request != nil {
+			requests[item.Name] = item.Request
+		}
+		for name, req := range postmanRequests(item.Item) {
+			requests[name] = req
+		}
+	}
+	return requests
+}
+
+func TestPostman(t *testing.T) {
+	doc := parseSpec(t, securitySpec)
+	collection := Postman("Cards", []NamedSpec{{Name: "Cards", Doc: doc}})
+
+	if collection.Info.Name != "Cards" || collection.Info.Schema != postmanSchema {
+		t.Errorf("info = %+v", collection.Info)
+	}
+	if len(collection.Item) != 1 || collection.Item[0].Name != "default" {
+		t.Fatalf("folders = %+v", collection.Item)
+	}
+
+	requests := postmanRequests(collection.Item)
+	get := requests["GET /cards/{id}"]
+	if get == nil {
+		t.Fatalf("requests = %v", requests)
+	}
+	if get.URL.Raw != "{{baseUrl}}/cards/:id" || !reflect.DeepEqual(get.URL.Path, []string{"cards", ":id"}) {
+		t.Errorf("url = %+v", get.URL)
+	}
+	if len(get.URL.Variable) != 1 || get.URL.Variable[0].Key != "id" || get.URL.Variable[0].Value != "c 1" {
+		t.Errorf("path variables = %+v", get.URL.Variable)
+	}
+	if len(get.URL.Query) != 1 || !get.URL.Query[0].Disabled {
+		t.Errorf("optional query = %+v", get.URL.Query)
+	}
+	if get.Auth == nil || get.Auth.Type != "apikey" {
+		t.Errorf("auth = %+v", get.Auth)
+	}
+
+	if auth := requests["GET /health"].Auth; auth == nil || auth.Type != "noauth" {
+		t.Errorf("health auth = %+v", auth)
+	}
+	post := requests["POST /cards"]
+	if post.Body == nil || post.Body.Raw != "{\n  \"name\": \"Travel\"\n}" {
+		t.Errorf("body = %+v", post.Body)
+	}
+	if auth := requests["DELETE /cards/{id}"].Auth; auth == nil || auth.Type != "bearer" {
+		t.Errorf("delete auth = %+v", auth)
+	}
+
+	// Every credential the requests reference is declared on the collection
+	got := make(map[string]string)
+	for _, v := range collection.Variable {
+		got[v.Key] = v.Value
+	}
+	if len(got) != 3 || got["baseUrl"] != "https://eu.example.com/v1" || got["bearer"] != "" {
+		t.Errorf("variables = %v", got)
+	}
+	if value := get.Auth.APIKey[1].Value; value != "{{"+collection.Variable[0].Key+"}}" {
+		t.Errorf("api key value %s is not a collection variable", value)
+	}
+}
+
+func TestPostmanSpecsSharingAName(t *testing.T) {
+	first := parseSpec(t, securitySpec)
+	second := parseSpec(t, `
+openapi: 3.0.3
+info: {title: Cards, version: '2'}
+servers:
+  - url: https://cards.example.com
+paths:
+  /cards:
+    get:
+      responses:
+        '200': {description: OK}
+`)
+	collection := Postman("specs", []NamedSpec{{Name: "Cards", Doc: first}, {Name: "Cards", Doc: second}})
+
+	if len(collection.Item) != 2 {
+		t.Fatalf("folders = %+v", collection.Item)
+	}
+	got := make(map[string]string)
+	for _, v := range collection.Variable {
+		got[v.Key] = v.Value
+	}
+	if got["cardsBaseUrl"] != "https://eu.example.com/v1" || got["cardsBaseUrl2"] != "https://cards.example.com" {
+		t.Errorf("variables = %v", got)
+	}
+	if raw := postmanRequests(collection.Item[1].Item)["GET /cards"].URL.Raw; raw != "{{cardsBaseUrl2}}/cards" {
+		t.Errorf("second spec url = %s", raw)
+	}
+}
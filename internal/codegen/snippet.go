@@ -0,0 +1,224 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/openapi"
+)
+
+// Request is a concrete HTTP request built from an operation's contract
+type Request struct {
+	Method      string
+	URL         string
+	Headers     [][2]string
+	ContentType string
+	Body        string
+}
+
+// ServerURL returns the base URL of the server at index with its variables
+// replaced by their defaults. Relative URLs are resolved against localhost.
+func ServerURL(doc *openapi.Document, index int) (string, error) {
+	if len(doc.Servers) == 0 {
+		return "http://localhost", nil
+	}
+	if index < 0 || index >= len(doc.Servers) {
+		return "", fmt.Errorf("server index %d out of range (spec defines %d servers)", index, len(doc.Servers))
+	}
+
+	server := doc.Servers[index]
+	base := server.URL
+	for name, variable := range server.Variables {
+		base = strings.ReplaceAll(base, "{"+name+"}", variable.Default)
+	}
+	if strings.HasPrefix(base, "/") {
+		base = "http://localhost" + base
+	}
+	return strings.TrimSuffix(base, "/"), nil
+}
+
+// BuildRequest fills in an operation's path, query and header parameters,
+// security headers and an example JSON body against the given base URL
+func BuildRequest(doc *openapi.Document, op openapi.OperationRef, baseURL string) *Request {
+	req := &Request{Method: op.Method}
+
+	path := op.Path
+	query := url.Values{}
+	var cookies []string
+
+	for _, param := range doc.Parameters(op) {
+		value := scalarString(doc.ParameterExample(param))
+		switch param.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(value))
+		case "query":
+			query.Add(param.Name, value)
+		case "header":
+			req.Headers = append(req.Headers, [2]string{param.Name, value})
+		case "cookie":
+			cookies = append(cookies, param.Name+"="+value)
+		}
+	}
+
+	var authQuery []string
+	for _, auth := range securityHeaders(doc, op) {
+		switch auth.in {
+		case "query":
+			// Appended unescaped so the placeholder stays a shell variable
+			authQuery = append(authQuery, url.QueryEscape(auth.name)+"="+auth.value)
+		case "cookie":
+			cookies = append(cookies, auth.name+"="+auth.value)
+		default:
+			req.Headers = append(req.Headers, [2]string{auth.name, auth.value})
+		}
+	}
+
+	if len(cookies) > 0 {
+		req.Headers = append(req.Headers, [2]string{"Cookie", strings.Join(cookies, "; ")})
+	}
+
+	req.URL = baseURL + path
+	rawQuery := query.Encode()
+	if len(authQuery) > 0 {
+		if rawQuery != "" {
+			rawQuery += "&"
+		}
+		rawQuery += strings.Join(authQuery, "&")
+	}
+	if rawQuery != "" {
+		req.URL += "?" + rawQuery
+	}
+
+	if body := doc.ResolveRequestBody(op.Operation.RequestBody); body != nil {
+		contentType, mediaType := openapi.JSONMediaType(body.Content)
+		if mediaType != nil {
+			req.ContentType = contentType
+			if example := doc.RequestMediaTypeExample(mediaType); example != nil {
+				if data, err := json.MarshalIndent(example, "", "  "); err == nil {
+					req.Body = string(data)
+				}
+			}
+		}
+	}
+
+	return req
+}
+
+// Curl renders a request as a curl command
+func Curl(req *Request) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("curl -X %s %s", req.Method, shellQuote(req.URL)))
+
+	for _, h := range req.Headers {
+		b.WriteString(" \\\n  -H " + shellQuote(h[0]+": "+h[1]))
+	}
+	if req.Body != "" {
+		b.WriteString(" \\\n  -H " + shellQuote("Content-Type: "+req.ContentType))
+		b.WriteString(" \\\n  -d " + shellQuote(req.Body))
+	}
+	return b.String()
+}
+
+// HTTPie renders a request as an HTTPie command
+func HTTPie(req *Request) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("http %s %s", req.Method, shellQuote(req.URL)))
+
+	for _, h := range req.Headers {
+		b.WriteString(" \\\n  " + shellQuote(h[0]+":"+h[1]))
+	}
+	if req.Body != "" {
+		b.WriteString(" \\\n  " + shellQuote("Content-Type:"+req.ContentType))
+		b.WriteString(" \\\n  --raw " + shellQuote(req.Body))
+	}
+	return b.String()
+}
+
+// credential is a security value to send with a request
+type credential struct {
+	in    string
+	name  string
+	value string
+}
+
+// securityHeaders returns placeholder credentials for the first alternative
+// of the operation's resolved security, as get_endpoint_auth reports it
+func securityHeaders(doc *openapi.Document, op openapi.OperationRef) []credential {
+	auth := doc.ResolveAuth(op.Operation)
+	if len(auth.Alternatives) == 0 {
+		return nil
+	}
+
+	var creds []credential
+	for _, required := range auth.Alternatives[0].Schemes {
+		scheme := required.Scheme
+		if scheme == nil {
+			continue
+		}
+		env := "$" + EnvName(required.Name)
+		switch scheme.Type {
+		case "apiKey":
+			creds = append(creds, credential{in: scheme.In, name: scheme.Name, value: env})
+		case "http":
+			if strings.EqualFold(scheme.Scheme, "basic") {
+				creds = append(creds, credential{name: "Authorization", value: "Basic " + env})
+			} else {
+				creds = append(creds, credential{name: "Authorization", value: "Bearer " + env})
+			}
+		case "oauth2", "openIdConnect":
+			creds = append(creds, credential{name: "Authorization", value: "Bearer " + env})
+		}
+	}
+	return creds
+}
+
+// EnvName converts a security scheme name to an environment variable name
+func EnvName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z':
+			b.WriteRune(r - 'a' + 'A')
+		case r >= 'A' && r <= 'Z':
+			if i > 0 && name[i-1] >= 'a' && name[i-1] <= 'z' {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// scalarString formats a parameter example for use in a URL or header
+func scalarString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case []interface{}:
+		parts := make([]string, 0, len(val))
+		for _, item := range val {
+			parts = append(parts, scalarString(item))
+		}
+		return strings.Join(parts, ",")
+	case map[string]interface{}:
+		data, _ := json.Marshal(val)
+		return string(data)
+	}
+	return fmt.Sprint(v)
+}
+
+// shellQuote quotes a value for POSIX shells, leaving $VARIABLES expandable
+func shellQuote(s string) string {
+	if strings.Contains(s, "$") && !strings.ContainsAny(s, "\"`\\\n") {
+		return "\"" + s + "\""
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
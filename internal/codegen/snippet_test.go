@@ -0,0 +1,189 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/openapi"
+)
+
+// parseSpec parses an inline test spec
+func parseSpec(t *testing.T, spec string) *openapi.Document {
+	t.Helper()
+	doc, err := openapi.Parse([]byte(strings.TrimLeft(spec, "\n")))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return doc
+}
+
+// findOperation looks up an operation of a test spec
+func findOperation(t *testing.T, doc *openapi.Document, path, method string) openapi.OperationRef {
+	t.Helper()
+	op, err := doc.FindOperation(path, method)
+	if err != nil {
+		t.Fatalf("FindOperation: %v", err)
+	}
+	return *op
+}
+
+const securitySpec = `
+openapi: 3.0.3
+info: {title: Cards, version: '1'}
+servers:
+  - url: https://{region}.example.com/v1
+    variables:
+      region: {default: eu}
+security:
+  - apiKey: []
+paths:
+  /cards/{id}:
+    get:
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}, example: c 1}
+        - {name: expand, in: query, schema: {type: array, items: {type: string}}, example: [owner, limits]}
+        - {name: X-Request-Id, in: header, schema: {type: string}, example: r-1}
+      responses:
+        '200': {description: OK}
+    delete:
+      security:
+        - bearer: []
+          partner: []
+        - apiKey: []
+      responses:
+        '204': {description: Deleted}
+  /health:
+    get:
+      security: []
+      responses:
+        '200': {description: OK}
+  /cards:
+    post:
+      security:
+        - {}
+        - apiKey: []
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name: {type: string, example: Travel}
+      responses:
+        '201': {description: Created}
+components:
+  securitySchemes:
+    apiKey: {type: apiKey, in: header, name: X-API-Key}
+    bearer: {type: http, scheme: bearer}
+    partner: {type: apiKey, in: query, name: partner_key}
+`
+
+func TestBuildRequest(t *testing.T) {
+	doc := parseSpec(t, securitySpec)
+	base, err := ServerURL(doc, 0)
+	if err != nil {
+		t.Fatalf("ServerURL: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		method  string
+		url     string
+		headers [][2]string
+		body    string
+	}{
+		{
+			name: "global security and parameters", path: "/cards/{id}", method: "GET",
+			url:     "https://eu.example.com/v1/cards/c%201?expand=owner%2Climits",
+			headers: [][2]string{{"X-Request-Id", "r-1"}, {"X-API-Key", "$API_KEY"}},
+		},
+		{
+			name: "operation security overrides global", path: "/cards/{id}", method: "DELETE",
+			url:     "https://eu.example.com/v1/cards/{id}?partner_key=$PARTNER",
+			headers: [][2]string{{"Authorization", "Bearer $BEARER"}},
+		},
+		{
+			name: "security disabled", path: "/health", method: "GET",
+			url: "https://eu.example.com/v1/health",
+		},
+		{
+			name: "anonymous alternative first", path: "/cards", method: "POST",
+			url:  "https://eu.example.com/v1/cards",
+			body: "{\n  \"name\": \"Travel\"\n}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := findOperation(t, doc, tt.path, tt.method)
+			req := BuildRequest(doc, op, base)
+			if req.URL != tt.url {
+				t.Errorf("URL = %s, want %s", req.URL, tt.url)
+			}
+			if strings.Join(flatten(req.Headers), "|") != strings.Join(flatten(tt.headers), "|") {
+				t.Errorf("headers = %v, want %v", req.Headers, tt.headers)
+			}
+			if req.Body != tt.body {
+				t.Errorf("body = %q, want %q", req.Body, tt.body)
+			}
+
+			// Snippets send the credentials get_endpoint_auth reports first
+			auth := doc.ResolveAuth(op.Operation)
+			if len(auth.Alternatives) > 0 {
+				for _, scheme := range auth.Alternatives[0].Schemes {
+					if !strings.Contains(Curl(req), "$"+EnvName(scheme.Name)) {
+						t.Errorf("curl lacks the %s credential:\n%s", scheme.Name, Curl(req))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSnippets(t *testing.T) {
+	req := &Request{
+		Method:      "POST",
+		URL:         "https://api.example.com/cards",
+		Headers:     [][2]string{{"X-API-Key", "$API_KEY"}},
+		ContentType: "application/json",
+		Body:        `{"name": "it's"}`,
+	}
+
+	wantCurl := `curl -X POST 'https://api.example.com/cards' \
+  -H "X-API-Key: $API_KEY" \
+  -H 'Content-Type: application/json' \
+  -d '{"name": "it'\''s"}'`
+	if got := Curl(req); got != wantCurl {
+		t.Errorf("Curl =\n%s\nwant\n%s", got, wantCurl)
+	}
+
+	wantHTTPie := `http POST 'https://api.example.com/cards' \
+  "X-API-Key:$API_KEY" \
+  'Content-Type:application/json' \
+  --raw '{"name": "it'\''s"}'`
+	if got := HTTPie(req); got != wantHTTPie {
+		t.Errorf("HTTPie =\n%s\nwant\n%s", got, wantHTTPie)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	for name, want := range map[string]string{
+		"apiKey":       "API_KEY",
+		"bearer-auth":  "BEARER_AUTH",
+		"OAuth2":       "OAUTH2",
+		"petstore_key": "PETSTORE_KEY",
+	} {
+		if got := EnvName(name); got != want {
+			t.Errorf("EnvName(%s) = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func flatten(headers [][2]string) []string {
+	var out []string
+	for _, h := range headers {
+		out = append(out, h[0]+": "+h[1])
+	}
+	return out
+}
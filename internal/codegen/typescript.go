@@ -0,0 +1,365 @@
+package codegen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/openapi"
+)
+
+// tsIdentifier matches property names that need no quoting
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsGenerator accumulates TypeScript declarations while walking schemas
+type tsGenerator struct {
+	doc      *openapi.Document
+	decls    []string
+	declared map[string]bool // Component schemas declared
+
+	taken      map[string]bool   // Type names in use
+	components map[string]string // TypeScript name of each component schema
+}
+
+// TypeScriptTypes converts the named component schemas and the payloads of
+// the given operations into TypeScript declarations. Components referenced
+// from them are emitted as well.
+func TypeScriptTypes(doc *openapi.Document, schemas []string, ops []openapi.OperationRef) (string, error) {
+	g := &tsGenerator{doc: doc, declared: make(map[string]bool), taken: make(map[string]bool), components: make(map[string]string)}
+
+	// Components claim their names first, so operation types that collide
+	// with them are the ones renamed
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g.components[name] = uniqueIn(g.taken, GoName(name))
+	}
+
+	for _, name := range schemas {
+		if doc.Components.Schemas[name] == nil {
+			return "", fmt.Errorf("schema %q not found in components", name)
+		}
+		g.component(name)
+	}
+	for _, op := range ops {
+		g.operation(op)
+	}
+
+	var b strings.Builder
+	b.WriteString("// Code generated by s3-mcp-server. DO NOT EDIT.\n")
+	if doc.Info.Title != "" {
+		b.WriteString(fmt.Sprintf("// Source: %s %s\n", doc.Info.Title, doc.Info.Version))
+	}
+	for _, decl := range g.decls {
+		b.WriteString("\n")
+		b.WriteString(decl)
+	}
+	return b.String(), nil
+}
+
+// operation emits params, request and response types for an operation
+func (g *tsGenerator) operation(op openapi.OperationRef) {
+	name := OperationName(op)
+
+	if params := g.doc.Parameters(op); len(params) > 0 {
+		var b strings.Builder
+		b.WriteString(fmt.Sprintf("/** Parameters of %s %s */\n", op.Method, op.Path))
+		b.WriteString(fmt.Sprintf("export interface %s {\n", uniqueIn(g.taken, name+"Params")))
+		for _, p := range params {
+			b.WriteString(tsDoc(p.Description, p.Deprecated, "  "))
+			optional := "?"
+			if p.Required {
+				optional = ""
+			}
+			b.WriteString(fmt.Sprintf("  %s%s: %s;\n", tsPropertyName(p.Name), optional, g.typeFor(p.Schema, "  ")))
+		}
+		b.WriteString("}\n")
+		g.decls = append(g.decls, b.String())
+	}
+
+	if body := g.doc.ResolveRequestBody(op.Operation.RequestBody); body != nil {
+		if _, mt := openapi.JSONMediaType(body.Content); mt != nil && mt.Schema != nil {
+			g.alias(name+"Request", fmt.Sprintf("Request body of %s %s", op.Method, op.Path), mt.Schema)
+		}
+	}
+
+	codes := make([]string, 0, len(op.Operation.Responses))
+	for code := range op.Operation.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	var success []string
+	for _, code := range codes {
+		resp := g.doc.ResolveResponse(op.Operation.Responses[code])
+		if resp == nil {
+			continue
+		}
+		_, mt := openapi.JSONMediaType(resp.Content)
+		if mt == nil || mt.Schema == nil {
+			continue
+		}
+		suffix := strings.ToUpper(code)
+		if code == "default" {
+			suffix = "Default"
+		}
+		typeName := g.alias(name+"Response"+suffix, fmt.Sprintf("%s response of %s %s", code, op.Method, op.Path), mt.Schema)
+		if strings.HasPrefix(code, "2") {
+			success = append(success, typeName)
+		}
+	}
+	if len(success) > 0 {
+		g.decls = append(g.decls, fmt.Sprintf("/** Successful response of %s %s */\nexport type %s = %s;\n",
+			op.Method, op.Path, uniqueIn(g.taken, name+"Response"), strings.Join(success, " | ")))
+	}
+}
+
+// alias declares an operation-level type for a payload schema and returns
+// its name. A bare $ref to a component already named base is used as is,
+// since aliasing it would declare the name twice.
+func (g *tsGenerator) alias(base, doc string, schema *openapi.Schema) string {
+	t := g.typeFor(schema, "")
+	if schema.Ref != "" && t == base {
+		return t
+	}
+	name := uniqueIn(g.taken, base)
+	g.decls = append(g.decls, fmt.Sprintf("/** %s */\nexport type %s = %s;\n", doc, name, t))
+	return name
+}
+
+// component declares a named component schema
+func (g *tsGenerator) component(name string) string {
+	typeName, ok := g.components[name]
+	if !ok {
+		typeName = uniqueIn(g.taken, GoName(name))
+		g.components[name] = typeName
+	}
+	if g.declared[name] {
+		return typeName
+	}
+	g.declared[name] = true
+
+	schema := g.doc.Components.Schemas[name]
+	if schema == nil {
+		g.decls = append(g.decls, fmt.Sprintf("/** Referenced by the spec but not defined */\nexport type %s = unknown;\n", typeName))
+		return typeName
+	}
+
+	// Reserve the slot so dependencies are declared after their users
+	index := len(g.decls)
+	g.decls = append(g.decls, "")
+
+	var b strings.Builder
+	b.WriteString(tsDoc(schema.Description, schema.Deprecated, ""))
+	if isPlainObject(schema) {
+		b.WriteString(fmt.Sprintf("export interface %s %s\n", typeName, g.objectType(schema, "")))
+	} else {
+		b.WriteString(fmt.Sprintf("export type %s = %s;\n", typeName, g.typeFor(schema, "")))
+	}
+	g.decls[index] = b.String()
+	return typeName
+}
+
+// typeFor renders the TypeScript type expression for a schema
+func (g *tsGenerator) typeFor(s *openapi.Schema, indent string) string {
+	if s == nil {
+		return "unknown"
+	}
+	if s.Ref != "" {
+		return g.component(openapi.RefName(s.Ref))
+	}
+
+	t := g.baseType(s, indent)
+	if s.Nullable {
+		t += " | null"
+	}
+	return t
+}
+
+func (g *tsGenerator) baseType(s *openapi.Schema, indent string) string {
+	switch {
+	case len(s.OneOf) > 0:
+		return g.unionType(s, s.OneOf, indent)
+	case len(s.AnyOf) > 0:
+		return g.unionType(s, s.AnyOf, indent)
+	case len(s.AllOf) > 0:
+		var parts []string
+		for _, sub := range s.AllOf {
+			parts = append(parts, parenthesize(g.typeFor(sub, indent)))
+		}
+		if len(s.Properties) > 0 {
+			parts = append(parts, g.objectType(s, indent))
+		}
+		return strings.Join(parts, " & ")
+	case len(s.Enum) > 0:
+		var literals []string
+		for _, v := range s.Enum {
+			switch val := v.(type) {
+			case string:
+				literals = append(literals, fmt.Sprintf("%q", val))
+			case nil:
+				literals = append(literals, "null")
+			default:
+				literals = append(literals, fmt.Sprint(val))
+			}
+		}
+		return strings.Join(literals, " | ")
+	}
+
+	switch s.Type {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		return parenthesize(g.typeFor(s.Items, indent)) + "[]"
+	case "object", "":
+		if len(s.Properties) > 0 {
+			return g.objectType(s, indent)
+		}
+		if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+			return fmt.Sprintf("Record<string, %s>", g.typeFor(s.AdditionalProperties.Schema, indent))
+		}
+		if s.Type == "object" {
+			return "Record<string, unknown>"
+		}
+	}
+	return "unknown"
+}
+
+// unionType renders oneOf/anyOf members, tagging each with its
+// discriminator value when the schema declares a discriminator
+func (g *tsGenerator) unionType(s *openapi.Schema, members []*openapi.Schema, indent string) string {
+	tags := make(map[string]string)
+	if s.Discriminator != nil {
+		for value, ref := range s.Discriminator.Mapping {
+			tags[openapi.RefName(ref)] = value
+		}
+	}
+
+	var parts []string
+	for _, member := range members {
+		t := g.typeFor(member, indent)
+		if s.Discriminator != nil && member.Ref != "" {
+			name := openapi.RefName(member.Ref)
+			value, ok := tags[name]
+			if !ok {
+				value = name
+			}
+			t = fmt.Sprintf("(%s & { %s: %q })", t, tsPropertyName(s.Discriminator.PropertyName), value)
+		} else {
+			t = parenthesize(t)
+		}
+		parts = append(parts, t)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// objectType renders an inline object literal type
+func (g *tsGenerator) objectType(s *openapi.Schema, indent string) string {
+	required := make(map[string]bool)
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	inner := indent + "  "
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, name := range names {
+		prop := s.Properties[name]
+		resolved := g.doc.ResolveSchema(prop)
+		if resolved != nil && prop.Ref == "" {
+			b.WriteString(tsDoc(resolved.Description, resolved.Deprecated, inner))
+		}
+		optional := "?"
+		if required[name] {
+			optional = ""
+		}
+		readonly := ""
+		if prop.ReadOnly || (resolved != nil && resolved.ReadOnly) {
+			readonly = "readonly "
+		}
+		b.WriteString(fmt.Sprintf("%s%s%s%s: %s;\n", inner, readonly, tsPropertyName(name), optional, g.typeFor(prop, inner)))
+	}
+	if ap := s.AdditionalProperties; ap != nil && ap.Allowed {
+		// Declared properties must stay assignable to the index signature
+		valueType := "unknown"
+		if ap.Schema != nil && len(names) == 0 {
+			valueType = g.typeFor(ap.Schema, inner)
+		}
+		b.WriteString(fmt.Sprintf("%s[key: string]: %s;\n", inner, valueType))
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+// isPlainObject reports whether a schema maps directly onto an interface
+func isPlainObject(s *openapi.Schema) bool {
+	return len(s.Properties) > 0 && len(s.AllOf) == 0 && len(s.OneOf) == 0 &&
+		len(s.AnyOf) == 0 && !s.Nullable && (s.Type == "object" || s.Type == "")
+}
+
+// tsPropertyName quotes property names that are not valid identifiers
+func tsPropertyName(name string) string {
+	if tsIdentifier.MatchString(name) {
+		return name
+	}
+	return fmt.Sprintf("%q", name)
+}
+
+// parenthesize wraps union and intersection types before combining them
+func parenthesize(t string) string {
+	depth := 0
+	inString := false
+	for i := 0; i < len(t); i++ {
+		switch c := t[i]; {
+		case c == '"' && (i == 0 || t[i-1] != '\\'):
+			inString = !inString
+		case inString:
+		case c == '{' || c == '(' || c == '<' || c == '[':
+			depth++
+		case c == '}' || c == ')' || c == '>' || c == ']':
+			depth--
+		case depth == 0 && (c == '|' || c == '&'):
+			return "(" + t + ")"
+		}
+	}
+	return t
+}
+
+// tsDoc renders a JSDoc comment for a description and deprecation flag
+func tsDoc(description string, deprecated bool, indent string) string {
+	description = strings.TrimSpace(description)
+	if description == "" && !deprecated {
+		return ""
+	}
+	var lines []string
+	if description != "" {
+		lines = append(lines, strings.Split(description, "\n")...)
+	}
+	if deprecated {
+		lines = append(lines, "@deprecated")
+	}
+	if len(lines) == 1 {
+		return fmt.Sprintf("%s/** %s */\n", indent, strings.ReplaceAll(lines[0], "*/", "*\\/"))
+	}
+	var b strings.Builder
+	b.WriteString(indent + "/**\n")
+	for _, line := range lines {
+		b.WriteString(fmt.Sprintf("%s * %s\n", indent, strings.ReplaceAll(strings.TrimSpace(line), "*/", "*\\/")))
+	}
+	b.WriteString(indent + " */\n")
+	return b.String()
+}
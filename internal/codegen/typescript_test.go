@@ -0,0 +1,193 @@
+package codegen
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/openapi"
+)
+
+const typeScriptSpec = `
+openapi: 3.0.3
+info: {title: Cards, version: '1'}
+paths:
+  /cards/{id}:
+    get:
+      operationId: getCard
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+        - {name: X-Trace, in: header, description: Trace header, schema: {type: string}}
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Card'}
+        '404':
+          description: Missing
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  message: {type: string}
+components:
+  schemas:
+    Card:
+      type: object
+      description: A payment card
+      required: [id, status]
+      properties:
+        id: {$ref: '#/components/schemas/CardID'}
+        created: {type: string, readOnly: true}
+        status: {type: string, enum: [active, frozen]}
+        nickname: {type: string, nullable: true}
+        old-field: {type: string, deprecated: true}
+        labels:
+          type: object
+          additionalProperties: {type: string}
+        owner: {$ref: '#/components/schemas/Owner'}
+    CardID:
+      type: string
+      readOnly: true
+    Owner:
+      oneOf:
+        - {$ref: '#/components/schemas/Person'}
+        - {$ref: '#/components/schemas/Company'}
+      discriminator:
+        propertyName: kind
+        mapping:
+          person: '#/components/schemas/Person'
+    Person:
+      type: object
+      properties:
+        name: {type: string}
+    Company:
+      allOf:
+        - {$ref: '#/components/schemas/Person'}
+        - type: object
+          properties:
+            vat: {type: string}
+`
+
+func TestTypeScriptTypes(t *testing.T) {
+	doc := parseSpec(t, typeScriptSpec)
+	out, err := TypeScriptTypes(doc, []string{"Card"}, []openapi.OperationRef{findOperation(t, doc, "/cards/{id}", "GET")})
+	if err != nil {
+		t.Fatalf("TypeScriptTypes: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "interface with doc", want: "/** A payment card */\nexport interface Card {\n"},
+		{name: "readonly through $ref", want: "  readonly id: CardID;\n"},
+		{name: "readonly inline", want: "  readonly created?: string;\n"},
+		{name: "required enum", want: "  status: \"active\" | \"frozen\";\n"},
+		{name: "nullable", want: "  nickname?: string | null;\n"},
+		{name: "quoted deprecated property", want: "  /** @deprecated */\n  \"old-field\"?: string;\n"},
+		{name: "map", want: "  labels?: Record<string, string>;\n"},
+		{name: "discriminated union", want: "export type Owner = (Person & { kind: \"person\" }) | (Company & { kind: \"Company\" });\n"},
+		{name: "allOf", want: "export type Company = Person & {\n  vat?: string;\n};\n"},
+		{name: "params", want: "export interface GetCardParams {\n  id: string;\n  /** Trace header */\n  \"X-Trace\"?: string;\n}\n"},
+		{name: "error response", want: "export type GetCardResponse404 = {\n  message?: string;\n};\n"},
+		{name: "success union", want: "export type GetCardResponse = GetCardResponse200;\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("output lacks %q:\n%s", tt.want, out)
+			}
+		})
+	}
+
+	if strings.Count(out, "export interface Person ") != 1 {
+		t.Errorf("Person should be declared once:\n%s", out)
+	}
+	if _, err := TypeScriptTypes(doc, []string{"Missing"}, nil); err == nil {
+		t.Error("TypeScriptTypes accepted an unknown schema")
+	}
+}
+
+// tsCollisionSpec has component schemas named like the types generated for
+// the createCard operation
+const tsCollisionSpec = `
+openapi: 3.0.3
+info: {title: Cards, version: '1'}
+paths:
+  /cards:
+    post:
+      operationId: createCard
+      parameters:
+        - {name: dryRun, in: query, schema: {type: boolean}}
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/CreateCardRequest'}
+      responses:
+        '201':
+          description: Created
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/CreateCardResponse'}
+        '400':
+          description: Invalid
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/CreateCardResponse400'}
+components:
+  schemas:
+    CreateCardRequest:
+      type: object
+      properties:
+        name: {type: string}
+    CreateCardResponse:
+      type: object
+      properties:
+        id: {type: string}
+    CreateCardResponse400:
+      type: object
+      properties:
+        message: {type: string}
+    CreateCardParams:
+      type: object
+      properties:
+        unused: {type: string}
+`
+
+func TestTypeScriptTypesCollisions(t *testing.T) {
+	doc := parseSpec(t, tsCollisionSpec)
+	out, err := TypeScriptTypes(doc, []string{"CreateCardParams"}, []openapi.OperationRef{findOperation(t, doc, "/cards", "POST")})
+	if err != nil {
+		t.Fatalf("TypeScriptTypes: %v", err)
+	}
+
+	declared := make(map[string]int)
+	for _, m := range regexp.MustCompile(`export (?:interface|type) (\w+)`).FindAllStringSubmatch(out, -1) {
+		declared[m[1]]++
+	}
+	for name, count := range declared {
+		if count > 1 {
+			t.Errorf("%s declared %d times:\n%s", name, count, out)
+		}
+	}
+
+	for _, want := range []string{
+		"export interface CreateCardParams {\n  unused?: string;\n}\n",
+		"export interface CreateCardParams2 {\n  dryRun?: boolean;\n}\n",
+		"export interface CreateCardRequest {\n",
+		"export type CreateCardResponse201 = CreateCardResponse;\n",
+		"export type CreateCardResponse2 = CreateCardResponse201;\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+	for _, notWant := range []string{"= CreateCardRequest;", "CreateCardResponse400 = CreateCardResponse400"} {
+		if strings.Contains(out, notWant) {
+			t.Errorf("output contains the circular alias %q:\n%s", notWant, out)
+		}
+	}
+}
@@ -1,7 +1,12 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
+	"strconv"
+	"strings"
 )
 
 // Config holds the configuration for the S3 MCP server
@@ -12,26 +17,247 @@ type Config struct {
 	S3AccessKey string
 	S3SecretKey string
 	S3Endpoint  string // Optional: for S3-compatible services
+	S3Auth      Auth
 
 	// Server Configuration
-	LogLevel string
+	LogLevel  string
+	LogFormat string // text or json
+	LogFile   string // Optional: logs go to stderr when empty
+
+	// MetricsAddr is the address /metrics, /healthz and /readyz are served
+	// on; none when empty
+	MetricsAddr string
+
+	// Tracing exports spans to an OTLP endpoint or a file; it is off when
+	// TracingExporter is empty
+	TracingExporter string // otlp or file
+	TracingEndpoint string // Optional: OTLP/HTTP endpoint URL
+	TracingFile     string // Spans file of the file exporter
+
+	// HighlightFields are error body fields, such as domain error codes,
+	// called out in endpoint details and error response listings
+	HighlightFields []string
+
+	// MaxOutputChars is the default character budget for tool results and
+	// resource reads; zero means unlimited
+	MaxOutputChars int
+
+	// File is the config file the settings were read from, if any
+	File string
+
+	// Sources are the named buckets, directories and git repositories specs
+	// are read from. S3_BUCKET is the source named "default", SPECS_DIR the
+	// one named "local", SPECS_GIT the one named "git"; S3_SOURCES names more.
+	Sources []Source
+}
+
+// Source is a named bucket, a prefix within one, a local directory or a git
+// repository holding spec files
+type Source struct {
+	Name      string
+	Dir       string // Local directory; the S3 settings are ignored when set
+	Git       string // Local clone of a git repository; the S3 settings are ignored when set
+	Ref       string // Branch, tag or commit the git source reads, HEAD by default
+	Bucket    string
+	Prefix    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	Endpoint  string
+	Auth      Auth
+}
+
+// Auth selects a named AWS profile and a role to assume, on top of static
+// keys or the default credential chain
+type Auth struct {
+	Profile              string
+	RoleARN              string
+	ExternalID           string
+	SessionName          string
+	WebIdentityTokenFile string
+}
+
+// Names of the sources configured by S3_BUCKET, SPECS_DIR and SPECS_GIT
+const (
+	DefaultSourceName = "default"
+	LocalSourceName   = "local"
+	GitSourceName     = "git"
+)
+
+// Options are the settings layered over the environment by Load
+type Options struct {
+	// File is the config file to read. When empty, DefaultPath is read if
+	// it exists.
+	File string
+	// Flags are command line overrides, keyed by the environment variable
+	// they replace
+	Flags map[string]string
+}
+
+// Load builds the configuration from command line flags, environment
+// variables and the config file, in that order of precedence, over the
+// defaults
+func Load(opts Options) (*Config, error) {
+	path := opts.File
+	if path == "" {
+		path = DefaultPath()
+	}
+
+	l := layers{flags: opts.Flags}
+	if path != "" {
+		file, err := ReadFile(path)
+		switch {
+		case opts.File == "" && errors.Is(err, fs.ErrNotExist):
+			path = ""
+		case err != nil:
+			return nil, err
+		default:
+			l.file = file.values()
+		}
+	}
+
+	maxOutputChars, err := l.int("MAX_OUTPUT_CHARS", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{
+		S3Region:    l.get("S3_REGION", "us-east-1"),
+		S3Bucket:    l.get("S3_BUCKET", ""),
+		S3AccessKey: l.get("AWS_ACCESS_KEY_ID", ""),
+		S3SecretKey: l.get("AWS_SECRET_ACCESS_KEY", ""),
+		S3Endpoint:  l.get("S3_ENDPOINT", ""),
+		LogLevel:    l.get("LOG_LEVEL", "info"),
+		LogFormat:   l.get("LOG_FORMAT", "text"),
+		LogFile:     l.get("LOG_FILE", ""),
+		MetricsAddr: l.get("METRICS_ADDR", ""),
+
+		TracingExporter: l.get("TRACING_EXPORTER", ""),
+		TracingEndpoint: l.get("TRACING_ENDPOINT", ""),
+		TracingFile:     l.get("TRACING_FILE", ""),
+
+		HighlightFields: splitList(l.get("HIGHLIGHT_FIELDS", "")),
+		MaxOutputChars:  maxOutputChars,
+		File:            path,
+	}
+	cfg.S3Auth = loadAuth(l, "S3_", Auth{})
+	if cfg.Sources, err = loadSources(cfg, l); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadSources reads the default source from S3_BUCKET and S3_PREFIX, the
+// local one from SPECS_DIR, the git one from SPECS_GIT and SPECS_GIT_REF, and
+// each source listed in S3_SOURCES from
+// S3_SOURCE_<NAME>_* variables that fall back to the global region,
+// credentials, profile, role and endpoint. Source names must be unique.
+func loadSources(cfg *Config, l layers) ([]Source, error) {
+	var sources []Source
+	if cfg.S3Bucket != "" {
+		sources = append(sources, Source{
+			Name:      DefaultSourceName,
+			Bucket:    cfg.S3Bucket,
+			Prefix:    l.get("S3_PREFIX", ""),
+			Region:    cfg.S3Region,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			Endpoint:  cfg.S3Endpoint,
+			Auth:      cfg.S3Auth,
+		})
+	}
+	if dir := l.get("SPECS_DIR", ""); dir != "" {
+		sources = append(sources, Source{Name: LocalSourceName, Dir: dir})
+	}
+	if repo := l.get("SPECS_GIT", ""); repo != "" {
+		sources = append(sources, Source{Name: GitSourceName, Git: repo, Ref: l.get("SPECS_GIT_REF", "HEAD")})
+	}
+
+	for _, name := range splitList(l.get("S3_SOURCES", "")) {
+		env := sourceEnvPrefix(name)
+		sources = append(sources, Source{
+			Name:      name,
+			Dir:       l.get(env+"DIR", ""),
+			Git:       l.get(env+"GIT", ""),
+			Ref:       l.get(env+"REF", "HEAD"),
+			Bucket:    l.get(env+"BUCKET", ""),
+			Prefix:    l.get(env+"PREFIX", ""),
+			Region:    l.get(env+"REGION", cfg.S3Region),
+			AccessKey: l.get(env+"ACCESS_KEY_ID", cfg.S3AccessKey),
+			SecretKey: l.get(env+"SECRET_ACCESS_KEY", cfg.S3SecretKey),
+			Endpoint:  l.get(env+"ENDPOINT", cfg.S3Endpoint),
+			Auth:      loadAuth(l, env, cfg.S3Auth),
+		})
+	}
+
+	seen := make(map[string]bool)
+	for _, src := range sources {
+		if seen[src.Name] {
+			return nil, fmt.Errorf("duplicate source name %q: named sources must be unique and may not reuse %s, %s or %s", src.Name, DefaultSourceName, LocalSourceName, GitSourceName)
+		}
+		seen[src.Name] = true
+	}
+	return sources, nil
+}
+
+// loadAuth reads the profile and role settings named with prefix, such as
+// S3_PROFILE and S3_ROLE_ARN, falling back to another source of settings
+func loadAuth(l layers, prefix string, fallback Auth) Auth {
+	return Auth{
+		Profile:              l.get(prefix+"PROFILE", fallback.Profile),
+		RoleARN:              l.get(prefix+"ROLE_ARN", fallback.RoleARN),
+		ExternalID:           l.get(prefix+"EXTERNAL_ID", fallback.ExternalID),
+		SessionName:          l.get(prefix+"ROLE_SESSION_NAME", fallback.SessionName),
+		WebIdentityTokenFile: l.get(prefix+"WEB_IDENTITY_TOKEN_FILE", fallback.WebIdentityTokenFile),
+	}
 }
 
-// Load loads configuration from environment variables
-func Load() *Config {
-	return &Config{
-		S3Region:    getEnvOrDefault("S3_REGION", "us-east-1"),
-		S3Bucket:    getEnvOrDefault("S3_BUCKET", ""),
-		S3AccessKey: getEnvOrDefault("AWS_ACCESS_KEY_ID", ""),
-		S3SecretKey: getEnvOrDefault("AWS_SECRET_ACCESS_KEY", ""),
-		S3Endpoint:  getEnvOrDefault("S3_ENDPOINT", ""),
-		LogLevel:    getEnvOrDefault("LOG_LEVEL", "info"),
+// splitList splits a comma-separated value, dropping empty entries
+func splitList(value string) []string {
+	var out []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
 	}
+	return out
 }
 
-func getEnvOrDefault(key, defaultValue string) string {
+// sourceEnvPrefix is the prefix of the variables configuring a named source
+func sourceEnvPrefix(name string) string {
+	return "S3_SOURCE_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name)) + "_"
+}
+
+// layers looks settings up by environment variable name, preferring command
+// line flags, then the environment, then the config file
+type layers struct {
+	flags map[string]string
+	file  map[string]string
+}
+
+func (l layers) get(key, defaultValue string) string {
+	if value := l.flags[key]; value != "" {
+		return value
+	}
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
+	if value := l.file[key]; value != "" {
+		return value
+	}
 	return defaultValue
 }
+
+// int reads a non-negative integer setting, falling back to the default
+// when it is unset
+func (l layers) int(key string, defaultValue int) (int, error) {
+	raw := l.get(key, "")
+	if raw == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a non-negative integer", key, raw)
+	}
+	return value, nil
+}
@@ -0,0 +1,277 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testFile = `
+s3:
+  bucket: file-bucket
+  region: eu-west-1
+  secret_access_key: file-secret
+git:
+  repo: /srv/specs
+  ref: main
+sources:
+  - name: cards-eu
+    bucket: cards-docs
+    region: eu-central-1
+    role_arn: arn:aws:iam::123456789012:role/cards
+    external_id: cards-partner
+logging:
+  level: debug
+  format: json
+tools:
+  highlight_fields: [code, reason]
+  max_output_chars: 20000
+`
+
+// clearEnv unsets every variable Load reads, so the host environment does
+// not leak into the test
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{
+		"S3_BUCKET", "S3_PREFIX", "S3_REGION", "S3_ENDPOINT", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY",
+		"SPECS_DIR", "SPECS_GIT", "SPECS_GIT_REF", "S3_SOURCES", "LOG_LEVEL", "LOG_FORMAT", "LOG_FILE", "METRICS_ADDR", "TRACING_EXPORTER", "TRACING_ENDPOINT", "TRACING_FILE", "HIGHLIGHT_FIELDS", "MAX_OUTPUT_CHARS",
+		"S3_PROFILE", "S3_ROLE_ARN", "S3_EXTERNAL_ID", "S3_ROLE_SESSION_NAME", "S3_WEB_IDENTITY_TOKEN_FILE",
+		"S3_SOURCE_CARDS_EU_BUCKET", "S3_SOURCE_CARDS_EU_REGION", "S3_SOURCE_CARDS_EU_ROLE_ARN",
+	} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+}
+
+func writeFile(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		flags map[string]string
+		check func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "file over defaults",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.S3Bucket != "file-bucket" || cfg.S3Region != "eu-west-1" || cfg.LogLevel != "debug" || cfg.LogFormat != "json" || cfg.MaxOutputChars != 20000 {
+					t.Errorf("got bucket %q, region %q, log level %q, log format %q, budget %d", cfg.S3Bucket, cfg.S3Region, cfg.LogLevel, cfg.LogFormat, cfg.MaxOutputChars)
+				}
+				if strings.Join(cfg.HighlightFields, ",") != "code,reason" {
+					t.Errorf("highlight fields = %v", cfg.HighlightFields)
+				}
+			},
+		},
+		{
+			name: "env over file",
+			env:  map[string]string{"S3_BUCKET": "env-bucket", "LOG_LEVEL": "warn"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.S3Bucket != "env-bucket" || cfg.LogLevel != "warn" || cfg.S3Region != "eu-west-1" {
+					t.Errorf("got bucket %q, log level %q, region %q", cfg.S3Bucket, cfg.LogLevel, cfg.S3Region)
+				}
+			},
+		},
+		{
+			name:  "flags over env",
+			env:   map[string]string{"S3_BUCKET": "env-bucket"},
+			flags: map[string]string{"S3_BUCKET": "flag-bucket"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.S3Bucket != "flag-bucket" {
+					t.Errorf("bucket = %q", cfg.S3Bucket)
+				}
+			},
+		},
+		{
+			name: "named sources from the file",
+			env:  map[string]string{"S3_SOURCE_CARDS_EU_REGION": "eu-west-3"},
+			check: func(t *testing.T, cfg *Config) {
+				var names []string
+				for _, src := range cfg.Sources {
+					names = append(names, src.Name)
+				}
+				if strings.Join(names, ",") != "default,git,cards-eu" {
+					t.Fatalf("sources = %v", names)
+				}
+				git, cards := cfg.Sources[1], cfg.Sources[2]
+				if git.Git != "/srv/specs" || git.Ref != "main" {
+					t.Errorf("git source = %+v", git)
+				}
+				if cards.Bucket != "cards-docs" || cards.Region != "eu-west-3" || cards.SecretKey != "file-secret" {
+					t.Errorf("cards-eu source = %+v", cards)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			cfg, err := Load(Options{File: writeFile(t, t.TempDir(), testFile), Flags: tt.flags})
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	t.Run("default path", func(t *testing.T) {
+		clearEnv(t)
+		home := os.Getenv("XDG_CONFIG_HOME")
+		path := writeFile(t, filepath.Join(home, "s3-mcp-server"), testFile)
+
+		cfg, err := Load(Options{})
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.File != path || cfg.S3Bucket != "file-bucket" {
+			t.Errorf("read %q with bucket %q, want %s", cfg.File, cfg.S3Bucket, path)
+		}
+	})
+
+	t.Run("no default file", func(t *testing.T) {
+		clearEnv(t)
+		cfg, err := Load(Options{})
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.File != "" || len(cfg.Sources) != 0 {
+			t.Errorf("got file %q and %d sources", cfg.File, len(cfg.Sources))
+		}
+	})
+
+	for name, content := range map[string]string{
+		"unknown setting":  "s3:\n  bukket: x\n",
+		"unnamed source":   "sources:\n  - bucket: x\n",
+		"malformed yaml":   "s3: [\n",
+		"missing explicit": "",
+	} {
+		t.Run(name, func(t *testing.T) {
+			clearEnv(t)
+			path := filepath.Join(t.TempDir(), "missing.yaml")
+			if content != "" {
+				path = writeFile(t, t.TempDir(), content)
+			}
+			if _, err := Load(Options{File: path}); err == nil {
+				t.Error("Load succeeded")
+			}
+		})
+	}
+}
+
+func TestLoadRejectsMalformedNumbers(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   string
+		flag  string
+		file  string
+		want  int
+		valid bool
+	}{
+		{name: "env suffix", env: "10k"},
+		{name: "negative env", env: "-5"},
+		{name: "flag", flag: "lots"},
+		{name: "file", file: "tools:\n  max_output_chars: 10k\n"},
+		{name: "padded env", env: " 2000 ", want: 2000, valid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			t.Setenv("MAX_OUTPUT_CHARS", tt.env)
+			opts := Options{}
+			if tt.flag != "" {
+				opts.Flags = map[string]string{"MAX_OUTPUT_CHARS": tt.flag}
+			}
+			if tt.file != "" {
+				opts.File = writeFile(t, t.TempDir(), tt.file)
+			}
+
+			cfg, err := Load(opts)
+			if !tt.valid {
+				if err == nil {
+					t.Errorf("Load succeeded with a budget of %d", cfg.MaxOutputChars)
+				}
+				return
+			}
+			if err != nil || cfg.MaxOutputChars != tt.want {
+				t.Errorf("Load = %v, %v, want a budget of %d", cfg, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsDuplicateSources(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		file    string
+		wantErr bool
+	}{
+		{name: "bucket and named default", env: map[string]string{"S3_BUCKET": "specs", "S3_SOURCES": "default"}, wantErr: true},
+		{name: "repeated name", env: map[string]string{"S3_SOURCES": "cards, cards"}, wantErr: true},
+		{name: "directory and named local", env: map[string]string{"SPECS_DIR": "/srv/specs", "S3_SOURCES": "local"}, wantErr: true},
+		{name: "repeated file source", file: "sources:\n  - {name: cards, bucket: a}\n  - {name: cards, bucket: b}\n", wantErr: true},
+		{name: "named default without a bucket", env: map[string]string{"S3_SOURCES": "default"}},
+		{name: "distinct names", env: map[string]string{"S3_BUCKET": "specs", "SPECS_GIT": "/srv/specs", "S3_SOURCES": "cards,users"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			opts := Options{}
+			if tt.file != "" {
+				opts.File = writeFile(t, t.TempDir(), tt.file)
+			}
+
+			cfg, err := Load(opts)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "duplicate source name") {
+					t.Errorf("Load = %v, want a duplicate source error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if len(cfg.Sources) == 0 {
+				t.Error("no sources loaded")
+			}
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load(Options{File: writeFile(t, t.TempDir(), testFile)})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	file := cfg.Redacted()
+	if file.S3.SecretKey != redacted || file.S3.AccessKey != "" {
+		t.Errorf("s3 credentials = %q / %q", file.S3.AccessKey, file.S3.SecretKey)
+	}
+	for _, src := range file.Sources {
+		if src.SecretKey != "" && src.SecretKey != redacted {
+			t.Errorf("source %s secret not redacted: %q", src.Name, src.SecretKey)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File is the layout of the YAML config file. Every setting has an
+// environment variable that overrides it.
+type File struct {
+	S3      FileS3       `yaml:"s3,omitempty"`
+	Dir     string       `yaml:"specs_dir,omitempty"`
+	Git     FileGit      `yaml:"git,omitempty"`
+	Sources []FileSource `yaml:"sources,omitempty"`
+	Logging FileLogging  `yaml:"logging,omitempty"`
+	Metrics FileMetrics  `yaml:"metrics,omitempty"`
+	Tracing FileTracing  `yaml:"tracing,omitempty"`
+	Tools   FileTools    `yaml:"tools,omitempty"`
+}
+
+// FileS3 configures the default bucket and the S3 settings other sources
+// fall back to
+type FileS3 struct {
+	Bucket    string `yaml:"bucket,omitempty"`
+	Prefix    string `yaml:"prefix,omitempty"`
+	Region    string `yaml:"region,omitempty"`
+	Endpoint  string `yaml:"endpoint,omitempty"`
+	AccessKey string `yaml:"access_key_id,omitempty"`
+	SecretKey string `yaml:"secret_access_key,omitempty"`
+	FileAuth  `yaml:",inline"`
+}
+
+// FileAuth selects a profile and a role to assume
+type FileAuth struct {
+	Profile              string `yaml:"profile,omitempty"`
+	RoleARN              string `yaml:"role_arn,omitempty"`
+	ExternalID           string `yaml:"external_id,omitempty"`
+	SessionName          string `yaml:"role_session_name,omitempty"`
+	WebIdentityTokenFile string `yaml:"web_identity_token_file,omitempty"`
+}
+
+// FileGit configures the git source
+type FileGit struct {
+	Repo string `yaml:"repo,omitempty"`
+	Ref  string `yaml:"ref,omitempty"`
+}
+
+// FileSource configures a named source
+type FileSource struct {
+	Name      string `yaml:"name"`
+	Bucket    string `yaml:"bucket,omitempty"`
+	Prefix    string `yaml:"prefix,omitempty"`
+	Region    string `yaml:"region,omitempty"`
+	Endpoint  string `yaml:"endpoint,omitempty"`
+	AccessKey string `yaml:"access_key_id,omitempty"`
+	SecretKey string `yaml:"secret_access_key,omitempty"`
+	FileAuth  `yaml:",inline"`
+	Dir       string `yaml:"dir,omitempty"`
+	Git       string `yaml:"git,omitempty"`
+	Ref       string `yaml:"ref,omitempty"`
+}
+
+// FileLogging configures logging
+type FileLogging struct {
+	Level  string `yaml:"level,omitempty"`
+	Format string `yaml:"format,omitempty"`
+	File   string `yaml:"file,omitempty"`
+}
+
+// FileMetrics configures the metrics and health check listener
+type FileMetrics struct {
+	Addr string `yaml:"addr,omitempty"`
+}
+
+// FileTracing configures where spans are exported
+type FileTracing struct {
+	Exporter string `yaml:"exporter,omitempty"`
+	Endpoint string `yaml:"endpoint,omitempty"`
+	File     string `yaml:"file,omitempty"`
+}
+
+// FileTools configures tool output
+type FileTools struct {
+	HighlightFields []string `yaml:"highlight_fields,omitempty"`
+	MaxOutputChars  int      `yaml:"max_output_chars,omitempty"`
+}
+
+// redacted replaces secrets in printed configuration
+const redacted = "<redacted>"
+
+// DefaultPath is the config file read when none is given:
+// $XDG_CONFIG_HOME/s3-mcp-server/config.yaml, or ~/.config/s3-mcp-server/config.yaml
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "s3-mcp-server", "config.yaml")
+}
+
+// ReadFile parses a config file, rejecting unknown settings
+func ReadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file File
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	for i, src := range file.Sources {
+		if src.Name == "" {
+			return nil, fmt.Errorf("config file %s: source %d has no name", path, i+1)
+		}
+	}
+	return &file, nil
+}
+
+// values flattens the file into settings keyed by environment variable name
+func (f *File) values() map[string]string {
+	values := make(map[string]string)
+	set := func(key, value string) {
+		if value != "" {
+			values[key] = value
+		}
+	}
+
+	set("S3_BUCKET", f.S3.Bucket)
+	set("S3_PREFIX", f.S3.Prefix)
+	set("S3_REGION", f.S3.Region)
+	set("S3_ENDPOINT", f.S3.Endpoint)
+	set("AWS_ACCESS_KEY_ID", f.S3.AccessKey)
+	set("AWS_SECRET_ACCESS_KEY", f.S3.SecretKey)
+	f.S3.FileAuth.values("S3_", set)
+	set("SPECS_DIR", expandHome(f.Dir))
+	set("SPECS_GIT", expandHome(f.Git.Repo))
+	set("SPECS_GIT_REF", f.Git.Ref)
+
+	names := make([]string, 0, len(f.Sources))
+	for _, src := range f.Sources {
+		names = append(names, src.Name)
+		env := sourceEnvPrefix(src.Name)
+		set(env+"BUCKET", src.Bucket)
+		set(env+"PREFIX", src.Prefix)
+		set(env+"REGION", src.Region)
+		set(env+"ENDPOINT", src.Endpoint)
+		set(env+"ACCESS_KEY_ID", src.AccessKey)
+		set(env+"SECRET_ACCESS_KEY", src.SecretKey)
+		src.FileAuth.values(env, set)
+		set(env+"DIR", expandHome(src.Dir))
+		set(env+"GIT", expandHome(src.Git))
+		set(env+"REF", src.Ref)
+	}
+	set("S3_SOURCES", strings.Join(names, ","))
+
+	set("LOG_LEVEL", f.Logging.Level)
+	set("LOG_FORMAT", f.Logging.Format)
+	set("LOG_FILE", expandHome(f.Logging.File))
+	set("METRICS_ADDR", f.Metrics.Addr)
+	set("TRACING_EXPORTER", f.Tracing.Exporter)
+	set("TRACING_ENDPOINT", f.Tracing.Endpoint)
+	set("TRACING_FILE", expandHome(f.Tracing.File))
+	set("HIGHLIGHT_FIELDS", strings.Join(f.Tools.HighlightFields, ","))
+	if f.Tools.MaxOutputChars != 0 {
+		set("MAX_OUTPUT_CHARS", strconv.Itoa(f.Tools.MaxOutputChars))
+	}
+	return values
+}
+
+// values sets the profile and role settings named with prefix
+func (a FileAuth) values(prefix string, set func(key, value string)) {
+	set(prefix+"PROFILE", a.Profile)
+	set(prefix+"ROLE_ARN", a.RoleARN)
+	set(prefix+"EXTERNAL_ID", a.ExternalID)
+	set(prefix+"ROLE_SESSION_NAME", a.SessionName)
+	set(prefix+"WEB_IDENTITY_TOKEN_FILE", expandHome(a.WebIdentityTokenFile))
+}
+
+// fileAuth converts loaded auth settings back to their file form
+func fileAuth(a Auth) FileAuth {
+	return FileAuth{
+		Profile:              a.Profile,
+		RoleARN:              a.RoleARN,
+		ExternalID:           a.ExternalID,
+		SessionName:          a.SessionName,
+		WebIdentityTokenFile: a.WebIdentityTokenFile,
+	}
+}
+
+// Redacted renders the effective configuration as a config file, with every
+// source listed under sources and secrets replaced
+func (c *Config) Redacted() *File {
+	file := &File{
+		S3: FileS3{
+			Region:    c.S3Region,
+			Endpoint:  c.S3Endpoint,
+			AccessKey: redact(c.S3AccessKey),
+			SecretKey: redact(c.S3SecretKey),
+			FileAuth:  fileAuth(c.S3Auth),
+		},
+		Logging: FileLogging{Level: c.LogLevel, Format: c.LogFormat, File: c.LogFile},
+		Metrics: FileMetrics{Addr: c.MetricsAddr},
+		Tracing: FileTracing{Exporter: c.TracingExporter, Endpoint: c.TracingEndpoint, File: c.TracingFile},
+		Tools:   FileTools{HighlightFields: c.HighlightFields, MaxOutputChars: c.MaxOutputChars},
+	}
+	for _, src := range c.Sources {
+		out := FileSource{Name: src.Name}
+		switch {
+		case src.Dir != "":
+			out.Dir = src.Dir
+		case src.Git != "":
+			out.Git, out.Ref = src.Git, src.Ref
+		default:
+			out.Bucket, out.Prefix, out.Region, out.Endpoint = src.Bucket, src.Prefix, src.Region, src.Endpoint
+			out.AccessKey, out.SecretKey = redact(src.AccessKey), redact(src.SecretKey)
+			out.FileAuth = fileAuth(src.Auth)
+		}
+		file.Sources = append(file.Sources, out)
+	}
+	return file
+}
+
+// redact hides a secret, keeping whether it is set
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
+
+// expandHome resolves a leading ~/ in a path from the config file
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
@@ -0,0 +1,139 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Options configure the level, format and destination of the logs
+type Options struct {
+	Level  string // debug, info, warn or error
+	Format string // text or json
+	File   string // Appended to; stderr when empty
+}
+
+// New creates a logger from opts. Logs never go to stdout, which carries
+// the MCP messages. A log file stays open for the life of the process.
+func New(opts Options) (*slog.Logger, error) {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	var out io.Writer = os.Stderr
+	if opts.File != "" {
+		file, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		out = file
+	}
+	return NewWriter(out, level, opts.Format)
+}
+
+// NewWriter creates a logger writing entries at level and above to out
+func NewWriter(out io.Writer, level slog.Leveler, format string) (*slog.Logger, error) {
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", "text":
+		handler = slog.NewTextHandler(out, handlerOpts)
+	case "json":
+		handler = slog.NewJSONHandler(out, handlerOpts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q: use text or json", format)
+	}
+	return slog.New(NewHandler(handler)), nil
+}
+
+// ParseLevel parses a LOG_LEVEL value
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q: use debug, info, warn or error", s)
+}
+
+// Request is the MCP request being handled. Entries logged with its context
+// carry its ID, method, tool and S3 call count.
+type Request struct {
+	ID     string
+	Method string
+	Tool   string // Set once the tool of a tools/call request is known
+
+	s3Calls atomic.Int64
+}
+
+// AddS3Call counts an S3 API call made for the request
+func (r *Request) AddS3Call() {
+	r.s3Calls.Add(1)
+}
+
+// S3Calls is the number of S3 API calls made for the request so far
+func (r *Request) S3Calls() int64 {
+	return r.s3Calls.Load()
+}
+
+// requestKey is the context key of the Request
+type requestKey struct{}
+
+// NewContext returns a context carrying req
+func NewContext(ctx context.Context, req *Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, req)
+}
+
+// FromContext returns the request ctx carries, or nil
+func FromContext(ctx context.Context) *Request {
+	req, _ := ctx.Value(requestKey{}).(*Request)
+	return req
+}
+
+// Handler adds the attributes of the request in the context to each entry,
+// and the IDs of the trace and span in it when tracing is on
+type Handler struct {
+	slog.Handler
+}
+
+// NewHandler wraps next so entries carry request attributes
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{Handler: next}
+}
+
+// Handle adds the request and trace attributes and passes the entry on
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	if req := FromContext(ctx); req != nil {
+		record.AddAttrs(slog.String("request_id", req.ID), slog.String("method", req.Method))
+		if req.Tool != "" {
+			record.AddAttrs(slog.String("tool", req.Tool))
+		}
+		record.AddAttrs(slog.Int64("s3_calls", req.S3Calls()))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs keeps adding request attributes to the derived handler
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup keeps adding request attributes to the derived handler
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	for input, want := range map[string]slog.Level{
+		"":        slog.LevelInfo,
+		"debug":   slog.LevelDebug,
+		"INFO":    slog.LevelInfo,
+		"warning": slog.LevelWarn,
+		"error":   slog.LevelError,
+	} {
+		if got, err := ParseLevel(input); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("ParseLevel accepted an unknown level")
+	}
+}
+
+func TestRequestAttributes(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := NewWriter(&out, slog.LevelDebug, "json")
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+
+	req := &Request{ID: "7", Method: "tools/call"}
+	ctx := NewContext(context.Background(), req)
+	req.Tool = "list_yaml_files"
+	req.AddS3Call()
+	req.AddS3Call()
+	logger.With("component", "test").DebugContext(ctx, "with request")
+	logger.Info("without request")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("logged %d entries, want 2:\n%s", len(lines), out.String())
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["request_id"] != "7" || entry["method"] != "tools/call" || entry["tool"] != "list_yaml_files" || entry["s3_calls"] != float64(2) || entry["component"] != "test" {
+		t.Errorf("entry with request = %v", entry)
+	}
+	if strings.Contains(lines[1], "request_id") {
+		t.Errorf("entry without request has request attributes: %s", lines[1])
+	}
+}
+
+func TestNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	logger, err := New(Options{Level: "warn", Format: "text", File: path})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	logger.Info("dropped")
+	logger.Warn("kept")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "dropped") || !strings.Contains(string(data), "msg=kept") {
+		t.Errorf("log file = %q", data)
+	}
+
+	if _, err := New(Options{Format: "xml"}); err == nil {
+		t.Error("New accepted an unknown format")
+	}
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric name
+const namespace = "s3mcp"
+
+// registry holds the server metrics along with the Go runtime and process
+// ones, apart from the global Prometheus registry
+var registry = prometheus.NewRegistry()
+
+var factory = promauto.With(registry)
+
+// Metrics of the MCP requests handled
+var (
+	Requests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "requests_total",
+		Help:      "MCP requests handled, by method, tool and status (ok or error).",
+	}, []string{"method", "tool", "status"})
+
+	RequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "request_duration_seconds",
+		Help:      "Time taken to handle MCP requests, by method and tool.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"method", "tool"})
+
+	RequestsInFlight = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "requests_in_flight",
+		Help:      "MCP requests being handled.",
+	})
+)
+
+// Metrics of the S3 API calls made and the specs read
+var (
+	S3Calls = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "s3_calls_total",
+		Help:      "S3 API calls made, by operation and status (ok or error).",
+	}, []string{"operation", "status"})
+
+	S3Bytes = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "s3_received_bytes_total",
+		Help:      "Response body bytes received from S3, by operation.",
+	}, []string{"operation"})
+
+	ParseFailures = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "spec_parse_failures_total",
+		Help:      "Spec files that failed to parse, by format (openapi or asyncapi).",
+	}, []string{"format"})
+
+	CacheLookups = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_lookups_total",
+		Help:      "Lookups in the caches of spec listings, by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+)
+
+func init() {
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// Lookup labels a cache lookup as hit or miss
+func Lookup(hit bool) string {
+	if hit {
+		return "hit"
+	}
+	return "miss"
+}
+
+// Status labels an outcome as ok or error
+func Status(failed bool) string {
+	if failed {
+		return "error"
+	}
+	return "ok"
+}
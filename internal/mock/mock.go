@@ -0,0 +1,325 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/openapi"
+)
+
+// Handler serves example responses for every operation of a spec and
+// validates incoming requests against the contract
+type Handler struct {
+	doc      *openapi.Document
+	basePath string
+	routes   []route
+}
+
+// route is a compiled path template
+type route struct {
+	path     string
+	segments []string
+	literals int
+	item     *openapi.PathItem
+}
+
+// Server is a running mock HTTP server
+type Server struct {
+	Key        string
+	Addr       string
+	httpServer *http.Server
+}
+
+// validationError is the body returned for requests that break the contract
+type validationError struct {
+	Error   string   `json:"error"`
+	Details []string `json:"details,omitempty"`
+}
+
+// NewHandler creates a mock handler for a parsed spec
+func NewHandler(doc *openapi.Document) *Handler {
+	h := &Handler{doc: doc}
+
+	// Serve under the base path of the first server, e.g. /v1
+	if len(doc.Servers) > 0 {
+		server := doc.Servers[0]
+		base := server.URL
+		for name, variable := range server.Variables {
+			base = strings.ReplaceAll(base, "{"+name+"}", variable.Default)
+		}
+		if u, err := url.Parse(base); err == nil && !strings.Contains(u.Path, "{") {
+			h.basePath = strings.TrimSuffix(u.Path, "/")
+		}
+	}
+
+	for path, item := range doc.Paths {
+		if item == nil {
+			continue
+		}
+		r := route{path: path, segments: splitPath(path), item: item}
+		for _, seg := range r.segments {
+			if !isTemplate(seg) {
+				r.literals++
+			}
+		}
+		h.routes = append(h.routes, r)
+	}
+
+	// Prefer the most specific template, e.g. /cards/me over /cards/{id}
+	sort.Slice(h.routes, func(i, j int) bool {
+		if h.routes[i].literals != h.routes[j].literals {
+			return h.routes[i].literals > h.routes[j].literals
+		}
+		return h.routes[i].path < h.routes[j].path
+	})
+
+	return h
+}
+
+// Start listens on addr and serves the mock in the background
+func Start(key, addr string, doc *openapi.Document) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	srv := &Server{
+		Key:        key,
+		Addr:       listener.Addr().String(),
+		httpServer: &http.Server{Handler: NewHandler(doc), ReadHeaderTimeout: 10 * time.Second},
+	}
+
+	go func() {
+		if err := srv.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			slog.Error("Mock server stopped", "key", key, "error", err)
+		}
+	}()
+
+	return srv, nil
+}
+
+// Stop shuts the mock server down
+func (s *Server) Stop(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	if h.basePath != "" && strings.HasPrefix(path, h.basePath) {
+		path = strings.TrimPrefix(path, h.basePath)
+		if path == "" {
+			path = "/"
+		}
+	}
+
+	rt, pathParams := h.match(path)
+	if rt == nil {
+		writeJSON(w, http.StatusNotFound, validationError{Error: fmt.Sprintf("no operation matches path %s", path)})
+		return
+	}
+
+	op := rt.item.Operation(r.Method)
+	if op == nil {
+		writeJSON(w, http.StatusMethodNotAllowed, validationError{Error: fmt.Sprintf("method %s not defined for %s", r.Method, rt.path)})
+		return
+	}
+	ref := openapi.OperationRef{Path: rt.path, Method: strings.ToUpper(r.Method), PathItem: rt.item, Operation: op}
+
+	if problems := h.validateRequest(ref, r, pathParams); len(problems) > 0 {
+		slog.WarnContext(r.Context(), "Mock request violates contract", "method", r.Method, "path", r.URL.Path, "problems", strings.Join(problems, "; "))
+		writeJSON(w, http.StatusBadRequest, validationError{Error: "request does not match the contract", Details: problems})
+		return
+	}
+
+	h.respond(w, r, op)
+}
+
+// match finds the route for a request path and extracts path parameters
+func (h *Handler) match(path string) (*route, map[string]string) {
+	segments := splitPath(path)
+	for i := range h.routes {
+		rt := &h.routes[i]
+		if len(rt.segments) != len(segments) {
+			continue
+		}
+		params := make(map[string]string)
+		matched := true
+		for j, seg := range rt.segments {
+			if isTemplate(seg) {
+				value, err := url.PathUnescape(segments[j])
+				if err != nil {
+					value = segments[j]
+				}
+				params[strings.Trim(seg, "{}")] = value
+				continue
+			}
+			if seg != segments[j] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return rt, params
+		}
+	}
+	return nil, nil
+}
+
+// validateRequest checks parameters and the JSON body against the contract
+func (h *Handler) validateRequest(ref openapi.OperationRef, r *http.Request, pathParams map[string]string) []string {
+	var problems []string
+
+	query := r.URL.Query()
+	for _, p := range h.doc.Parameters(ref) {
+		var raw []string
+		switch p.In {
+		case "path":
+			if v, ok := pathParams[p.Name]; ok {
+				raw = []string{v}
+			}
+		case "query":
+			raw = query[p.Name]
+		case "header":
+			raw = r.Header.Values(p.Name)
+		case "cookie":
+			if c, err := r.Cookie(p.Name); err == nil {
+				raw = []string{c.Value}
+			}
+		}
+		problems = append(problems, h.doc.ValidateParameter(p, raw)...)
+	}
+
+	body := h.doc.ResolveRequestBody(ref.Operation.RequestBody)
+	if body == nil {
+		return problems
+	}
+
+	if r.ContentLength == 0 {
+		if body.Required {
+			problems = append(problems, "request body is required")
+		}
+		return problems
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	contentType = strings.TrimSpace(contentType)
+
+	mt, ok := body.Content[contentType]
+	if !ok {
+		if len(body.Content) > 0 {
+			problems = append(problems, fmt.Sprintf("unsupported content type %q", contentType))
+		}
+		return problems
+	}
+	if !strings.Contains(contentType, "json") || mt == nil || mt.Schema == nil {
+		return problems
+	}
+
+	var payload interface{}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		if body.Required {
+			problems = append(problems, fmt.Sprintf("request body is not valid JSON: %v", err))
+		}
+		return problems
+	}
+	return append(problems, h.doc.Validate(mt.Schema, payload, "body")...)
+}
+
+// respond writes the example for the first 2xx response, or for the status
+// requested with a "Prefer: code=404" header
+func (h *Handler) respond(w http.ResponseWriter, r *http.Request, op *openapi.Operation) {
+	code := preferredCode(r.Header.Get("Prefer"))
+	if _, ok := op.Responses[code]; code == "" || !ok {
+		code = ""
+		codes := make([]string, 0, len(op.Responses))
+		for c := range op.Responses {
+			codes = append(codes, c)
+		}
+		sort.Strings(codes)
+		for _, c := range codes {
+			if strings.HasPrefix(c, "2") {
+				code = c
+				break
+			}
+		}
+		if code == "" && len(codes) > 0 {
+			code = codes[0]
+		}
+	}
+
+	status := statusFromCode(code)
+	resp := h.doc.ResolveResponse(op.Responses[code])
+	if resp == nil {
+		w.WriteHeader(status)
+		return
+	}
+
+	contentType, mt := openapi.JSONMediaType(resp.Content)
+	if mt == nil {
+		w.WriteHeader(status)
+		return
+	}
+
+	example := h.doc.MediaTypeExample(mt)
+	if str, ok := example.(string); ok && !strings.Contains(contentType, "json") {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(str))
+		return
+	}
+
+	data, err := json.MarshalIndent(example, "", "  ")
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, validationError{Error: fmt.Sprintf("failed to encode example: %v", err)})
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
+
+// preferredCode extracts the status code from a "Prefer: code=NNN" header
+func preferredCode(prefer string) string {
+	for _, part := range strings.Split(prefer, ",") {
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(part, "code=") {
+			return strings.TrimPrefix(part, "code=")
+		}
+	}
+	return ""
+}
+
+// statusFromCode converts a response key such as "201", "2XX" or "default"
+func statusFromCode(code string) int {
+	var status int
+	if _, err := fmt.Sscanf(strings.ReplaceAll(strings.ToUpper(code), "X", "0"), "%d", &status); err != nil || status < 100 {
+		return http.StatusOK
+	}
+	return status
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func isTemplate(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
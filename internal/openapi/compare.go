@@ -0,0 +1,292 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kinds of change reported by Compare
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+// Change is one difference between two versions of a document. Breaking
+// changes are those that can fail clients written against the base version.
+type Change struct {
+	Kind     string
+	Location string
+	Detail   string
+	Breaking bool
+}
+
+// Compare lists the differences from base to head: operations, parameters,
+// request bodies, responses, security and component schemas, in document
+// order
+func Compare(base, head *Document) []Change {
+	var out []Change
+	if base.Info.Version != head.Info.Version {
+		out = append(out, Change{
+			Kind:     ChangeModified,
+			Location: "info.version",
+			Detail:   fmt.Sprintf("%s → %s", orNone(base.Info.Version), orNone(head.Info.Version)),
+		})
+	}
+
+	baseOps := make(map[string]OperationRef)
+	for _, op := range base.Operations() {
+		baseOps[op.Method+" "+op.Path] = op
+	}
+	headOps := make(map[string]bool)
+	for _, op := range head.Operations() {
+		name := op.Method + " " + op.Path
+		headOps[name] = true
+		old, ok := baseOps[name]
+		if !ok {
+			out = append(out, Change{Kind: ChangeAdded, Location: name, Detail: "new operation"})
+			continue
+		}
+		out = append(out, compareOperations(base, head, old, op, name)...)
+	}
+	for _, op := range base.Operations() {
+		if name := op.Method + " " + op.Path; !headOps[name] {
+			out = append(out, Change{Kind: ChangeRemoved, Location: name, Detail: "operation removed", Breaking: true})
+		}
+	}
+
+	return append(out, compareComponentSchemas(base, head)...)
+}
+
+// compareOperations compares two versions of one operation
+func compareOperations(base, head *Document, old, op OperationRef, name string) []Change {
+	var out []Change
+	if !old.Operation.Deprecated && op.Operation.Deprecated {
+		out = append(out, Change{Kind: ChangeModified, Location: name, Detail: "now deprecated"})
+	}
+
+	oldParams := make(map[string]*Parameter)
+	for _, p := range base.Parameters(old) {
+		oldParams[p.In+":"+p.Name] = p
+	}
+	seen := make(map[string]bool)
+	for _, p := range head.Parameters(op) {
+		id := p.In + ":" + p.Name
+		seen[id] = true
+		location := fmt.Sprintf("%s %s parameter '%s'", name, p.In, p.Name)
+		prev, ok := oldParams[id]
+		switch {
+		case !ok && p.Required:
+			out = append(out, Change{Kind: ChangeAdded, Location: location, Detail: "new required parameter", Breaking: true})
+		case !ok:
+			out = append(out, Change{Kind: ChangeAdded, Location: location, Detail: "new optional parameter"})
+		default:
+			if !prev.Required && p.Required {
+				out = append(out, Change{Kind: ChangeModified, Location: location, Detail: "now required", Breaking: true})
+			} else if prev.Required && !p.Required {
+				out = append(out, Change{Kind: ChangeModified, Location: location, Detail: "now optional"})
+			}
+			if from, to := typeName(prev.Schema), typeName(p.Schema); from != to {
+				out = append(out, Change{Kind: ChangeModified, Location: location, Detail: fmt.Sprintf("type %s → %s", orNone(from), orNone(to)), Breaking: true})
+			}
+		}
+	}
+	for _, p := range base.Parameters(old) {
+		if !seen[p.In+":"+p.Name] {
+			location := fmt.Sprintf("%s %s parameter '%s'", name, p.In, p.Name)
+			out = append(out, Change{Kind: ChangeRemoved, Location: location, Detail: "parameter removed", Breaking: true})
+		}
+	}
+
+	oldBody, body := base.ResolveRequestBody(old.Operation.RequestBody), head.ResolveRequestBody(op.Operation.RequestBody)
+	location := name + " request body"
+	switch {
+	case oldBody == nil && body != nil:
+		out = append(out, Change{Kind: ChangeAdded, Location: location, Detail: requiredLabel(body.Required) + " request body", Breaking: body.Required})
+	case oldBody != nil && body == nil:
+		out = append(out, Change{Kind: ChangeRemoved, Location: location, Detail: "request body removed", Breaking: true})
+	case oldBody != nil && body != nil:
+		if !oldBody.Required && body.Required {
+			out = append(out, Change{Kind: ChangeModified, Location: location, Detail: "now required", Breaking: true})
+		}
+		_, oldType := JSONMediaType(oldBody.Content)
+		_, newType := JSONMediaType(body.Content)
+		if oldType != nil && newType != nil {
+			if from, to := typeName(oldType.Schema), typeName(newType.Schema); from != to {
+				out = append(out, Change{Kind: ChangeModified, Location: location, Detail: fmt.Sprintf("schema %s → %s", orNone(from), orNone(to)), Breaking: true})
+			}
+		}
+	}
+
+	for _, code := range sortedKeys(op.Operation.Responses) {
+		if _, ok := old.Operation.Responses[code]; !ok {
+			out = append(out, Change{Kind: ChangeAdded, Location: fmt.Sprintf("%s response %s", name, code), Detail: "new response"})
+		}
+	}
+	for _, code := range sortedKeys(old.Operation.Responses) {
+		if _, ok := op.Operation.Responses[code]; !ok {
+			out = append(out, Change{Kind: ChangeRemoved, Location: fmt.Sprintf("%s response %s", name, code), Detail: "response removed", Breaking: true})
+		}
+	}
+
+	oldSchemes := securitySchemes(base.EffectiveSecurity(old.Operation))
+	newSchemes := securitySchemes(head.EffectiveSecurity(op.Operation))
+	if from, to := strings.Join(oldSchemes, ", "), strings.Join(newSchemes, ", "); from != to {
+		out = append(out, Change{
+			Kind:     ChangeModified,
+			Location: name + " security",
+			Detail:   fmt.Sprintf("%s → %s", orNone(from), orNone(to)),
+			Breaking: len(newSchemes) > 0 && !subset(newSchemes, oldSchemes),
+		})
+	}
+	return out
+}
+
+// compareComponentSchemas compares the named schemas of two documents,
+// property by property
+func compareComponentSchemas(base, head *Document) []Change {
+	var out []Change
+	for _, name := range sortedKeys(head.Components.Schemas) {
+		schema := head.Components.Schemas[name]
+		old, ok := base.Components.Schemas[name]
+		if !ok {
+			out = append(out, Change{Kind: ChangeAdded, Location: "schema " + name, Detail: "new schema"})
+			continue
+		}
+		if old == nil || schema == nil {
+			continue
+		}
+		out = append(out, compareSchemas(old, schema, "schema "+name)...)
+	}
+	for _, name := range sortedKeys(base.Components.Schemas) {
+		if _, ok := head.Components.Schemas[name]; !ok {
+			out = append(out, Change{Kind: ChangeRemoved, Location: "schema " + name, Detail: "schema removed", Breaking: true})
+		}
+	}
+	return out
+}
+
+// compareSchemas compares the type, enum, required list and direct
+// properties of two versions of a schema
+func compareSchemas(old, schema *Schema, location string) []Change {
+	var out []Change
+	if from, to := typeName(old), typeName(schema); from != to {
+		out = append(out, Change{Kind: ChangeModified, Location: location, Detail: fmt.Sprintf("type %s → %s", orNone(from), orNone(to)), Breaking: true})
+	}
+
+	oldEnum, newEnum := enumValues(old.Enum), enumValues(schema.Enum)
+	if added := missing(newEnum, oldEnum); len(added) > 0 {
+		out = append(out, Change{Kind: ChangeAdded, Location: location, Detail: "enum values " + strings.Join(added, ", ")})
+	}
+	if removed := missing(oldEnum, newEnum); len(removed) > 0 {
+		out = append(out, Change{Kind: ChangeRemoved, Location: location, Detail: "enum values " + strings.Join(removed, ", "), Breaking: true})
+	}
+
+	for _, name := range sortedKeys(schema.Properties) {
+		prop := location + "." + name
+		if _, ok := old.Properties[name]; !ok {
+			required := contains(schema.Required, name)
+			out = append(out, Change{Kind: ChangeAdded, Location: prop, Detail: "new " + requiredLabel(required) + " property", Breaking: required})
+			continue
+		}
+		if !contains(old.Required, name) && contains(schema.Required, name) {
+			out = append(out, Change{Kind: ChangeModified, Location: prop, Detail: "now required", Breaking: true})
+		}
+		if from, to := typeName(old.Properties[name]), typeName(schema.Properties[name]); from != to {
+			out = append(out, Change{Kind: ChangeModified, Location: prop, Detail: fmt.Sprintf("type %s → %s", orNone(from), orNone(to)), Breaking: true})
+		}
+	}
+	for _, name := range sortedKeys(old.Properties) {
+		if _, ok := schema.Properties[name]; !ok {
+			out = append(out, Change{Kind: ChangeRemoved, Location: location + "." + name, Detail: "property removed", Breaking: true})
+		}
+	}
+	return out
+}
+
+// typeName describes the shape of a schema: the component it references, or
+// its type with item types for arrays
+func typeName(s *Schema) string {
+	if s == nil {
+		return ""
+	}
+	if s.Ref != "" {
+		return RefName(s.Ref)
+	}
+	if s.Type == "array" && s.Items != nil {
+		if item := typeName(s.Items); item != "" {
+			return item + "[]"
+		}
+	}
+	if s.Format != "" {
+		return s.Type + "(" + s.Format + ")"
+	}
+	return s.Type
+}
+
+// securitySchemes lists the scheme names any requirement mentions
+func securitySchemes(reqs []SecurityRequirement) []string {
+	set := make(map[string]bool)
+	for _, req := range reqs {
+		for name := range req {
+			set[name] = true
+		}
+	}
+	return sortedKeys(set)
+}
+
+func enumValues(values []interface{}) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		out = append(out, fmt.Sprint(v))
+	}
+	return out
+}
+
+// missing returns the values of a that are not in b
+func missing(a, b []string) []string {
+	var out []string
+	for _, v := range a {
+		if !contains(b, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func subset(a, b []string) bool {
+	return len(missing(a, b)) == 0
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func requiredLabel(required bool) string {
+	if required {
+		return "required"
+	}
+	return "optional"
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,177 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sunsetExtensions are the vendor extensions read as a removal date, in order
+// of preference
+var sunsetExtensions = []string{"x-sunset", "x-sunset-date", "x-removal-date", "x-remove-after"}
+
+// sunsetLayouts are the date formats accepted in sunset extensions
+var sunsetLayouts = []string{"2006-01-02", time.RFC3339, time.RFC1123, "2006-01"}
+
+// Deprecation is an operation, parameter, schema or property that is marked
+// deprecated or scheduled for removal
+type Deprecation struct {
+	Kind     string
+	Location string
+	Sunset   string
+	Date     time.Time
+	Note     string
+}
+
+// Deprecations lists every deprecated element of the document, sorted by
+// sunset date with undated entries last
+func (d *Document) Deprecations() []Deprecation {
+	var out []Deprecation
+
+	for _, op := range d.Operations() {
+		name := op.Method + " " + op.Path
+		if dep, ok := deprecation("operation", name, op.Operation.Deprecated, op.Operation.Extra); ok {
+			dep.Note = op.Operation.Summary
+			out = append(out, dep)
+		}
+
+		// Inline parameter, body and response schemas; referenced ones are
+		// covered by components
+		for _, p := range d.Parameters(op) {
+			location := fmt.Sprintf("%s %s parameter '%s'", name, p.In, p.Name)
+			if dep, ok := deprecation("parameter", location, p.Deprecated, p.Extra); ok {
+				dep.Note = p.Description
+				out = append(out, dep)
+			}
+			out = append(out, inlineDeprecations(p.Schema, location)...)
+		}
+
+		if body := d.ResolveRequestBody(op.Operation.RequestBody); body != nil {
+			if _, mt := JSONMediaType(body.Content); mt != nil {
+				out = append(out, inlineDeprecations(mt.Schema, name+" request body")...)
+			}
+		}
+
+		codes := make([]string, 0, len(op.Operation.Responses))
+		for code := range op.Operation.Responses {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			resp := d.ResolveResponse(op.Operation.Responses[code])
+			if resp == nil {
+				continue
+			}
+			if _, mt := JSONMediaType(resp.Content); mt != nil {
+				out = append(out, inlineDeprecations(mt.Schema, name+" response "+code)...)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(d.Components.Schemas))
+	for name := range d.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		schema := d.Components.Schemas[name]
+		if schema == nil {
+			continue
+		}
+		if dep, ok := deprecation("schema", name, schema.Deprecated, schema.Extra); ok {
+			dep.Note = schema.Description
+			out = append(out, dep)
+		}
+		out = append(out, schemaDeprecations(schema, name, 0)...)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i].Date, out[j].Date
+		if a.IsZero() != b.IsZero() {
+			return !a.IsZero()
+		}
+		return a.Before(b)
+	})
+	return out
+}
+
+// inlineDeprecations walks a schema unless it references a component
+func inlineDeprecations(s *Schema, location string) []Deprecation {
+	if s == nil || s.Ref != "" {
+		return nil
+	}
+	return schemaDeprecations(s, location, 0)
+}
+
+// schemaDeprecations walks the properties of an inline schema without
+// following references
+func schemaDeprecations(s *Schema, location string, depth int) []Deprecation {
+	if s == nil || depth > maxExampleDepth {
+		return nil
+	}
+
+	var out []Deprecation
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop := s.Properties[name]
+		if prop == nil {
+			continue
+		}
+		child := location + "." + name
+		if dep, ok := deprecation("property", child, prop.Deprecated, prop.Extra); ok {
+			dep.Note = prop.Description
+			out = append(out, dep)
+		}
+		if prop.Ref == "" {
+			out = append(out, schemaDeprecations(prop, child, depth+1)...)
+		}
+	}
+
+	if s.Items != nil && s.Items.Ref == "" {
+		out = append(out, schemaDeprecations(s.Items, location+"[]", depth+1)...)
+	}
+	for _, list := range [][]*Schema{s.AllOf, s.OneOf, s.AnyOf} {
+		for _, sub := range list {
+			if sub != nil && sub.Ref == "" {
+				out = append(out, schemaDeprecations(sub, location, depth+1)...)
+			}
+		}
+	}
+	return out
+}
+
+// deprecation builds an entry when the element is flagged deprecated or
+// carries a sunset extension
+func deprecation(kind, location string, deprecated bool, extra map[string]interface{}) (Deprecation, bool) {
+	dep := Deprecation{Kind: kind, Location: location}
+	for _, key := range sunsetExtensions {
+		if value, ok := extra[key]; ok && value != nil {
+			dep.Sunset, dep.Date = sunsetValue(value)
+			break
+		}
+	}
+	if flag, ok := extra["x-deprecated"].(bool); ok && flag {
+		deprecated = true
+	}
+	return dep, deprecated || dep.Sunset != ""
+}
+
+// sunsetValue normalizes a sunset extension, which YAML may already have
+// decoded as a timestamp
+func sunsetValue(value interface{}) (string, time.Time) {
+	if t, ok := value.(time.Time); ok {
+		return t.Format("2006-01-02"), t
+	}
+	raw := strings.TrimSpace(fmt.Sprint(value))
+	for _, layout := range sunsetLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return raw, t
+		}
+	}
+	return raw, time.Time{}
+}
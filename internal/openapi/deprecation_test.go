@@ -0,0 +1,111 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const deprecationSpec = `
+openapi: 3.0.3
+info: {title: Cards, version: '1'}
+paths:
+  /cards:
+    get:
+      summary: List cards
+      deprecated: true
+      x-sunset: 2025-06-30
+      parameters:
+        - {name: page, in: query, deprecated: true, description: Use cursor}
+        - name: filter
+          in: query
+          schema:
+            type: object
+            properties:
+              legacy: {type: string, deprecated: true}
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  total: {type: integer, x-sunset: 2025-01-31}
+                  items:
+                    type: array
+                    items:
+                      type: object
+                      properties:
+                        code: {type: string, x-deprecated: true}
+        '404': {$ref: '#/components/responses/NotFound'}
+    post:
+      x-removal-date: someday
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name: {type: string, deprecated: true}
+      responses:
+        '201':
+          description: Created
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Card'}
+components:
+  responses:
+    NotFound:
+      description: Missing
+      content:
+        application/json:
+          schema:
+            type: object
+            properties:
+              reason: {type: string, deprecated: true}
+  schemas:
+    Card:
+      type: object
+      deprecated: true
+      x-sunset: '2026-01'
+      properties:
+        pan: {type: string, deprecated: true}
+`
+
+func TestDeprecations(t *testing.T) {
+	got := parse(t, deprecationSpec).Deprecations()
+
+	want := []string{
+		"property GET /cards response 200.total",
+		"operation GET /cards",
+		"schema Card",
+		"parameter GET /cards query parameter 'page'",
+		"property GET /cards query parameter 'filter'.legacy",
+		"property GET /cards response 200.items[].code",
+		"property GET /cards response 404.reason",
+		"operation POST /cards",
+		"property POST /cards request body.name",
+		"property Card.pan",
+	}
+	var locations []string
+	for _, dep := range got {
+		locations = append(locations, dep.Kind+" "+dep.Location)
+	}
+	if !reflect.DeepEqual(locations, want) {
+		t.Fatalf("deprecations =\n%v\nwant\n%v", locations, want)
+	}
+
+	if op := got[1]; op.Sunset != "2025-06-30" || !op.Date.Equal(time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)) || op.Note != "List cards" {
+		t.Errorf("operation = %+v", op)
+	}
+	if schema := got[2]; schema.Sunset != "2026-01" || schema.Date.IsZero() {
+		t.Errorf("schema = %+v", schema)
+	}
+	if param := got[3]; param.Note != "Use cursor" || param.Sunset != "" {
+		t.Errorf("parameter = %+v", param)
+	}
+	if post := got[7]; post.Sunset != "someday" || !post.Date.IsZero() {
+		t.Errorf("undated sunset = %+v", post)
+	}
+}
@@ -0,0 +1,108 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+)
+
+// ErrorResponse is a 4xx, 5xx or default response of an operation
+type ErrorResponse struct {
+	Status      string
+	Description string
+	ContentType string
+	SchemaName  string
+	Fields      []ErrorField
+}
+
+// ErrorField is a property of an error body, flattened to a dotted path
+type ErrorField struct {
+	Path        string
+	Type        string
+	Description string
+	Enum        []interface{}
+}
+
+// ErrorResponses returns the 4xx and 5xx responses of an operation
+// (including default and ranges such as 4XX) with their body fields
+// flattened
+func (d *Document) ErrorResponses(op OperationRef) []ErrorResponse {
+	codes := make([]string, 0, len(op.Operation.Responses))
+	for code := range op.Operation.Responses {
+		if isErrorStatus(code) {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	var out []ErrorResponse
+	for _, code := range codes {
+		resp := d.ResolveResponse(op.Operation.Responses[code])
+		if resp == nil {
+			continue
+		}
+		er := ErrorResponse{Status: code, Description: resp.Description}
+		contentType, mt := JSONMediaType(resp.Content)
+		if mt != nil && mt.Schema != nil {
+			er.ContentType = contentType
+			er.SchemaName = RefName(mt.Schema.Ref)
+			er.Fields = d.errorFields(mt.Schema, "", 0)
+		}
+		out = append(out, er)
+	}
+	return out
+}
+
+// isErrorStatus reports whether a responses key describes an error: a 4xx
+// or 5xx code, a 4XX or 5XX range, or default
+func isErrorStatus(code string) bool {
+	if code == "default" {
+		return true
+	}
+	if len(code) != 3 || (code[0] != '4' && code[0] != '5') {
+		return false
+	}
+	rest := strings.ToUpper(code[1:])
+	if rest == "XX" {
+		return true
+	}
+	return rest[0] >= '0' && rest[0] <= '9' && rest[1] >= '0' && rest[1] <= '9'
+}
+
+// errorFields flattens the properties of a schema, following references
+// and merging allOf
+func (d *Document) errorFields(s *Schema, prefix string, depth int) []ErrorField {
+	s = d.ResolveSchema(s)
+	if s == nil || depth > maxExampleDepth {
+		return nil
+	}
+
+	var out []ErrorField
+	for _, sub := range s.AllOf {
+		out = append(out, d.errorFields(sub, prefix, depth+1)...)
+	}
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		prop := d.ResolveSchema(s.Properties[name])
+		if prop == nil {
+			continue
+		}
+		out = append(out, ErrorField{Path: path, Type: prop.Type, Description: prop.Description, Enum: prop.Enum})
+
+		switch {
+		case len(prop.Properties) > 0 || len(prop.AllOf) > 0:
+			out = append(out, d.errorFields(prop, path, depth+1)...)
+		case prop.Type == "array" && prop.Items != nil:
+			out = append(out, d.errorFields(prop.Items, path+"[]", depth+1)...)
+		}
+	}
+	return out
+}
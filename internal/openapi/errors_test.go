@@ -0,0 +1,100 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+const errorsSpec = `
+openapi: 3.0.3
+info: {title: Cards, version: '1'}
+paths:
+  /cards:
+    get:
+      responses:
+        '100': {description: Continue}
+        '200': {description: OK}
+        '304': {description: Not modified}
+        '3XX': {description: Redirect}
+        '404': {$ref: '#/components/responses/NotFound'}
+        4XX:
+          description: Client error
+          content:
+            application/json:
+              schema:
+                allOf:
+                  - {$ref: '#/components/schemas/Error'}
+                  - type: object
+                    properties:
+                      details:
+                        type: array
+                        items:
+                          type: object
+                          properties:
+                            field: {type: string}
+        '503': {description: Unavailable}
+        default: {description: Unexpected}
+components:
+  responses:
+    NotFound:
+      description: Missing
+      content:
+        application/json:
+          schema: {$ref: '#/components/schemas/Error'}
+  schemas:
+    Error:
+      type: object
+      properties:
+        code: {type: string, enum: [CARD_NOT_FOUND, CARD_FROZEN], description: Domain error}
+        message: {type: string}
+`
+
+func TestErrorResponses(t *testing.T) {
+	doc := parse(t, errorsSpec)
+	op, err := doc.FindOperation("/cards", "GET")
+	if err != nil {
+		t.Fatalf("FindOperation: %v", err)
+	}
+	got := doc.ErrorResponses(*op)
+
+	var codes []string
+	for _, er := range got {
+		codes = append(codes, er.Status)
+	}
+	if want := []string{"404", "4XX", "503", "default"}; !reflect.DeepEqual(codes, want) {
+		t.Fatalf("statuses = %v, want %v", codes, want)
+	}
+
+	notFound := got[0]
+	if notFound.Description != "Missing" || notFound.ContentType != "application/json" || notFound.SchemaName != "Error" {
+		t.Errorf("404 = %+v", notFound)
+	}
+	if len(notFound.Fields) != 2 || notFound.Fields[0].Path != "code" || len(notFound.Fields[0].Enum) != 2 || notFound.Fields[0].Description != "Domain error" {
+		t.Errorf("404 fields = %+v", notFound.Fields)
+	}
+
+	var paths []string
+	for _, f := range got[1].Fields {
+		paths = append(paths, f.Path)
+	}
+	if want := []string{"code", "message", "details", "details[].field"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("4XX fields = %v, want %v", paths, want)
+	}
+	if got[2].ContentType != "" || got[2].Fields != nil {
+		t.Errorf("503 without a body = %+v", got[2])
+	}
+}
+
+func TestIsErrorStatus(t *testing.T) {
+	tests := map[string]bool{
+		"400": true, "404": true, "500": true, "599": true,
+		"4XX": true, "5xx": true, "default": true,
+		"100": false, "200": false, "2XX": false, "304": false, "3XX": false,
+		"40": false, "4000": false, "4X0": false, "Default": false,
+	}
+	for code, want := range tests {
+		if got := isErrorStatus(code); got != want {
+			t.Errorf("isErrorStatus(%q) = %v, want %v", code, got, want)
+		}
+	}
+}
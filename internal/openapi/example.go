@@ -0,0 +1,159 @@
+package openapi
+
+import (
+	"sort"
+)
+
+// maxExampleDepth bounds example generation for recursive schemas
+const maxExampleDepth = 8
+
+// Example returns an example value for a schema, preferring explicit
+// examples and defaults and otherwise generating a placeholder from the type
+func (d *Document) Example(s *Schema) interface{} {
+	return d.example(s, 0, false)
+}
+
+// RequestExample is like Example but leaves out readOnly properties
+func (d *Document) RequestExample(s *Schema) interface{} {
+	return d.example(s, 0, true)
+}
+
+// MediaTypeExample returns the example for a media type, using the first
+// named example when no inline example is present
+func (d *Document) MediaTypeExample(mt *MediaType) interface{} {
+	return d.mediaTypeExample(mt, false)
+}
+
+// RequestMediaTypeExample is like MediaTypeExample but leaves generated
+// payloads without readOnly properties
+func (d *Document) RequestMediaTypeExample(mt *MediaType) interface{} {
+	return d.mediaTypeExample(mt, true)
+}
+
+func (d *Document) mediaTypeExample(mt *MediaType, request bool) interface{} {
+	if mt == nil {
+		return nil
+	}
+	if mt.Example != nil {
+		return mt.Example
+	}
+	if len(mt.Examples) > 0 {
+		names := make([]string, 0, len(mt.Examples))
+		for name := range mt.Examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if ex := mt.Examples[names[0]]; ex != nil && ex.Value != nil {
+			return ex.Value
+		}
+	}
+	return d.example(mt.Schema, 0, request)
+}
+
+// ParameterExample returns an example value for a parameter
+func (d *Document) ParameterExample(p *Parameter) interface{} {
+	if p.Example != nil {
+		return p.Example
+	}
+	if p.Schema == nil {
+		return "string"
+	}
+	return d.Example(p.Schema)
+}
+
+func (d *Document) example(s *Schema, depth int, request bool) interface{} {
+	s = d.ResolveSchema(s)
+	if s == nil || depth > maxExampleDepth {
+		return nil
+	}
+
+	if s.Example != nil {
+		return s.Example
+	}
+	if s.Default != nil {
+		return s.Default
+	}
+	if len(s.Enum) > 0 {
+		return s.Enum[0]
+	}
+
+	if len(s.AllOf) > 0 {
+		merged := map[string]interface{}{}
+		for _, sub := range s.AllOf {
+			if obj, ok := d.example(sub, depth+1, request).(map[string]interface{}); ok {
+				for k, v := range obj {
+					merged[k] = v
+				}
+			}
+		}
+		for k, v := range d.objectExample(s, depth, request) {
+			merged[k] = v
+		}
+		return merged
+	}
+	if len(s.OneOf) > 0 {
+		return d.example(s.OneOf[0], depth+1, request)
+	}
+	if len(s.AnyOf) > 0 {
+		return d.example(s.AnyOf[0], depth+1, request)
+	}
+
+	switch s.Type {
+	case "string":
+		return stringExample(s.Format)
+	case "integer":
+		return 0
+	case "number":
+		return 0.0
+	case "boolean":
+		return true
+	case "array":
+		if item := d.example(s.Items, depth+1, request); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case "object", "":
+		if s.Type == "" && len(s.Properties) == 0 && s.AdditionalProperties == nil {
+			return nil
+		}
+		return d.objectExample(s, depth, request)
+	}
+	return nil
+}
+
+func (d *Document) objectExample(s *Schema, depth int, request bool) map[string]interface{} {
+	obj := map[string]interface{}{}
+	for name, prop := range s.Properties {
+		resolved := d.ResolveSchema(prop)
+		if request && resolved != nil && resolved.ReadOnly {
+			continue
+		}
+		if v := d.example(prop, depth+1, request); v != nil {
+			obj[name] = v
+		}
+	}
+	if len(obj) == 0 && s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+		if v := d.example(s.AdditionalProperties.Schema, depth+1, request); v != nil {
+			obj["key"] = v
+		}
+	}
+	return obj
+}
+
+func stringExample(format string) string {
+	switch format {
+	case "date":
+		return "2024-01-01"
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "uuid":
+		return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	case "email":
+		return "user@example.com"
+	case "uri", "url":
+		return "https://example.com"
+	case "byte":
+		return "U3dhZ2dlciByb2Nrcw=="
+	}
+	return "string"
+}
@@ -0,0 +1,503 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNotOpenAPI is returned by Parse for documents with neither an openapi
+// nor a swagger key
+var ErrNotOpenAPI = errors.New("document is not an OpenAPI or Swagger specification")
+
+// Document represents a parsed OpenAPI 3 document
+type Document struct {
+	// ConvertedFrom is the Swagger version the document was converted from,
+	// empty for native OpenAPI 3 documents
+	ConvertedFrom string `yaml:"-"`
+
+	OpenAPI    string                `yaml:"openapi,omitempty"`
+	Info       Info                  `yaml:"info,omitempty"`
+	Servers    []Server              `yaml:"servers,omitempty"`
+	Paths      map[string]*PathItem  `yaml:"paths,omitempty"`
+	Components Components            `yaml:"components,omitempty"`
+	Security   []SecurityRequirement `yaml:"security,omitempty"`
+	Tags       []Tag                 `yaml:"tags,omitempty"`
+
+	// Extra keeps the fields not modeled above, such as externalDocs, so
+	// that a marshaled document loses nothing
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// Info holds the document metadata
+type Info struct {
+	Title       string                 `yaml:"title,omitempty"`
+	Description string                 `yaml:"description,omitempty"`
+	Version     string                 `yaml:"version,omitempty"`
+	Extra       map[string]interface{} `yaml:",inline"`
+}
+
+// Server represents an entry of the servers list
+type Server struct {
+	URL         string                    `yaml:"url,omitempty"`
+	Description string                    `yaml:"description,omitempty"`
+	Variables   map[string]ServerVariable `yaml:"variables,omitempty"`
+	Extra       map[string]interface{}    `yaml:",inline"`
+}
+
+// ServerVariable represents a substitution variable in a server URL
+type ServerVariable struct {
+	Default     string   `yaml:"default,omitempty"`
+	Enum        []string `yaml:"enum,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+}
+
+// Tag represents a document-level tag
+type Tag struct {
+	Name        string                 `yaml:"name,omitempty"`
+	Description string                 `yaml:"description,omitempty"`
+	Extra       map[string]interface{} `yaml:",inline"`
+}
+
+// Components holds the reusable objects of a document
+type Components struct {
+	Schemas         map[string]*Schema         `yaml:"schemas,omitempty"`
+	Parameters      map[string]*Parameter      `yaml:"parameters,omitempty"`
+	RequestBodies   map[string]*RequestBody    `yaml:"requestBodies,omitempty"`
+	Responses       map[string]*Response       `yaml:"responses,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `yaml:"securitySchemes,omitempty"`
+	Extra           map[string]interface{}     `yaml:",inline"`
+}
+
+// PathItem holds the operations available on a single path
+type PathItem struct {
+	Ref        string                 `yaml:"$ref,omitempty"`
+	Summary    string                 `yaml:"summary,omitempty"`
+	Parameters []*Parameter           `yaml:"parameters,omitempty"`
+	Get        *Operation             `yaml:"get,omitempty"`
+	Put        *Operation             `yaml:"put,omitempty"`
+	Post       *Operation             `yaml:"post,omitempty"`
+	Delete     *Operation             `yaml:"delete,omitempty"`
+	Options    *Operation             `yaml:"options,omitempty"`
+	Head       *Operation             `yaml:"head,omitempty"`
+	Patch      *Operation             `yaml:"patch,omitempty"`
+	Trace      *Operation             `yaml:"trace,omitempty"`
+	Extra      map[string]interface{} `yaml:",inline"`
+}
+
+// Operation represents a single API operation on a path
+type Operation struct {
+	OperationID string                 `yaml:"operationId,omitempty"`
+	Summary     string                 `yaml:"summary,omitempty"`
+	Description string                 `yaml:"description,omitempty"`
+	Tags        []string               `yaml:"tags,omitempty"`
+	Parameters  []*Parameter           `yaml:"parameters,omitempty"`
+	RequestBody *RequestBody           `yaml:"requestBody,omitempty"`
+	Responses   map[string]*Response   `yaml:"responses,omitempty"`
+	Security    *[]SecurityRequirement `yaml:"security,omitempty"`
+	Deprecated  bool                   `yaml:"deprecated,omitempty"`
+	Extra       map[string]interface{} `yaml:",inline"`
+}
+
+// Parameter represents an operation parameter
+type Parameter struct {
+	Ref         string                 `yaml:"$ref,omitempty"`
+	Name        string                 `yaml:"name,omitempty"`
+	In          string                 `yaml:"in,omitempty"`
+	Description string                 `yaml:"description,omitempty"`
+	Required    bool                   `yaml:"required,omitempty"`
+	Deprecated  bool                   `yaml:"deprecated,omitempty"`
+	Style       string                 `yaml:"style,omitempty"`
+	Explode     *bool                  `yaml:"explode,omitempty"`
+	Schema      *Schema                `yaml:"schema,omitempty"`
+	Example     interface{}            `yaml:"example,omitempty"`
+	Extra       map[string]interface{} `yaml:",inline"`
+}
+
+// RequestBody represents an operation request body
+type RequestBody struct {
+	Ref         string                 `yaml:"$ref,omitempty"`
+	Description string                 `yaml:"description,omitempty"`
+	Required    bool                   `yaml:"required,omitempty"`
+	Content     map[string]*MediaType  `yaml:"content,omitempty"`
+	Extra       map[string]interface{} `yaml:",inline"`
+}
+
+// Response represents a single operation response
+type Response struct {
+	Ref         string                 `yaml:"$ref,omitempty"`
+	Description string                 `yaml:"description,omitempty"`
+	Headers     map[string]*Header     `yaml:"headers,omitempty"`
+	Content     map[string]*MediaType  `yaml:"content,omitempty"`
+	Extra       map[string]interface{} `yaml:",inline"`
+}
+
+// Header represents a response header
+type Header struct {
+	Description string                 `yaml:"description,omitempty"`
+	Schema      *Schema                `yaml:"schema,omitempty"`
+	Extra       map[string]interface{} `yaml:",inline"`
+}
+
+// MediaType represents the payload of a request or response for a content type
+type MediaType struct {
+	Schema   *Schema                `yaml:"schema,omitempty"`
+	Example  interface{}            `yaml:"example,omitempty"`
+	Examples map[string]*Example    `yaml:"examples,omitempty"`
+	Extra    map[string]interface{} `yaml:",inline"`
+}
+
+// Example represents a named example
+type Example struct {
+	Summary string                 `yaml:"summary,omitempty"`
+	Value   interface{}            `yaml:"value,omitempty"`
+	Extra   map[string]interface{} `yaml:",inline"`
+}
+
+// Schema represents a JSON schema as used by OpenAPI 3.0
+type Schema struct {
+	Ref                  string                 `yaml:"$ref,omitempty"`
+	Title                string                 `yaml:"title,omitempty"`
+	Type                 string                 `yaml:"type,omitempty"`
+	Format               string                 `yaml:"format,omitempty"`
+	Description          string                 `yaml:"description,omitempty"`
+	Enum                 []interface{}          `yaml:"enum,omitempty"`
+	Default              interface{}            `yaml:"default,omitempty"`
+	Example              interface{}            `yaml:"example,omitempty"`
+	Nullable             bool                   `yaml:"nullable,omitempty"`
+	Deprecated           bool                   `yaml:"deprecated,omitempty"`
+	ReadOnly             bool                   `yaml:"readOnly,omitempty"`
+	WriteOnly            bool                   `yaml:"writeOnly,omitempty"`
+	Pattern              string                 `yaml:"pattern,omitempty"`
+	MinLength            *int                   `yaml:"minLength,omitempty"`
+	MaxLength            *int                   `yaml:"maxLength,omitempty"`
+	Minimum              *float64               `yaml:"minimum,omitempty"`
+	Maximum              *float64               `yaml:"maximum,omitempty"`
+	MinItems             *int                   `yaml:"minItems,omitempty"`
+	MaxItems             *int                   `yaml:"maxItems,omitempty"`
+	Required             []string               `yaml:"required,omitempty"`
+	Properties           map[string]*Schema     `yaml:"properties,omitempty"`
+	Items                *Schema                `yaml:"items,omitempty"`
+	AdditionalProperties *AdditionalProperties  `yaml:"additionalProperties,omitempty"`
+	AllOf                []*Schema              `yaml:"allOf,omitempty"`
+	OneOf                []*Schema              `yaml:"oneOf,omitempty"`
+	AnyOf                []*Schema              `yaml:"anyOf,omitempty"`
+	Discriminator        *Discriminator         `yaml:"discriminator,omitempty"`
+	Extra                map[string]interface{} `yaml:",inline"`
+}
+
+// AdditionalProperties is either a boolean or a schema
+type AdditionalProperties struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+// UnmarshalYAML accepts both the boolean and the schema form
+func (a *AdditionalProperties) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&a.Allowed)
+	}
+	a.Allowed = true
+	a.Schema = &Schema{}
+	return node.Decode(a.Schema)
+}
+
+// MarshalYAML writes the boolean form unless a schema is set
+func (a *AdditionalProperties) MarshalYAML() (interface{}, error) {
+	if a.Schema != nil {
+		return a.Schema, nil
+	}
+	return a.Allowed, nil
+}
+
+// Discriminator describes how oneOf/anyOf alternatives are told apart
+type Discriminator struct {
+	PropertyName string            `yaml:"propertyName,omitempty"`
+	Mapping      map[string]string `yaml:"mapping,omitempty"`
+}
+
+// UnmarshalYAML also accepts the Swagger 2.0 form, a bare property name
+func (d *Discriminator) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&d.PropertyName)
+	}
+	type plain Discriminator
+	return node.Decode((*plain)(d))
+}
+
+// SecurityScheme represents an entry of components.securitySchemes
+type SecurityScheme struct {
+	Type             string                 `yaml:"type,omitempty"`
+	Description      string                 `yaml:"description,omitempty"`
+	Name             string                 `yaml:"name,omitempty"`
+	In               string                 `yaml:"in,omitempty"`
+	Scheme           string                 `yaml:"scheme,omitempty"`
+	BearerFormat     string                 `yaml:"bearerFormat,omitempty"`
+	Flows            *OAuthFlows            `yaml:"flows,omitempty"`
+	OpenIDConnectURL string                 `yaml:"openIdConnectUrl,omitempty"`
+	Extra            map[string]interface{} `yaml:",inline"`
+}
+
+// OAuthFlows holds the supported OAuth2 flows
+type OAuthFlows struct {
+	Implicit          *OAuthFlow `yaml:"implicit,omitempty"`
+	Password          *OAuthFlow `yaml:"password,omitempty"`
+	ClientCredentials *OAuthFlow `yaml:"clientCredentials,omitempty"`
+	AuthorizationCode *OAuthFlow `yaml:"authorizationCode,omitempty"`
+}
+
+// OAuthFlow represents a single OAuth2 flow
+type OAuthFlow struct {
+	AuthorizationURL string            `yaml:"authorizationUrl,omitempty"`
+	TokenURL         string            `yaml:"tokenUrl,omitempty"`
+	RefreshURL       string            `yaml:"refreshUrl,omitempty"`
+	Scopes           map[string]string `yaml:"scopes,omitempty"`
+}
+
+// SecurityRequirement maps security scheme names to required scopes
+type SecurityRequirement map[string][]string
+
+// OperationRef identifies an operation together with its path and method
+type OperationRef struct {
+	Path      string
+	Method    string
+	PathItem  *PathItem
+	Operation *Operation
+}
+
+// Methods lists the HTTP methods of a path item in canonical order
+var Methods = []string{"GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH", "TRACE"}
+
+// Parse parses an OpenAPI document from YAML (or JSON) content. Swagger 2.0
+// documents are converted to the OpenAPI 3 model.
+func Parse(content []byte) (*Document, error) {
+	node, err := decodeNode(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	var header struct {
+		OpenAPI string `yaml:"openapi"`
+		Swagger string `yaml:"swagger"`
+	}
+	if err := node.Decode(&header); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	switch {
+	case header.OpenAPI != "":
+		var doc Document
+		if err := node.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+		}
+		return &doc, nil
+	case header.Swagger != "":
+		var spec swagger2
+		if err := node.Decode(&spec); err != nil {
+			return nil, fmt.Errorf("failed to parse Swagger document: %w", err)
+		}
+		return spec.convert(), nil
+	}
+	return nil, ErrNotOpenAPI
+}
+
+// Marshal renders a document as OpenAPI 3 YAML
+func Marshal(doc *Document) ([]byte, error) {
+	return yaml.Marshal(doc)
+}
+
+// Operation returns the operation for the given HTTP method, if any
+func (p *PathItem) Operation(method string) *Operation {
+	switch strings.ToUpper(method) {
+	case "GET":
+		return p.Get
+	case "PUT":
+		return p.Put
+	case "POST":
+		return p.Post
+	case "DELETE":
+		return p.Delete
+	case "OPTIONS":
+		return p.Options
+	case "HEAD":
+		return p.Head
+	case "PATCH":
+		return p.Patch
+	case "TRACE":
+		return p.Trace
+	}
+	return nil
+}
+
+// Operations returns every operation in the document sorted by path and method
+func (d *Document) Operations() []OperationRef {
+	paths := make([]string, 0, len(d.Paths))
+	for path := range d.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var ops []OperationRef
+	for _, path := range paths {
+		item := d.Paths[path]
+		if item == nil {
+			continue
+		}
+		for _, method := range Methods {
+			if op := item.Operation(method); op != nil {
+				ops = append(ops, OperationRef{Path: path, Method: method, PathItem: item, Operation: op})
+			}
+		}
+	}
+	return ops
+}
+
+// FindOperation looks up an operation by exact path and method
+func (d *Document) FindOperation(path, method string) (*OperationRef, error) {
+	item, ok := d.Paths[path]
+	if !ok || item == nil {
+		return nil, fmt.Errorf("path %s not found", path)
+	}
+	op := item.Operation(method)
+	if op == nil {
+		return nil, fmt.Errorf("method %s not defined for path %s", strings.ToUpper(method), path)
+	}
+	return &OperationRef{Path: path, Method: strings.ToUpper(method), PathItem: item, Operation: op}, nil
+}
+
+// SelectOperations resolves operation selectors, each either an operationId
+// or "METHOD /path". An empty selector list selects every operation.
+func (d *Document) SelectOperations(selectors []string) ([]OperationRef, error) {
+	all := d.Operations()
+	if len(selectors) == 0 {
+		return all, nil
+	}
+
+	var selected []OperationRef
+	for _, selector := range selectors {
+		selector = strings.TrimSpace(selector)
+		found := false
+		for _, op := range all {
+			if op.Operation.OperationID == selector ||
+				strings.EqualFold(op.Method+" "+op.Path, selector) {
+				selected = append(selected, op)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("operation %q not found", selector)
+		}
+	}
+	return selected, nil
+}
+
+// Parameters returns the resolved path-level and operation-level parameters,
+// with operation-level parameters overriding path-level ones
+func (d *Document) Parameters(ref OperationRef) []*Parameter {
+	var params []*Parameter
+	index := make(map[string]int)
+
+	add := func(p *Parameter) {
+		p = d.ResolveParameter(p)
+		if p == nil {
+			return
+		}
+		id := p.In + ":" + p.Name
+		if i, ok := index[id]; ok {
+			params[i] = p
+			return
+		}
+		index[id] = len(params)
+		params = append(params, p)
+	}
+
+	if ref.PathItem != nil {
+		for _, p := range ref.PathItem.Parameters {
+			add(p)
+		}
+	}
+	for _, p := range ref.Operation.Parameters {
+		add(p)
+	}
+	return params
+}
+
+// EffectiveSecurity returns the security requirements that apply to an
+// operation: its own list when declared (even if empty), else the global one
+func (d *Document) EffectiveSecurity(op *Operation) []SecurityRequirement {
+	if op.Security != nil {
+		return *op.Security
+	}
+	return d.Security
+}
+
+// ResolveSchema follows local $ref pointers until a concrete schema is found
+func (d *Document) ResolveSchema(s *Schema) *Schema {
+	for depth := 0; s != nil && s.Ref != "" && depth < 32; depth++ {
+		s = d.Components.Schemas[refName(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+// ResolveParameter follows a local $ref to a component parameter
+func (d *Document) ResolveParameter(p *Parameter) *Parameter {
+	for depth := 0; p != nil && p.Ref != "" && depth < 32; depth++ {
+		p = d.Components.Parameters[refName(p.Ref, "#/components/parameters/")]
+	}
+	return p
+}
+
+// ResolveRequestBody follows a local $ref to a component request body
+func (d *Document) ResolveRequestBody(b *RequestBody) *RequestBody {
+	for depth := 0; b != nil && b.Ref != "" && depth < 32; depth++ {
+		b = d.Components.RequestBodies[refName(b.Ref, "#/components/requestBodies/")]
+	}
+	return b
+}
+
+// ResolveResponse follows a local $ref to a component response
+func (d *Document) ResolveResponse(r *Response) *Response {
+	for depth := 0; r != nil && r.Ref != "" && depth < 32; depth++ {
+		r = d.Components.Responses[refName(r.Ref, "#/components/responses/")]
+	}
+	return r
+}
+
+// RefName returns the component name a $ref points to
+func RefName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// JSONMediaType picks the JSON-like media type from a content map, falling
+// back to the first entry in sorted order
+func JSONMediaType(content map[string]*MediaType) (string, *MediaType) {
+	if len(content) == 0 {
+		return "", nil
+	}
+	if mt, ok := content["application/json"]; ok {
+		return "application/json", mt
+	}
+	keys := make([]string, 0, len(content))
+	for k := range content {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if strings.Contains(k, "json") {
+			return k, content[k]
+		}
+	}
+	return keys[0], content[keys[0]]
+}
+
+// refName strips a local reference prefix
+func refName(ref, prefix string) string {
+	if !strings.HasPrefix(ref, prefix) {
+		return ""
+	}
+	return strings.TrimPrefix(ref, prefix)
+}
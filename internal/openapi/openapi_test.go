@@ -0,0 +1,90 @@
+package openapi
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMarshalKeepsUnmodeledFields(t *testing.T) {
+	doc := parse(t, `
+openapi: 3.0.3
+info:
+  title: Cards
+  version: '1'
+  contact: {email: cards@example.com}
+externalDocs: {url: https://docs.example.com}
+paths:
+  /cards:
+    description: Card collection
+    get:
+      parameters:
+        - {name: ids, in: query, style: form, explode: false, schema: {type: array, items: {type: string}}}
+      responses:
+        '200':
+          description: OK
+          headers:
+            X-Rate-Limit: {$ref: '#/components/headers/RateLimit'}
+          links:
+            GetCard: {$ref: '#/components/links/GetCard'}
+          content:
+            application/json:
+              examples:
+                list: {$ref: '#/components/examples/CardList'}
+components:
+  examples:
+    CardList: {value: [{id: c1}]}
+  headers:
+    RateLimit: {schema: {type: integer}}
+  links:
+    GetCard: {operationId: getCard}
+`)
+
+	data, err := Marshal(doc)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var got struct {
+		Info         map[string]interface{} `yaml:"info"`
+		ExternalDocs interface{}            `yaml:"externalDocs"`
+		Paths        map[string]struct {
+			Description string `yaml:"description"`
+			Get         struct {
+				Parameters []map[string]interface{} `yaml:"parameters"`
+				Responses  map[string]struct {
+					Headers map[string]map[string]string `yaml:"headers"`
+					Links   map[string]map[string]string `yaml:"links"`
+					Content map[string]struct {
+						Examples map[string]map[string]string `yaml:"examples"`
+					} `yaml:"content"`
+				} `yaml:"responses"`
+			} `yaml:"get"`
+		} `yaml:"paths"`
+		Components map[string]interface{} `yaml:"components"`
+	}
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v\n%s", err, data)
+	}
+
+	if got.Info["contact"] == nil || got.ExternalDocs == nil {
+		t.Errorf("metadata lost:\n%s", data)
+	}
+	for _, section := range []string{"examples", "headers", "links"} {
+		if got.Components[section] == nil {
+			t.Errorf("components.%s lost:\n%s", section, data)
+		}
+	}
+	cards := got.Paths["/cards"]
+	if cards.Description != "Card collection" {
+		t.Errorf("path description lost:\n%s", data)
+	}
+	if p := cards.Get.Parameters[0]; p["style"] != "form" || p["explode"] != false {
+		t.Errorf("parameter = %v", p)
+	}
+	ok := cards.Get.Responses["200"]
+	if ok.Headers["X-Rate-Limit"]["$ref"] != "#/components/headers/RateLimit" ||
+		ok.Links["GetCard"]["$ref"] != "#/components/links/GetCard" ||
+		ok.Content["application/json"].Examples["list"]["$ref"] != "#/components/examples/CardList" {
+		t.Errorf("references lost:\n%s", data)
+	}
+}
@@ -0,0 +1,150 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Where an operation's effective security requirements are declared
+const (
+	SecurityFromOperation = "operation"
+	SecurityFromGlobal    = "global"
+	SecurityNone          = "none"
+)
+
+// Auth is the resolved security of an operation. The client must satisfy
+// one of the alternatives; within an alternative every scheme is required.
+type Auth struct {
+	Source       string
+	Alternatives []AuthAlternative
+}
+
+// AuthAlternative is one security requirement object. An empty alternative
+// means anonymous access is allowed.
+type AuthAlternative struct {
+	Schemes []AuthScheme
+}
+
+// AuthScheme is a security scheme together with the scopes it must grant
+type AuthScheme struct {
+	Name   string
+	Scheme *SecurityScheme
+	Scopes []string
+}
+
+// ResolveAuth resolves the security requirements of an operation against
+// components.securitySchemes
+func (d *Document) ResolveAuth(op *Operation) Auth {
+	auth := Auth{Source: SecurityFromGlobal}
+	if op.Security != nil {
+		auth.Source = SecurityFromOperation
+	}
+
+	// An empty operation-level list still counts as an override
+	requirements := d.EffectiveSecurity(op)
+	if len(requirements) == 0 && op.Security == nil {
+		auth.Source = SecurityNone
+		return auth
+	}
+
+	for _, req := range requirements {
+		names := make([]string, 0, len(req))
+		for name := range req {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var alt AuthAlternative
+		for _, name := range names {
+			alt.Schemes = append(alt.Schemes, AuthScheme{
+				Name:   name,
+				Scheme: d.Components.SecuritySchemes[name],
+				Scopes: req[name],
+			})
+		}
+		auth.Alternatives = append(auth.Alternatives, alt)
+	}
+	return auth
+}
+
+// Describe explains how to present credentials for the scheme, e.g.
+// "API key in header X-API-Key" or "HTTP bearer token (JWT)"
+func (a AuthScheme) Describe() string {
+	s := a.Scheme
+	if s == nil {
+		return fmt.Sprintf("undefined security scheme '%s'", a.Name)
+	}
+
+	switch s.Type {
+	case "apiKey":
+		return fmt.Sprintf("API key in %s `%s`", s.In, s.Name)
+	case "http":
+		switch strings.ToLower(s.Scheme) {
+		case "bearer":
+			if s.BearerFormat != "" {
+				return fmt.Sprintf("HTTP bearer token (%s) in header `Authorization`", s.BearerFormat)
+			}
+			return "HTTP bearer token in header `Authorization`"
+		case "basic":
+			return "HTTP basic credentials in header `Authorization`"
+		}
+		return fmt.Sprintf("HTTP %s authentication in header `Authorization`", s.Scheme)
+	case "oauth2":
+		return "OAuth2 access token in header `Authorization: Bearer`"
+	case "openIdConnect":
+		if s.OpenIDConnectURL != "" {
+			return fmt.Sprintf("OpenID Connect token (discovery: %s)", s.OpenIDConnectURL)
+		}
+		return "OpenID Connect token"
+	case "mutualTLS":
+		return "mutual TLS client certificate"
+	}
+	return s.Type
+}
+
+// Flows lists the OAuth2 flows of the scheme with their endpoints
+func (a AuthScheme) Flows() []string {
+	if a.Scheme == nil || a.Scheme.Flows == nil {
+		return nil
+	}
+
+	flows := a.Scheme.Flows
+	var out []string
+	add := func(name string, flow *OAuthFlow) {
+		if flow == nil {
+			return
+		}
+		var urls []string
+		if flow.AuthorizationURL != "" {
+			urls = append(urls, "authorize "+flow.AuthorizationURL)
+		}
+		if flow.TokenURL != "" {
+			urls = append(urls, "token "+flow.TokenURL)
+		}
+		if flow.RefreshURL != "" {
+			urls = append(urls, "refresh "+flow.RefreshURL)
+		}
+		out = append(out, fmt.Sprintf("%s (%s)", name, strings.Join(urls, ", ")))
+	}
+	add("authorizationCode", flows.AuthorizationCode)
+	add("clientCredentials", flows.ClientCredentials)
+	add("password", flows.Password)
+	add("implicit", flows.Implicit)
+	return out
+}
+
+// ScopeDescription returns the description of a scope from the scheme's
+// OAuth2 flows
+func (a AuthScheme) ScopeDescription(scope string) string {
+	if a.Scheme == nil || a.Scheme.Flows == nil {
+		return ""
+	}
+	flows := a.Scheme.Flows
+	for _, flow := range []*OAuthFlow{flows.AuthorizationCode, flows.ClientCredentials, flows.Password, flows.Implicit} {
+		if flow != nil && flow.Scopes[scope] != "" {
+			return flow.Scopes[scope]
+		}
+	}
+	return ""
+}
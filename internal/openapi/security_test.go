@@ -0,0 +1,153 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const securitySpec = `
+openapi: 3.0.3
+info: {title: Cards, version: '1'}
+security:
+  - apiKey: []
+paths:
+  /cards:
+    get:
+      responses:
+        '200': {description: OK}
+    post:
+      security:
+        - oauth: [cards:write, cards:read]
+          apiKey: []
+        - {}
+      responses:
+        '201': {description: Created}
+  /health:
+    get:
+      security: []
+      responses:
+        '200': {description: OK}
+  /legacy:
+    get:
+      security:
+        - missing: []
+      responses:
+        '200': {description: OK}
+components:
+  securitySchemes:
+    apiKey: {type: apiKey, in: header, name: X-API-Key}
+    oauth:
+      type: oauth2
+      flows:
+        clientCredentials:
+          tokenUrl: https://auth.example.com/token
+          scopes: {cards:write: Modify cards, cards:read: Read cards}
+        authorizationCode:
+          authorizationUrl: https://auth.example.com/authorize
+          tokenUrl: https://auth.example.com/token
+          refreshUrl: https://auth.example.com/refresh
+          scopes: {}
+`
+
+// parse parses an inline test spec
+func parse(t *testing.T, spec string) *Document {
+	t.Helper()
+	doc, err := Parse([]byte(strings.TrimLeft(spec, "\n")))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return doc
+}
+
+// schemeNames lists the scheme names of each alternative
+func schemeNames(auth Auth) [][]string {
+	var names [][]string
+	for _, alt := range auth.Alternatives {
+		group := []string{}
+		for _, scheme := range alt.Schemes {
+			group = append(group, scheme.Name)
+		}
+		names = append(names, group)
+	}
+	return names
+}
+
+func TestResolveAuth(t *testing.T) {
+	doc := parse(t, securitySpec)
+
+	tests := []struct {
+		path, method string
+		source       string
+		want         [][]string
+	}{
+		{path: "/cards", method: "get", source: SecurityFromGlobal, want: [][]string{{"apiKey"}}},
+		{path: "/cards", method: "post", source: SecurityFromOperation, want: [][]string{{"apiKey", "oauth"}, {}}},
+		{path: "/health", method: "get", source: SecurityFromOperation},
+		{path: "/legacy", method: "get", source: SecurityFromOperation, want: [][]string{{"missing"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			auth := doc.ResolveAuth(operation(t, doc, tt.path, tt.method))
+			if auth.Source != tt.source {
+				t.Errorf("source = %s, want %s", auth.Source, tt.source)
+			}
+			if got := schemeNames(auth); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("alternatives = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	post := doc.ResolveAuth(operation(t, doc, "/cards", "post"))
+	if scopes := post.Alternatives[0].Schemes[1].Scopes; !reflect.DeepEqual(scopes, []string{"cards:write", "cards:read"}) {
+		t.Errorf("scopes = %v", scopes)
+	}
+
+	unsecured := parse(t, "openapi: 3.0.3\npaths:\n  /x:\n    get:\n      responses:\n        '200': {description: OK}\n")
+	if auth := unsecured.ResolveAuth(operation(t, unsecured, "/x", "get")); auth.Source != SecurityNone || auth.Alternatives != nil {
+		t.Errorf("unsecured auth = %+v", auth)
+	}
+}
+
+func TestAuthSchemeDescribe(t *testing.T) {
+	tests := []struct {
+		scheme *SecurityScheme
+		want   string
+	}{
+		{scheme: &SecurityScheme{Type: "apiKey", In: "query", Name: "key"}, want: "API key in query `key`"},
+		{scheme: &SecurityScheme{Type: "http", Scheme: "Bearer", BearerFormat: "JWT"}, want: "HTTP bearer token (JWT) in header `Authorization`"},
+		{scheme: &SecurityScheme{Type: "http", Scheme: "basic"}, want: "HTTP basic credentials in header `Authorization`"},
+		{scheme: &SecurityScheme{Type: "http", Scheme: "digest"}, want: "HTTP digest authentication in header `Authorization`"},
+		{scheme: &SecurityScheme{Type: "oauth2"}, want: "OAuth2 access token in header `Authorization: Bearer`"},
+		{scheme: &SecurityScheme{Type: "openIdConnect", OpenIDConnectURL: "https://id.example.com"}, want: "OpenID Connect token (discovery: https://id.example.com)"},
+		{scheme: &SecurityScheme{Type: "mutualTLS"}, want: "mutual TLS client certificate"},
+		{want: "undefined security scheme 'test'"},
+	}
+	for _, tt := range tests {
+		if got := (AuthScheme{Name: "test", Scheme: tt.scheme}).Describe(); got != tt.want {
+			t.Errorf("Describe() = %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestAuthSchemeFlows(t *testing.T) {
+	doc := parse(t, securitySpec)
+	oauth := AuthScheme{Name: "oauth", Scheme: doc.Components.SecuritySchemes["oauth"]}
+
+	want := []string{
+		"authorizationCode (authorize https://auth.example.com/authorize, token https://auth.example.com/token, refresh https://auth.example.com/refresh)",
+		"clientCredentials (token https://auth.example.com/token)",
+	}
+	if got := oauth.Flows(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Flows() = %v, want %v", got, want)
+	}
+	if got := oauth.ScopeDescription("cards:read"); got != "Read cards" {
+		t.Errorf("ScopeDescription = %q", got)
+	}
+	if got := oauth.ScopeDescription("admin"); got != "" {
+		t.Errorf("ScopeDescription of an undeclared scope = %q", got)
+	}
+	if flows := (AuthScheme{Name: "missing"}).Flows(); flows != nil {
+		t.Errorf("Flows() of an undefined scheme = %v", flows)
+	}
+}
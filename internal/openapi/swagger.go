@@ -0,0 +1,499 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+)
+
+// swagger2 is the subset of a Swagger 2.0 document needed for conversion
+type swagger2 struct {
+	Swagger             string                            `yaml:"swagger"`
+	Info                Info                              `yaml:"info"`
+	Host                string                            `yaml:"host"`
+	BasePath            string                            `yaml:"basePath"`
+	Schemes             []string                          `yaml:"schemes"`
+	Consumes            []string                          `yaml:"consumes"`
+	Produces            []string                          `yaml:"produces"`
+	Paths               map[string]*swaggerPathItem       `yaml:"paths"`
+	Definitions         map[string]*Schema                `yaml:"definitions"`
+	Parameters          map[string]*swaggerParameter      `yaml:"parameters"`
+	Responses           map[string]*swaggerResponse       `yaml:"responses"`
+	SecurityDefinitions map[string]*swaggerSecurityScheme `yaml:"securityDefinitions"`
+	Security            []SecurityRequirement             `yaml:"security"`
+	Tags                []Tag                             `yaml:"tags"`
+	Extra               map[string]interface{}            `yaml:",inline"`
+}
+
+type swaggerPathItem struct {
+	Parameters []*swaggerParameter `yaml:"parameters"`
+	Get        *swaggerOperation   `yaml:"get"`
+	Put        *swaggerOperation   `yaml:"put"`
+	Post       *swaggerOperation   `yaml:"post"`
+	Delete     *swaggerOperation   `yaml:"delete"`
+	Options    *swaggerOperation   `yaml:"options"`
+	Head       *swaggerOperation   `yaml:"head"`
+	Patch      *swaggerOperation   `yaml:"patch"`
+}
+
+type swaggerOperation struct {
+	OperationID string                      `yaml:"operationId"`
+	Summary     string                      `yaml:"summary"`
+	Description string                      `yaml:"description"`
+	Tags        []string                    `yaml:"tags"`
+	Consumes    []string                    `yaml:"consumes"`
+	Produces    []string                    `yaml:"produces"`
+	Parameters  []*swaggerParameter         `yaml:"parameters"`
+	Responses   map[string]*swaggerResponse `yaml:"responses"`
+	Security    *[]SecurityRequirement      `yaml:"security"`
+	Deprecated  bool                        `yaml:"deprecated"`
+	Extra       map[string]interface{}      `yaml:",inline"`
+}
+
+type swaggerParameter struct {
+	Ref              string                 `yaml:"$ref"`
+	Name             string                 `yaml:"name"`
+	In               string                 `yaml:"in"`
+	Description      string                 `yaml:"description"`
+	Required         bool                   `yaml:"required"`
+	Schema           *Schema                `yaml:"schema"`
+	Type             string                 `yaml:"type"`
+	Format           string                 `yaml:"format"`
+	Items            *Schema                `yaml:"items"`
+	CollectionFormat string                 `yaml:"collectionFormat"`
+	Enum             []interface{}          `yaml:"enum"`
+	Default          interface{}            `yaml:"default"`
+	Pattern          string                 `yaml:"pattern"`
+	MinLength        *int                   `yaml:"minLength"`
+	MaxLength        *int                   `yaml:"maxLength"`
+	Minimum          *float64               `yaml:"minimum"`
+	Maximum          *float64               `yaml:"maximum"`
+	Example          interface{}            `yaml:"x-example"`
+	Extra            map[string]interface{} `yaml:",inline"`
+}
+
+type swaggerResponse struct {
+	Ref         string                    `yaml:"$ref"`
+	Description string                    `yaml:"description"`
+	Schema      *Schema                   `yaml:"schema"`
+	Headers     map[string]*swaggerHeader `yaml:"headers"`
+	Examples    map[string]interface{}    `yaml:"examples"`
+}
+
+type swaggerHeader struct {
+	Description string        `yaml:"description"`
+	Type        string        `yaml:"type"`
+	Format      string        `yaml:"format"`
+	Items       *Schema       `yaml:"items"`
+	Enum        []interface{} `yaml:"enum"`
+	Default     interface{}   `yaml:"default"`
+}
+
+type swaggerSecurityScheme struct {
+	Type             string            `yaml:"type"`
+	Description      string            `yaml:"description"`
+	Name             string            `yaml:"name"`
+	In               string            `yaml:"in"`
+	Flow             string            `yaml:"flow"`
+	AuthorizationURL string            `yaml:"authorizationUrl"`
+	TokenURL         string            `yaml:"tokenUrl"`
+	Scopes           map[string]string `yaml:"scopes"`
+}
+
+// convert builds the equivalent OpenAPI 3 document
+func (sw *swagger2) convert() *Document {
+	doc := &Document{
+		OpenAPI:       "3.0.3",
+		ConvertedFrom: sw.Swagger,
+		Info:          sw.Info,
+		Security:      sw.Security,
+		Tags:          sw.Tags,
+		Paths:         make(map[string]*PathItem),
+		Extra:         extensions(sw.Extra),
+	}
+
+	doc.Servers = sw.servers()
+
+	doc.Components.Schemas = make(map[string]*Schema)
+	for name, schema := range sw.Definitions {
+		doc.Components.Schemas[name] = convertSchema(schema)
+	}
+
+	// Global body parameters become request bodies; the rest stay parameters
+	for name, p := range sw.Parameters {
+		if p.In == "body" || p.In == "formData" {
+			if doc.Components.RequestBodies == nil {
+				doc.Components.RequestBodies = make(map[string]*RequestBody)
+			}
+			doc.Components.RequestBodies[name] = sw.requestBody([]*swaggerParameter{p}, sw.Consumes)
+			continue
+		}
+		if doc.Components.Parameters == nil {
+			doc.Components.Parameters = make(map[string]*Parameter)
+		}
+		doc.Components.Parameters[name] = convertParameter(p)
+	}
+
+	for name, r := range sw.Responses {
+		if doc.Components.Responses == nil {
+			doc.Components.Responses = make(map[string]*Response)
+		}
+		doc.Components.Responses[name] = convertResponse(r, sw.Produces)
+	}
+
+	for name, scheme := range sw.SecurityDefinitions {
+		if doc.Components.SecuritySchemes == nil {
+			doc.Components.SecuritySchemes = make(map[string]*SecurityScheme)
+		}
+		doc.Components.SecuritySchemes[name] = convertSecurityScheme(scheme)
+	}
+
+	for path, item := range sw.Paths {
+		if item == nil {
+			continue
+		}
+		converted := &PathItem{}
+		converted.Parameters, _ = sw.parameters(item.Parameters)
+		operation := func(op *swaggerOperation) *Operation {
+			if op == nil {
+				return nil
+			}
+			return sw.operation(op, item.Parameters)
+		}
+		converted.Get = operation(item.Get)
+		converted.Put = operation(item.Put)
+		converted.Post = operation(item.Post)
+		converted.Delete = operation(item.Delete)
+		converted.Options = operation(item.Options)
+		converted.Head = operation(item.Head)
+		converted.Patch = operation(item.Patch)
+		doc.Paths[path] = converted
+	}
+
+	return doc
+}
+
+// servers combines schemes, host and basePath into server entries
+func (sw *swagger2) servers() []Server {
+	if sw.Host == "" && sw.BasePath == "" {
+		return nil
+	}
+	if sw.Host == "" {
+		return []Server{{URL: sw.BasePath}}
+	}
+	schemes := sw.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"https"}
+	}
+	var servers []Server
+	for _, scheme := range schemes {
+		servers = append(servers, Server{URL: scheme + "://" + sw.Host + sw.BasePath})
+	}
+	return servers
+}
+
+// operation converts a single operation, moving body and form parameters
+// into a request body
+func (sw *swagger2) operation(op *swaggerOperation, pathParams []*swaggerParameter) *Operation {
+	consumes := op.Consumes
+	if len(consumes) == 0 {
+		consumes = sw.Consumes
+	}
+	produces := op.Produces
+	if len(produces) == 0 {
+		produces = sw.Produces
+	}
+
+	converted := &Operation{
+		OperationID: op.OperationID,
+		Summary:     op.Summary,
+		Description: op.Description,
+		Tags:        op.Tags,
+		Security:    op.Security,
+		Deprecated:  op.Deprecated,
+		Extra:       extensions(op.Extra),
+	}
+
+	params, bodyParams := sw.parameters(op.Parameters)
+	converted.Parameters = params
+	if len(bodyParams) == 0 {
+		// A path-level body parameter applies to every operation
+		_, bodyParams = sw.parameters(pathParams)
+	}
+	if len(bodyParams) == 1 && bodyParams[0].Ref != "" {
+		converted.RequestBody = &RequestBody{Ref: "#/components/requestBodies/" + RefName(bodyParams[0].Ref)}
+	} else if len(bodyParams) > 0 {
+		converted.RequestBody = sw.requestBody(bodyParams, consumes)
+	}
+
+	if len(op.Responses) > 0 {
+		converted.Responses = make(map[string]*Response)
+		for code, r := range op.Responses {
+			converted.Responses[code] = convertResponse(r, produces)
+		}
+	}
+	return converted
+}
+
+// parameters converts non-body parameters and returns body and form
+// parameters separately
+func (sw *swagger2) parameters(params []*swaggerParameter) ([]*Parameter, []*swaggerParameter) {
+	var converted []*Parameter
+	var body []*swaggerParameter
+	for _, p := range params {
+		if p == nil {
+			continue
+		}
+		if p.Ref != "" {
+			target := sw.Parameters[RefName(p.Ref)]
+			if target != nil && (target.In == "body" || target.In == "formData") {
+				body = append(body, p)
+				continue
+			}
+			converted = append(converted, &Parameter{Ref: "#/components/parameters/" + RefName(p.Ref)})
+			continue
+		}
+		if p.In == "body" || p.In == "formData" {
+			body = append(body, p)
+			continue
+		}
+		converted = append(converted, convertParameter(p))
+	}
+	return converted, body
+}
+
+// requestBody builds a request body from a body parameter or a set of form
+// parameters
+func (sw *swagger2) requestBody(params []*swaggerParameter, consumes []string) *RequestBody {
+	body := &RequestBody{Content: make(map[string]*MediaType)}
+
+	var schema *Schema
+	var encoding map[string]interface{}
+	for _, p := range params {
+		if p.Ref != "" {
+			if target := sw.Parameters[RefName(p.Ref)]; target != nil {
+				p = target
+			}
+		}
+		if p.In == "body" {
+			schema = convertSchema(p.Schema)
+			body.Description = p.Description
+			body.Required = p.Required
+			break
+		}
+
+		// Form parameters are merged into a single object schema
+		if schema == nil {
+			schema = &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		}
+		schema.Properties[p.Name] = parameterSchema(p)
+		if p.Required {
+			schema.Required = append(schema.Required, p.Name)
+			body.Required = true
+		}
+		if style, explode := collectionStyle(p); style != "" {
+			if encoding == nil {
+				encoding = make(map[string]interface{})
+			}
+			encoding[p.Name] = map[string]interface{}{"style": style, "explode": *explode}
+		}
+	}
+
+	isForm := schema != nil && params[0].In == "formData"
+	if len(consumes) == 0 {
+		if isForm {
+			consumes = []string{"application/x-www-form-urlencoded"}
+		} else {
+			consumes = []string{"application/json"}
+		}
+	}
+	for _, contentType := range consumes {
+		mt := &MediaType{Schema: schema}
+		if encoding != nil {
+			mt.Extra = map[string]interface{}{"encoding": encoding}
+		}
+		body.Content[contentType] = mt
+	}
+	return body
+}
+
+// convertParameter converts a query, path, header or cookie parameter
+func convertParameter(p *swaggerParameter) *Parameter {
+	style, explode := collectionStyle(p)
+	return &Parameter{
+		Name:        p.Name,
+		In:          p.In,
+		Description: p.Description,
+		Required:    p.Required || p.In == "path",
+		Style:       style,
+		Explode:     explode,
+		Schema:      parameterSchema(p),
+		Example:     p.Example,
+		Extra:       extensions(p.Extra),
+	}
+}
+
+// collectionStyle maps the collectionFormat of an array parameter onto the
+// OpenAPI 3 style and explode. csv is the Swagger default; in paths and
+// headers it matches the OpenAPI 3 default, so no style is returned.
+func collectionStyle(p *swaggerParameter) (string, *bool) {
+	if p.Type != "array" {
+		return "", nil
+	}
+	explode := false
+	switch p.CollectionFormat {
+	case "", "csv":
+		if p.In == "path" || p.In == "header" {
+			return "", nil
+		}
+		return "form", &explode
+	case "multi":
+		explode = true
+		return "form", &explode
+	case "ssv":
+		return "spaceDelimited", &explode
+	case "pipes":
+		return "pipeDelimited", &explode
+	}
+	// tsv has no OpenAPI 3 equivalent
+	return "", nil
+}
+
+// extensions keeps the x- vendor extensions and externalDocs of a set of
+// unknown fields, dropping Swagger-only keywords
+func extensions(extra map[string]interface{}) map[string]interface{} {
+	var out map[string]interface{}
+	for k, v := range extra {
+		if !strings.HasPrefix(k, "x-") && k != "externalDocs" {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]interface{})
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// parameterSchema moves inline type information into a schema
+func parameterSchema(p *swaggerParameter) *Schema {
+	s := &Schema{
+		Type:      p.Type,
+		Format:    p.Format,
+		Items:     convertSchema(p.Items),
+		Enum:      p.Enum,
+		Default:   p.Default,
+		Pattern:   p.Pattern,
+		MinLength: p.MinLength,
+		MaxLength: p.MaxLength,
+		Minimum:   p.Minimum,
+		Maximum:   p.Maximum,
+	}
+	if s.Type == "file" {
+		s.Type, s.Format = "string", "binary"
+	}
+	return s
+}
+
+// convertResponse wraps a response schema in a content map
+func convertResponse(r *swaggerResponse, produces []string) *Response {
+	if r == nil {
+		return nil
+	}
+	if r.Ref != "" {
+		return &Response{Ref: "#/components/responses/" + RefName(r.Ref)}
+	}
+
+	converted := &Response{Description: r.Description}
+	if r.Schema != nil {
+		if len(produces) == 0 {
+			produces = []string{"application/json"}
+		}
+		converted.Content = make(map[string]*MediaType)
+		for _, contentType := range produces {
+			converted.Content[contentType] = &MediaType{Schema: convertSchema(r.Schema), Example: r.Examples[contentType]}
+		}
+	}
+	if len(r.Headers) > 0 {
+		converted.Headers = make(map[string]*Header)
+		for name, h := range r.Headers {
+			converted.Headers[name] = &Header{Description: h.Description, Schema: &Schema{
+				Type:    h.Type,
+				Format:  h.Format,
+				Items:   convertSchema(h.Items),
+				Enum:    h.Enum,
+				Default: h.Default,
+			}}
+		}
+	}
+	return converted
+}
+
+// convertSecurityScheme maps Swagger 2.0 security definitions
+func convertSecurityScheme(s *swaggerSecurityScheme) *SecurityScheme {
+	switch s.Type {
+	case "basic":
+		return &SecurityScheme{Type: "http", Scheme: "basic", Description: s.Description}
+	case "oauth2":
+		flow := &OAuthFlow{AuthorizationURL: s.AuthorizationURL, TokenURL: s.TokenURL, Scopes: s.Scopes}
+		if flow.Scopes == nil {
+			flow.Scopes = map[string]string{}
+		}
+		flows := &OAuthFlows{}
+		switch s.Flow {
+		case "implicit":
+			flows.Implicit = flow
+		case "password":
+			flows.Password = flow
+		case "application":
+			flows.ClientCredentials = flow
+		case "accessCode":
+			flows.AuthorizationCode = flow
+		}
+		return &SecurityScheme{Type: "oauth2", Description: s.Description, Flows: flows}
+	}
+	return &SecurityScheme{Type: s.Type, Description: s.Description, Name: s.Name, In: s.In}
+}
+
+// convertSchema rewrites definition references and vendor extensions of a
+// Swagger 2.0 schema in place
+func convertSchema(s *Schema) *Schema {
+	if s == nil {
+		return nil
+	}
+	if strings.HasPrefix(s.Ref, "#/definitions/") {
+		s.Ref = "#/components/schemas/" + strings.TrimPrefix(s.Ref, "#/definitions/")
+	}
+	if nullable, ok := s.Extra["x-nullable"].(bool); ok {
+		s.Nullable = nullable
+		delete(s.Extra, "x-nullable")
+	}
+	if s.Type == "file" {
+		s.Type, s.Format = "string", "binary"
+	}
+
+	s.Items = convertSchema(s.Items)
+	for _, list := range [][]*Schema{s.AllOf, s.OneOf, s.AnyOf} {
+		for _, sub := range list {
+			convertSchema(sub)
+		}
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		convertSchema(s.Properties[name])
+	}
+	if s.AdditionalProperties != nil {
+		convertSchema(s.AdditionalProperties.Schema)
+	}
+	if s.Discriminator != nil {
+		for value, ref := range s.Discriminator.Mapping {
+			if strings.HasPrefix(ref, "#/definitions/") {
+				s.Discriminator.Mapping[value] = "#/components/schemas/" + strings.TrimPrefix(ref, "#/definitions/")
+			}
+		}
+	}
+	return s
+}
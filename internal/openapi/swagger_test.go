@@ -0,0 +1,394 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const swaggerSpec = `
+swagger: '2.0'
+info: {title: Pets, version: '1'}
+host: api.example.com
+basePath: /v1
+schemes: [https, http]
+consumes: [application/json]
+produces: [application/json]
+security:
+  - key: []
+paths:
+  /pets/{id}:
+    parameters:
+      - {name: id, in: path, type: string}
+    get:
+      operationId: getPet
+      x-rate-limit: 10
+      parameters:
+        - {name: tags, in: query, type: array, items: {type: string}, collectionFormat: csv}
+        - {$ref: '#/parameters/Trace'}
+      responses:
+        '200':
+          description: OK
+          schema: {$ref: '#/definitions/Pet'}
+          headers:
+            X-Rate-Remaining: {type: integer, format: int32}
+        '404': {$ref: '#/responses/NotFound'}
+    put:
+      parameters:
+        - {$ref: '#/parameters/PetBody'}
+      responses:
+        '204': {description: Updated}
+  /pets/{id}/photo:
+    post:
+      consumes: [multipart/form-data]
+      parameters:
+        - {name: id, in: path, required: true, type: string}
+        - {name: caption, in: formData, type: string}
+        - {name: file, in: formData, required: true, type: file}
+      responses:
+        '201': {description: Uploaded}
+  /pets/{id}/notes:
+    post:
+      parameters:
+        - {name: id, in: path, required: true, type: string}
+        - {name: note, in: formData, type: string}
+      responses:
+        '201': {description: Noted}
+  /pets:
+    post:
+      security: []
+      parameters:
+        - {name: pet, in: body, required: true, description: The pet, schema: {$ref: '#/definitions/Pet'}}
+      responses:
+        '201': {description: Created}
+parameters:
+  Trace: {name: X-Trace, in: header, type: string}
+  PetBody: {name: pet, in: body, schema: {$ref: '#/definitions/Pet'}}
+responses:
+  NotFound:
+    description: Missing
+    schema: {$ref: '#/definitions/Error'}
+definitions:
+  Pet:
+    type: object
+    properties:
+      name: {type: string, x-nullable: true}
+      owner: {$ref: '#/definitions/Owner'}
+      kind:
+        type: string
+    discriminator: kind
+  Owner:
+    type: object
+  Error:
+    type: object
+securityDefinitions:
+  key: {type: apiKey, in: header, name: X-API-Key}
+  basic: {type: basic}
+  implicit: {type: oauth2, flow: implicit, authorizationUrl: https://auth.example.com/authorize, scopes: {read: Read pets}}
+  password: {type: oauth2, flow: password, tokenUrl: https://auth.example.com/token}
+  application: {type: oauth2, flow: application, tokenUrl: https://auth.example.com/token}
+  accessCode: {type: oauth2, flow: accessCode, authorizationUrl: https://auth.example.com/authorize, tokenUrl: https://auth.example.com/token}
+`
+
+func parseSwagger(t *testing.T) *Document {
+	t.Helper()
+	doc, err := Parse([]byte(strings.TrimLeft(swaggerSpec, "\n")))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return doc
+}
+
+func operation(t *testing.T, doc *Document, path, method string) *Operation {
+	t.Helper()
+	op, err := doc.FindOperation(path, method)
+	if err != nil {
+		t.Fatalf("FindOperation: %v", err)
+	}
+	return op.Operation
+}
+
+func TestSwaggerDocument(t *testing.T) {
+	doc := parseSwagger(t)
+
+	if doc.OpenAPI != "3.0.3" || doc.ConvertedFrom != "2.0" {
+		t.Errorf("version = %s converted from %s", doc.OpenAPI, doc.ConvertedFrom)
+	}
+	var urls []string
+	for _, server := range doc.Servers {
+		urls = append(urls, server.URL)
+	}
+	if want := []string{"https://api.example.com/v1", "http://api.example.com/v1"}; !reflect.DeepEqual(urls, want) {
+		t.Errorf("servers = %v, want %v", urls, want)
+	}
+	if len(doc.Security) != 1 || doc.Security[0]["key"] == nil {
+		t.Errorf("global security = %v", doc.Security)
+	}
+
+	pet := doc.Components.Schemas["Pet"]
+	if pet.Properties["owner"].Ref != "#/components/schemas/Owner" {
+		t.Errorf("definition ref = %s", pet.Properties["owner"].Ref)
+	}
+	if name := pet.Properties["name"]; !name.Nullable || name.Extra["x-nullable"] != nil {
+		t.Errorf("x-nullable not converted: %+v", name)
+	}
+	if pet.Discriminator == nil || pet.Discriminator.PropertyName != "kind" {
+		t.Errorf("discriminator = %+v", pet.Discriminator)
+	}
+	if doc.Components.Parameters["Trace"].In != "header" || doc.Components.RequestBodies["PetBody"] == nil {
+		t.Errorf("global parameters = %v, request bodies = %v", doc.Components.Parameters, doc.Components.RequestBodies)
+	}
+	if resp := doc.Components.Responses["NotFound"]; resp.Content["application/json"].Schema.Ref != "#/components/schemas/Error" {
+		t.Errorf("global response = %+v", resp)
+	}
+}
+
+func TestSwaggerOperations(t *testing.T) {
+	doc := parseSwagger(t)
+
+	t.Run("parameters", func(t *testing.T) {
+		get := operation(t, doc, "/pets/{id}", "GET")
+		params := doc.Parameters(OperationRef{Path: "/pets/{id}", Method: "GET", Operation: get, PathItem: doc.Paths["/pets/{id}"]})
+		got := make(map[string]*Parameter)
+		for _, p := range params {
+			got[p.Name] = p
+		}
+		if id := got["id"]; id == nil || !id.Required || id.Schema.Type != "string" {
+			t.Errorf("path-level id = %+v", id)
+		}
+		if tags := got["tags"]; tags == nil || tags.Schema.Type != "array" || tags.Schema.Items.Type != "string" || tags.Extra["collectionFormat"] != nil {
+			t.Errorf("tags = %+v", tags)
+		}
+		if got["X-Trace"] == nil {
+			t.Errorf("referenced parameter missing from %v", params)
+		}
+		if get.Extra["x-rate-limit"] != 10 {
+			t.Errorf("vendor extension lost: %v", get.Extra)
+		}
+	})
+
+	t.Run("responses", func(t *testing.T) {
+		get := operation(t, doc, "/pets/{id}", "GET")
+		ok := get.Responses["200"]
+		if ok.Content["application/json"].Schema.Ref != "#/components/schemas/Pet" {
+			t.Errorf("200 content = %+v", ok.Content)
+		}
+		if h := ok.Headers["X-Rate-Remaining"]; h == nil || h.Schema.Type != "integer" || h.Schema.Format != "int32" {
+			t.Errorf("response header = %+v", h)
+		}
+		if get.Responses["404"].Ref != "#/components/responses/NotFound" {
+			t.Errorf("404 = %+v", get.Responses["404"])
+		}
+	})
+
+	tests := []struct {
+		name        string
+		path        string
+		method      string
+		contentType string
+		ref         string
+		required    []string
+		properties  map[string]string // property name to type/format
+	}{
+		{name: "body parameter", path: "/pets", method: "POST", contentType: "application/json", ref: "#/components/schemas/Pet"},
+		{name: "referenced body parameter", path: "/pets/{id}", method: "PUT", ref: "#/components/requestBodies/PetBody"},
+		{
+			name: "multipart form", path: "/pets/{id}/photo", method: "POST", contentType: "multipart/form-data",
+			required:   []string{"file"},
+			properties: map[string]string{"caption": "string", "file": "string/binary"},
+		},
+		{
+			name: "form with global consumes", path: "/pets/{id}/notes", method: "POST", contentType: "application/json",
+			properties: map[string]string{"note": "string"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := operation(t, doc, tt.path, tt.method).RequestBody
+			if body == nil {
+				t.Fatal("no request body")
+			}
+			if tt.contentType == "" {
+				if body.Ref != tt.ref {
+					t.Errorf("request body ref = %s, want %s", body.Ref, tt.ref)
+				}
+				return
+			}
+			mt := body.Content[tt.contentType]
+			if mt == nil || len(body.Content) != 1 {
+				t.Fatalf("content = %v, want only %s", body.Content, tt.contentType)
+			}
+			if mt.Schema.Ref != tt.ref {
+				t.Errorf("schema ref = %s, want %s", mt.Schema.Ref, tt.ref)
+			}
+			if !reflect.DeepEqual(mt.Schema.Required, tt.required) {
+				t.Errorf("required = %v, want %v", mt.Schema.Required, tt.required)
+			}
+			for name, want := range tt.properties {
+				prop := mt.Schema.Properties[name]
+				got := prop.Type
+				if prop.Format != "" {
+					got += "/" + prop.Format
+				}
+				if got != want {
+					t.Errorf("property %s = %s, want %s", name, got, want)
+				}
+			}
+		})
+	}
+
+	if post := operation(t, doc, "/pets", "POST"); post.Security == nil || len(*post.Security) != 0 || !post.RequestBody.Required || post.RequestBody.Description != "The pet" {
+		t.Errorf("POST /pets = %+v", post)
+	}
+}
+
+func TestSwaggerSecurityDefinitions(t *testing.T) {
+	schemes := parseSwagger(t).Components.SecuritySchemes
+
+	tests := []struct {
+		name string
+		want SecurityScheme
+	}{
+		{name: "key", want: SecurityScheme{Type: "apiKey", In: "header", Name: "X-API-Key"}},
+		{name: "basic", want: SecurityScheme{Type: "http", Scheme: "basic"}},
+		{name: "implicit", want: SecurityScheme{Type: "oauth2", Flows: &OAuthFlows{
+			Implicit: &OAuthFlow{AuthorizationURL: "https://auth.example.com/authorize", Scopes: map[string]string{"read": "Read pets"}},
+		}}},
+		{name: "password", want: SecurityScheme{Type: "oauth2", Flows: &OAuthFlows{
+			Password: &OAuthFlow{TokenURL: "https://auth.example.com/token", Scopes: map[string]string{}},
+		}}},
+		{name: "application", want: SecurityScheme{Type: "oauth2", Flows: &OAuthFlows{
+			ClientCredentials: &OAuthFlow{TokenURL: "https://auth.example.com/token", Scopes: map[string]string{}},
+		}}},
+		{name: "accessCode", want: SecurityScheme{Type: "oauth2", Flows: &OAuthFlows{
+			AuthorizationCode: &OAuthFlow{AuthorizationURL: "https://auth.example.com/authorize", TokenURL: "https://auth.example.com/token", Scopes: map[string]string{}},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := schemes[tt.name]; got == nil || !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("scheme = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSwaggerCollectionFormat(t *testing.T) {
+	doc := parse(t, `
+swagger: '2.0'
+info: {title: Pets, version: '1'}
+paths:
+  /pets/{ids}:
+    post:
+      consumes: [application/x-www-form-urlencoded]
+      parameters:
+        - {name: ids, in: path, required: true, type: array, items: {type: string}}
+        - {name: default, in: query, type: array, items: {type: string}}
+        - {name: csv, in: query, type: array, items: {type: string}, collectionFormat: csv}
+        - {name: multi, in: query, type: array, items: {type: string}, collectionFormat: multi}
+        - {name: ssv, in: query, type: array, items: {type: string}, collectionFormat: ssv}
+        - {name: pipes, in: query, type: array, items: {type: string}, collectionFormat: pipes}
+        - {name: tsv, in: query, type: array, items: {type: string}, collectionFormat: tsv}
+        - {name: X-Ids, in: header, type: array, items: {type: string}, collectionFormat: csv}
+        - {name: scalar, in: query, type: string, collectionFormat: multi}
+        - {name: colors, in: formData, type: array, items: {type: string}, collectionFormat: multi}
+      responses:
+        '204': {description: OK}
+`)
+	op := operation(t, doc, "/pets/{ids}", "POST")
+
+	tests := []struct {
+		name    string
+		style   string
+		explode string
+	}{
+		{name: "ids"},
+		{name: "default", style: "form", explode: "false"},
+		{name: "csv", style: "form", explode: "false"},
+		{name: "multi", style: "form", explode: "true"},
+		{name: "ssv", style: "spaceDelimited", explode: "false"},
+		{name: "pipes", style: "pipeDelimited", explode: "false"},
+		{name: "tsv"},
+		{name: "X-Ids"},
+		{name: "scalar"},
+	}
+	params := make(map[string]*Parameter)
+	for _, p := range op.Parameters {
+		params[p.Name] = p
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := params[tt.name]
+			if p == nil {
+				t.Fatalf("parameter missing from %v", op.Parameters)
+			}
+			explode := ""
+			if p.Explode != nil {
+				explode = fmt.Sprint(*p.Explode)
+			}
+			if p.Style != tt.style || explode != tt.explode {
+				t.Errorf("style = %q, explode = %q, want %q, %q", p.Style, explode, tt.style, tt.explode)
+			}
+			if p.Extra["collectionFormat"] != nil {
+				t.Errorf("collectionFormat kept: %v", p.Extra)
+			}
+		})
+	}
+
+	// Form arrays are described by the media type's encoding
+	mt := op.RequestBody.Content["application/x-www-form-urlencoded"]
+	want := map[string]interface{}{"colors": map[string]interface{}{"style": "form", "explode": true}}
+	if mt == nil || !reflect.DeepEqual(mt.Extra["encoding"], want) {
+		t.Errorf("form media type = %+v", mt)
+	}
+}
+
+func TestSwaggerMetadata(t *testing.T) {
+	doc := parse(t, `
+swagger: '2.0'
+info:
+  title: Pets
+  version: '1'
+  termsOfService: https://example.com/terms
+  contact: {name: Pet team, email: pets@example.com}
+  license: {name: MIT}
+externalDocs: {url: https://docs.example.com}
+x-owner: pets
+tags:
+  - {name: pets, externalDocs: {url: https://docs.example.com/pets}}
+paths:
+  /pets:
+    get:
+      externalDocs: {url: https://docs.example.com/list}
+      produces: [application/json]
+      responses:
+        '200':
+          description: OK
+          headers:
+            X-Ids: {type: array, items: {type: integer, format: int64}, collectionFormat: csv}
+            X-Mode: {type: string, enum: [fast, slow], default: fast}
+`)
+
+	if doc.Info.Extra["contact"] == nil || doc.Info.Extra["license"] == nil || doc.Info.Extra["termsOfService"] == nil {
+		t.Errorf("info = %+v", doc.Info)
+	}
+	if doc.Extra["externalDocs"] == nil || doc.Extra["x-owner"] != "pets" {
+		t.Errorf("document extra = %v", doc.Extra)
+	}
+	if doc.Tags[0].Extra["externalDocs"] == nil {
+		t.Errorf("tag = %+v", doc.Tags[0])
+	}
+	get := operation(t, doc, "/pets", "GET")
+	if get.Extra["externalDocs"] == nil || get.Extra["produces"] != nil {
+		t.Errorf("operation extra = %v", get.Extra)
+	}
+
+	headers := get.Responses["200"].Headers
+	if ids := headers["X-Ids"].Schema; ids.Type != "array" || ids.Items == nil || ids.Items.Format != "int64" {
+		t.Errorf("X-Ids = %+v", ids)
+	}
+	if mode := headers["X-Mode"].Schema; len(mode.Enum) != 2 || mode.Default != "fast" {
+		t.Errorf("X-Mode = %+v", mode)
+	}
+}
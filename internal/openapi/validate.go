@@ -0,0 +1,228 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Validate checks a decoded JSON value against a schema and returns every
+// violation found, each prefixed with the location of the offending value
+func (d *Document) Validate(s *Schema, value interface{}, location string) []string {
+	return d.validate(s, value, location, 0)
+}
+
+// ValidateParameter coerces raw string values of a parameter to its schema
+// type and validates them
+func (d *Document) ValidateParameter(p *Parameter, raw []string) []string {
+	location := fmt.Sprintf("%s parameter '%s'", p.In, p.Name)
+	if len(raw) == 0 {
+		if p.Required {
+			return []string{location + " is required"}
+		}
+		return nil
+	}
+	schema := d.ResolveSchema(p.Schema)
+	if schema == nil {
+		return nil
+	}
+
+	if schema.Type == "array" {
+		items := make([]interface{}, 0, len(raw))
+		for _, r := range raw {
+			v, err := coerce(d.ResolveSchema(schema.Items), r)
+			if err != nil {
+				return []string{fmt.Sprintf("%s: %v", location, err)}
+			}
+			items = append(items, v)
+		}
+		return d.Validate(schema, items, location)
+	}
+
+	v, err := coerce(schema, raw[0])
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %v", location, err)}
+	}
+	return d.Validate(schema, v, location)
+}
+
+func (d *Document) validate(s *Schema, value interface{}, location string, depth int) []string {
+	s = d.ResolveSchema(s)
+	if s == nil || depth > 32 {
+		return nil
+	}
+
+	if value == nil {
+		if s.Nullable || s.Type == "" {
+			return nil
+		}
+		return []string{location + " must not be null"}
+	}
+
+	var errs []string
+
+	for _, sub := range s.AllOf {
+		errs = append(errs, d.validate(sub, value, location, depth+1)...)
+	}
+	alternatives := make([]*Schema, 0, len(s.OneOf)+len(s.AnyOf))
+	alternatives = append(append(alternatives, s.OneOf...), s.AnyOf...)
+	if len(alternatives) > 0 {
+		matched := false
+		for _, sub := range alternatives {
+			if len(d.validate(sub, value, location, depth+1)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			errs = append(errs, location+" does not match any of the allowed schemas")
+		}
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		errs = append(errs, fmt.Sprintf("%s must be one of %v", location, s.Enum))
+	}
+
+	switch s.Type {
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return append(errs, location+" must be a string")
+		}
+		length := utf8.RuneCountInString(str)
+		if s.MinLength != nil && length < *s.MinLength {
+			errs = append(errs, fmt.Sprintf("%s must be at least %d characters", location, *s.MinLength))
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			errs = append(errs, fmt.Sprintf("%s must be at most %d characters", location, *s.MaxLength))
+		}
+		if s.Pattern != "" {
+			if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(str) {
+				errs = append(errs, fmt.Sprintf("%s must match pattern %s", location, s.Pattern))
+			}
+		}
+	case "integer", "number":
+		num, ok := toFloat(value)
+		if !ok {
+			if s.Type == "integer" {
+				return append(errs, location+" must be an integer")
+			}
+			return append(errs, location+" must be a number")
+		}
+		if s.Type == "integer" && num != math.Trunc(num) {
+			return append(errs, location+" must be an integer")
+		}
+		if s.Minimum != nil && num < *s.Minimum {
+			errs = append(errs, fmt.Sprintf("%s must be >= %v", location, *s.Minimum))
+		}
+		if s.Maximum != nil && num > *s.Maximum {
+			errs = append(errs, fmt.Sprintf("%s must be <= %v", location, *s.Maximum))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			errs = append(errs, location+" must be a boolean")
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return append(errs, location+" must be an array")
+		}
+		if s.MinItems != nil && len(items) < *s.MinItems {
+			errs = append(errs, fmt.Sprintf("%s must have at least %d items", location, *s.MinItems))
+		}
+		if s.MaxItems != nil && len(items) > *s.MaxItems {
+			errs = append(errs, fmt.Sprintf("%s must have at most %d items", location, *s.MaxItems))
+		}
+		for i, item := range items {
+			errs = append(errs, d.validate(s.Items, item, fmt.Sprintf("%s[%d]", location, i), depth+1)...)
+		}
+	case "object", "":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			if s.Type == "object" {
+				errs = append(errs, location+" must be an object")
+			}
+			return errs
+		}
+		for _, name := range s.Required {
+			if _, present := obj[name]; !present {
+				errs = append(errs, fmt.Sprintf("%s.%s is required", location, name))
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := location + "." + k
+			if prop, ok := s.Properties[k]; ok {
+				errs = append(errs, d.validate(prop, obj[k], child, depth+1)...)
+				continue
+			}
+			if ap := s.AdditionalProperties; ap != nil {
+				if !ap.Allowed {
+					errs = append(errs, child+" is not allowed")
+				} else if ap.Schema != nil {
+					errs = append(errs, d.validate(ap.Schema, obj[k], child, depth+1)...)
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// coerce converts a raw parameter string to the schema's primitive type
+func coerce(s *Schema, raw string) (interface{}, error) {
+	if s == nil {
+		return raw, nil
+	}
+	switch s.Type {
+	case "integer":
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", raw)
+		}
+		return float64(v), nil
+	case "number":
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", raw)
+		}
+		return v, nil
+	case "boolean":
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", raw)
+		}
+		return v, nil
+	}
+	return raw, nil
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
@@ -6,67 +6,84 @@ import (
 	"io"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/metrics"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/source"
 )
 
 // Client wraps the AWS S3 client with additional functionality
 type Client struct {
-	client *s3.Client
-	bucket string
+	client      *s3.Client
+	bucket      string
+	credentials aws.CredentialsProvider
+
+	// Whether each .json object is a spec, by key, so listings only read
+	// the objects that are new or changed. Keys missing from a listing are
+	// dropped.
+	sniffMu sync.Mutex
+	sniffed map[string]sniffResult
+}
+
+// sniffResult is whether the content with an ETag is a spec
+type sniffResult struct {
+	etag string
+	spec bool
 }
 
-// YAMLFile represents a YAML file in S3
+// sniffConcurrency bounds the ranged reads a listing makes at once
+const sniffConcurrency = 8
+
+// YAMLFile represents a YAML or JSON spec file in S3
 type YAMLFile struct {
 	Key          string
 	Name         string
 	Size         int64
 	LastModified string
+	MimeType     string
 	Content      string
 }
 
-// New creates a new S3 client
-func New(region, bucket, accessKey, secretKey, endpoint string) (*Client, error) {
-	var cfg aws.Config
-	var err error
-
-	if accessKey != "" && secretKey != "" {
-		// Use explicit credentials
-		cfg, err = config.LoadDefaultConfig(context.TODO(),
-			config.WithRegion(region),
-			config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")),
-		)
-	} else {
-		// Use default credential chain (IAM roles, etc.)
-		cfg, err = config.LoadDefaultConfig(context.TODO(),
-			config.WithRegion(region),
-		)
-	}
+// Version is one stored version of an object in a versioned bucket
+type Version struct {
+	ID           string
+	Size         int64
+	LastModified string
+	IsLatest     bool
+}
 
+// New creates a new S3 client
+func New(opts Options) (*Client, error) {
+	cfg, err := loadAWSConfig(context.TODO(), opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		return nil, err
 	}
 
 	// Create S3 client
 	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
-		if endpoint != "" {
-			o.BaseEndpoint = aws.String(endpoint)
+		if opts.Endpoint != "" {
+			o.BaseEndpoint = aws.String(opts.Endpoint)
 			o.UsePathStyle = true
 		}
+		o.APIOptions = append(o.APIOptions, observeCalls(opts.Bucket))
 	})
 
 	return &Client{
-		client: client,
-		bucket: bucket,
+		client:      client,
+		bucket:      opts.Bucket,
+		credentials: cfg.Credentials,
+		sniffed:     make(map[string]sniffResult),
 	}, nil
 }
 
-// ListYAMLFiles lists all YAML files in the S3 bucket
+// ListYAMLFiles lists all YAML files and JSON specs in the S3 bucket
 func (c *Client) ListYAMLFiles(ctx context.Context, prefix string) ([]YAMLFile, error) {
 	var files []YAMLFile
+	var candidates []sniffCandidate
+	listed := make(map[string]bool)
 
 	paginator := s3.NewListObjectsV2Paginator(c.client, &s3.ListObjectsV2Input{
 		Bucket: aws.String(c.bucket),
@@ -81,42 +98,105 @@ func (c *Client) ListYAMLFiles(ctx context.Context, prefix string) ([]YAMLFile,
 
 		for _, obj := range page.Contents {
 			key := aws.ToString(obj.Key)
+			listed[key] = true
 
-			// Filter for YAML files
-			if isYAMLFile(key) {
-				files = append(files, YAMLFile{
-					Key:          key,
-					Name:         extractFileName(key),
-					Size:         obj.Size,
-					LastModified: obj.LastModified.Format("2006-01-02 15:04:05"),
-				})
+			// JSON files are kept only once sniffed as specs
+			if !source.IsYAMLFile(key) && !source.IsJSONFile(key) {
+				continue
+			}
+			if source.IsJSONFile(key) {
+				candidates = append(candidates, sniffCandidate{index: len(files), key: key, etag: aws.ToString(obj.ETag)})
 			}
+			files = append(files, YAMLFile{
+				Key:          key,
+				Name:         extractFileName(key),
+				Size:         obj.Size,
+				LastModified: obj.LastModified.Format("2006-01-02 15:04:05"),
+				MimeType:     source.MimeType(key),
+			})
+		}
+	}
+
+	notSpec := c.sniffJSONFiles(ctx, candidates)
+	c.pruneSniffed(prefix, listed)
+
+	kept := files[:0]
+	for i, file := range files {
+		if !notSpec[i] {
+			kept = append(kept, file)
 		}
 	}
+	return kept, nil
+}
 
-	return files, nil
+// sniffCandidate is a listed .json object and its index in the listing
+type sniffCandidate struct {
+	index int
+	key   string
+	etag  string
 }
 
-// GetYAMLFile downloads and returns the content of a YAML file
-func (c *Client) GetYAMLFile(ctx context.Context, key string) (*YAMLFile, error) {
-	if !isYAMLFile(key) {
-		return nil, fmt.Errorf("file %s is not a YAML file", key)
+// sniffJSONFiles checks the candidates, at most sniffConcurrency at a time,
+// and returns the listing indexes of those that are not specs
+func (c *Client) sniffJSONFiles(ctx context.Context, candidates []sniffCandidate) map[int]bool {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		notSpec = make(map[int]bool)
+		slots   = make(chan struct{}, sniffConcurrency)
+	)
+	for _, candidate := range candidates {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(candidate sniffCandidate) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if !c.isJSONSpec(ctx, candidate.key, candidate.etag) {
+				mu.Lock()
+				notSpec[candidate.index] = true
+				mu.Unlock()
+			}
+		}(candidate)
 	}
+	wg.Wait()
+	return notSpec
+}
 
+// pruneSniffed drops cached answers for keys under prefix that are no
+// longer listed, so deleted objects do not accumulate
+func (c *Client) pruneSniffed(prefix string, listed map[string]bool) {
+	c.sniffMu.Lock()
+	defer c.sniffMu.Unlock()
+	for key := range c.sniffed {
+		if strings.HasPrefix(key, prefix) && !listed[key] {
+			delete(c.sniffed, key)
+		}
+	}
+}
+
+// GetYAMLFile downloads and returns the content of a YAML or JSON spec file
+func (c *Client) GetYAMLFile(ctx context.Context, key string) (*YAMLFile, error) {
+	return c.GetYAMLFileVersion(ctx, key, "")
+}
+
+// GetYAMLFileVersion downloads a version of a spec file; an empty version
+// is the latest
+func (c *Client) GetYAMLFileVersion(ctx context.Context, key, versionID string) (*YAMLFile, error) {
 	// Get object metadata
-	headResp, err := c.client.HeadObject(ctx, &s3.HeadObjectInput{
-		Bucket: aws.String(c.bucket),
-		Key:    aws.String(key),
-	})
+	file, err := c.headYAMLFile(ctx, key, versionID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get object metadata: %w", err)
+		return nil, err
 	}
 
 	// Get object content
-	resp, err := c.client.GetObject(ctx, &s3.GetObjectInput{
+	input := &s3.GetObjectInput{
 		Bucket: aws.String(c.bucket),
 		Key:    aws.String(key),
-	})
+	}
+	if versionID != "" {
+		input.VersionId = aws.String(versionID)
+	}
+	resp, err := c.client.GetObject(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get object: %w", err)
 	}
@@ -127,33 +207,115 @@ func (c *Client) GetYAMLFile(ctx context.Context, key string) (*YAMLFile, error)
 		return nil, fmt.Errorf("failed to read object content: %w", err)
 	}
 
+	file.Content = string(content)
+	return file, nil
+}
+
+// HeadYAMLFile returns the metadata of a spec file without its content
+func (c *Client) HeadYAMLFile(ctx context.Context, key string) (*YAMLFile, error) {
+	return c.headYAMLFile(ctx, key, "")
+}
+
+func (c *Client) headYAMLFile(ctx context.Context, key, versionID string) (*YAMLFile, error) {
+	if !source.IsYAMLFile(key) && !source.IsJSONFile(key) {
+		return nil, fmt.Errorf("file %s is not a YAML or JSON file", key)
+	}
+
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	}
+	if versionID != "" {
+		input.VersionId = aws.String(versionID)
+	}
+	headResp, err := c.client.HeadObject(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object metadata: %w", err)
+	}
+
 	return &YAMLFile{
 		Key:          key,
 		Name:         extractFileName(key),
 		Size:         headResp.ContentLength,
 		LastModified: headResp.LastModified.Format("2006-01-02 15:04:05"),
-		Content:      string(content),
+		MimeType:     source.MimeType(key),
 	}, nil
 }
 
-// SearchYAMLFiles searches for YAML files by name pattern
-func (c *Client) SearchYAMLFiles(ctx context.Context, pattern string) ([]YAMLFile, error) {
-	allFiles, err := c.ListYAMLFiles(ctx, "")
-	if err != nil {
-		return nil, err
+// ListVersions lists the versions of an object, newest first. Buckets
+// without versioning report a single "null" version.
+func (c *Client) ListVersions(ctx context.Context, key string) ([]Version, error) {
+	var versions []Version
+
+	input := &s3.ListObjectVersionsInput{
+		Bucket: aws.String(c.bucket),
+		Prefix: aws.String(key),
 	}
+	for {
+		page, err := c.client.ListObjectVersions(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list object versions: %w", err)
+		}
 
-	var matches []YAMLFile
-	pattern = strings.ToLower(pattern)
+		for _, v := range page.Versions {
+			if aws.ToString(v.Key) != key {
+				continue
+			}
+			versions = append(versions, Version{
+				ID:           aws.ToString(v.VersionId),
+				Size:         v.Size,
+				LastModified: v.LastModified.Format("2006-01-02 15:04:05"),
+				IsLatest:     v.IsLatest,
+			})
+		}
 
-	for _, file := range allFiles {
-		if strings.Contains(strings.ToLower(file.Name), pattern) ||
-			strings.Contains(strings.ToLower(file.Key), pattern) {
-			matches = append(matches, file)
+		if !page.IsTruncated {
+			break
 		}
+		input.KeyMarker = page.NextKeyMarker
+		input.VersionIdMarker = page.NextVersionIdMarker
 	}
 
-	return matches, nil
+	return versions, nil
+}
+
+// isJSONSpec reads the start of a JSON object and reports whether it
+// declares an openapi, swagger or asyncapi version. Answers are cached by
+// ETag; objects listed without one are read every time.
+func (c *Client) isJSONSpec(ctx context.Context, key, etag string) bool {
+	if etag != "" {
+		c.sniffMu.Lock()
+		cached, ok := c.sniffed[key]
+		c.sniffMu.Unlock()
+		hit := ok && cached.etag == etag
+		metrics.CacheLookups.WithLabelValues("s3_sniff", metrics.Lookup(hit)).Inc()
+		if hit {
+			return cached.spec
+		}
+	}
+
+	resp, err := c.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+		Range:  aws.String(fmt.Sprintf("bytes=0-%d", source.SniffBytes-1)),
+	})
+	if err != nil {
+		// Not cached, so a transient failure is retried on the next listing
+		return false
+	}
+	defer resp.Body.Close()
+
+	head, err := io.ReadAll(io.LimitReader(resp.Body, source.SniffBytes))
+	if err != nil {
+		return false
+	}
+	spec := source.LooksLikeSpec(head)
+	if etag != "" {
+		c.sniffMu.Lock()
+		c.sniffed[key] = sniffResult{etag: etag, spec: spec}
+		c.sniffMu.Unlock()
+	}
+	return spec
 }
 
 // TestConnection tests the S3 connection
@@ -167,14 +329,21 @@ func (c *Client) TestConnection(ctx context.Context) error {
 	return nil
 }
 
-// Helper functions
-
-// isYAMLFile checks if a file is a YAML file based on its extension
-func isYAMLFile(key string) bool {
-	ext := strings.ToLower(filepath.Ext(key))
-	return ext == ".yaml" || ext == ".yml"
+// CheckCredentials resolves the credentials requests are signed with,
+// assuming the configured role if any, and returns where they came from
+func (c *Client) CheckCredentials(ctx context.Context) (string, error) {
+	if c.credentials == nil {
+		return "", fmt.Errorf("no credentials configured")
+	}
+	creds, err := c.credentials.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve credentials: %w", err)
+	}
+	return creds.Source, nil
 }
 
+// Helper functions
+
 // extractFileName extracts the filename from a full S3 key
 func extractFileName(key string) string {
 	return filepath.Base(key)
@@ -0,0 +1,99 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// DefaultSessionName is the role session name used when none is configured
+const DefaultSessionName = "s3-mcp-server"
+
+// refreshWindow is how long before they expire temporary credentials are
+// renewed, so a request never goes out with credentials about to lapse
+const refreshWindow = 5 * time.Minute
+
+// Options configure a client: the bucket it reads and how it authenticates
+type Options struct {
+	Region   string
+	Bucket   string
+	Endpoint string // Optional: for S3-compatible services
+
+	// AccessKey and SecretKey are static credentials; Profile is a named
+	// profile of the shared AWS config, and wins over static keys. With
+	// neither, the default credential chain is used.
+	AccessKey string
+	SecretKey string
+	Profile   string
+
+	// RoleARN is a role assumed with the credentials above, for buckets in
+	// other accounts. With WebIdentityTokenFile the role is assumed with
+	// that token instead, as on EKS or GitHub Actions.
+	RoleARN              string
+	ExternalID           string
+	SessionName          string
+	WebIdentityTokenFile string
+}
+
+// stsClient is the part of the STS API roles are assumed with
+type stsClient interface {
+	stscreds.AssumeRoleAPIClient
+	stscreds.AssumeRoleWithWebIdentityAPIClient
+}
+
+// loadAWSConfig resolves the AWS configuration and credentials for opts.
+// Assumed role credentials are cached and renewed before they expire.
+func loadAWSConfig(ctx context.Context, opts Options) (aws.Config, error) {
+	loadOpts := []func(*config.LoadOptions) error{config.WithRegion(opts.Region)}
+	switch {
+	case opts.Profile != "":
+		loadOpts = append(loadOpts, config.WithSharedConfigProfile(opts.Profile))
+	case opts.AccessKey != "" && opts.SecretKey != "":
+		loadOpts = append(loadOpts, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(opts.AccessKey, opts.SecretKey, ""),
+		))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	if opts.RoleARN == "" {
+		if opts.WebIdentityTokenFile != "" {
+			return aws.Config{}, fmt.Errorf("a web identity token file needs a role ARN to assume")
+		}
+		return cfg, nil
+	}
+
+	cfg.Credentials = aws.NewCredentialsCache(roleProvider(sts.NewFromConfig(cfg), opts), func(o *aws.CredentialsCacheOptions) {
+		o.ExpiryWindow = refreshWindow
+	})
+	return cfg, nil
+}
+
+// roleProvider assumes the configured role, with a web identity token when
+// one is configured
+func roleProvider(client stsClient, opts Options) aws.CredentialsProvider {
+	sessionName := opts.SessionName
+	if sessionName == "" {
+		sessionName = DefaultSessionName
+	}
+
+	if opts.WebIdentityTokenFile != "" {
+		return stscreds.NewWebIdentityRoleProvider(client, opts.RoleARN, stscreds.IdentityTokenFile(opts.WebIdentityTokenFile), func(o *stscreds.WebIdentityRoleOptions) {
+			o.RoleSessionName = sessionName
+		})
+	}
+	return stscreds.NewAssumeRoleProvider(client, opts.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = sessionName
+		if opts.ExternalID != "" {
+			o.ExternalID = aws.String(opts.ExternalID)
+		}
+	})
+}
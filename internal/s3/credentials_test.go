@@ -0,0 +1,125 @@
+package s3
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+)
+
+// fakeSTS records the role requests it answers
+type fakeSTS struct {
+	assumed  []*sts.AssumeRoleInput
+	web      []*sts.AssumeRoleWithWebIdentityInput
+	lifetime time.Duration
+}
+
+func (f *fakeSTS) credentials() *types.Credentials {
+	return &types.Credentials{
+		AccessKeyId:     aws.String("assumed"),
+		SecretAccessKey: aws.String("secret"),
+		SessionToken:    aws.String("token"),
+		Expiration:      aws.Time(time.Now().Add(f.lifetime)),
+	}
+}
+
+func (f *fakeSTS) AssumeRole(_ context.Context, in *sts.AssumeRoleInput, _ ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	f.assumed = append(f.assumed, in)
+	return &sts.AssumeRoleOutput{Credentials: f.credentials()}, nil
+}
+
+func (f *fakeSTS) AssumeRoleWithWebIdentity(_ context.Context, in *sts.AssumeRoleWithWebIdentityInput, _ ...func(*sts.Options)) (*sts.AssumeRoleWithWebIdentityOutput, error) {
+	f.web = append(f.web, in)
+	return &sts.AssumeRoleWithWebIdentityOutput{Credentials: f.credentials()}, nil
+}
+
+func TestRoleProvider(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("assume role", func(t *testing.T) {
+		client := &fakeSTS{lifetime: time.Hour}
+		provider := roleProvider(client, Options{RoleARN: "arn:aws:iam::123456789012:role/specs", ExternalID: "partner"})
+		if _, err := provider.Retrieve(ctx); err != nil {
+			t.Fatalf("Retrieve: %v", err)
+		}
+		in := client.assumed[0]
+		if aws.ToString(in.RoleArn) != "arn:aws:iam::123456789012:role/specs" || aws.ToString(in.ExternalId) != "partner" || aws.ToString(in.RoleSessionName) != DefaultSessionName {
+			t.Errorf("AssumeRole input = role %s, external ID %s, session %s", aws.ToString(in.RoleArn), aws.ToString(in.ExternalId), aws.ToString(in.RoleSessionName))
+		}
+	})
+
+	t.Run("web identity", func(t *testing.T) {
+		token := filepath.Join(t.TempDir(), "token")
+		if err := os.WriteFile(token, []byte("jwt"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		client := &fakeSTS{lifetime: time.Hour}
+		provider := roleProvider(client, Options{RoleARN: "arn:aws:iam::123456789012:role/specs", SessionName: "ci", WebIdentityTokenFile: token})
+		if _, err := provider.Retrieve(ctx); err != nil {
+			t.Fatalf("Retrieve: %v", err)
+		}
+		if len(client.assumed) != 0 || len(client.web) != 1 {
+			t.Fatalf("made %d AssumeRole and %d AssumeRoleWithWebIdentity calls", len(client.assumed), len(client.web))
+		}
+		if in := client.web[0]; aws.ToString(in.WebIdentityToken) != "jwt" || aws.ToString(in.RoleSessionName) != "ci" {
+			t.Errorf("AssumeRoleWithWebIdentity input = token %s, session %s", aws.ToString(in.WebIdentityToken), aws.ToString(in.RoleSessionName))
+		}
+	})
+
+	t.Run("refresh before expiry", func(t *testing.T) {
+		client := &fakeSTS{lifetime: refreshWindow - time.Second}
+		cache := aws.NewCredentialsCache(roleProvider(client, Options{RoleARN: "arn:aws:iam::123456789012:role/specs"}), func(o *aws.CredentialsCacheOptions) {
+			o.ExpiryWindow = refreshWindow
+		})
+		for i := 0; i < 2; i++ {
+			if _, err := cache.Retrieve(ctx); err != nil {
+				t.Fatalf("Retrieve: %v", err)
+			}
+		}
+		if len(client.assumed) != 2 {
+			t.Errorf("assumed the role %d times, want credentials inside the refresh window renewed", len(client.assumed))
+		}
+	})
+}
+
+func TestLoadAWSConfig(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config")
+	credentialsFile := filepath.Join(dir, "credentials")
+	if err := os.WriteFile(configFile, []byte("[profile specs]\nregion = eu-west-1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(credentialsFile, []byte("[specs]\naws_access_key_id = profile-key\naws_secret_access_key = profile-secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	ctx := context.Background()
+
+	cfg, err := loadAWSConfig(ctx, Options{Region: "us-east-1", Profile: "specs", AccessKey: "static", SecretKey: "static"})
+	if err != nil {
+		t.Fatalf("loadAWSConfig: %v", err)
+	}
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if creds.AccessKeyID != "profile-key" {
+		t.Errorf("access key = %s, want the profile's", creds.AccessKeyID)
+	}
+
+	if _, err := loadAWSConfig(ctx, Options{Region: "us-east-1", Profile: "missing"}); err == nil {
+		t.Error("loading a missing profile succeeded")
+	}
+	if _, err := loadAWSConfig(ctx, Options{Region: "us-east-1", WebIdentityTokenFile: "/var/run/token"}); err == nil {
+		t.Error("a web identity token without a role succeeded")
+	}
+}
@@ -0,0 +1,79 @@
+package s3
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/logging"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/metrics"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/tracing"
+)
+
+// observeCalls adds a middleware tracing each S3 API call in a span of its
+// own, counting it against the request in its context and in the metrics,
+// and logging it at debug level
+func observeCalls(bucket string) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("ObserveS3Calls", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			if req := logging.FromContext(ctx); req != nil {
+				req.AddS3Call()
+			}
+
+			operation := awsmiddleware.GetOperationName(ctx)
+			ctx, span := tracing.Tracer().Start(ctx, "S3."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+				attribute.String("rpc.system", "aws-api"),
+				attribute.String("rpc.service", "S3"),
+				attribute.String("rpc.method", operation),
+				attribute.String("aws.s3.bucket", bucket),
+			))
+			defer span.End()
+			if key := objectKey(in.Parameters); key != "" {
+				span.SetAttributes(attribute.String("aws.s3.key", key))
+			}
+
+			start := time.Now()
+			out, metadata, err := next.HandleInitialize(ctx, in)
+			metrics.S3Calls.WithLabelValues(operation, metrics.Status(err != nil)).Inc()
+			if resp, ok := awsmiddleware.GetRawResponse(metadata).(*smithyhttp.Response); ok {
+				span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+				// HEAD responses carry the object size without its body
+				if resp.ContentLength > 0 && !strings.HasPrefix(operation, "Head") {
+					metrics.S3Bytes.WithLabelValues(operation).Add(float64(resp.ContentLength))
+				}
+			}
+
+			attrs := []any{
+				"operation", operation,
+				"bucket", bucket,
+				"duration_ms", float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				tracing.RecordError(span, err)
+				attrs = append(attrs, "error", err)
+			}
+			slog.DebugContext(ctx, "S3 call", attrs...)
+			return out, metadata, err
+		}), middleware.After)
+	}
+}
+
+// objectKey is the key of the object an S3 call reads, if any
+func objectKey(params interface{}) string {
+	switch input := params.(type) {
+	case *s3.GetObjectInput:
+		return aws.ToString(input.Key)
+	case *s3.HeadObjectInput:
+		return aws.ToString(input.Key)
+	}
+	return ""
+}
@@ -0,0 +1,301 @@
+// Package s3test provides an in-memory S3 server for tests. It speaks the
+// path-style REST API that the s3 client uses when S3_ENDPOINT is set:
+// HeadBucket, ListObjectsV2, ListObjectVersions, HeadObject and GetObject,
+// with versioning always enabled.
+package s3test
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is a fake S3 endpoint backed by memory
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	buckets  map[string]map[string][]object
+	now      time.Time
+	requests map[string]int
+	// PageSize caps the keys returned per list call, to exercise pagination
+	PageSize int
+}
+
+// object is one version of a stored object
+type object struct {
+	versionID    string
+	content      []byte
+	lastModified time.Time
+}
+
+// NewServer starts a fake S3 endpoint; callers must Close it
+func NewServer() *Server {
+	s := &Server{
+		buckets:  make(map[string]map[string][]object),
+		now:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		requests: make(map[string]int),
+		PageSize: 1000,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// CreateBucket creates an empty bucket
+func (s *Server) CreateBucket(bucket string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.buckets[bucket] == nil {
+		s.buckets[bucket] = make(map[string][]object)
+	}
+}
+
+// Put stores a new version of an object, creating the bucket if needed, and
+// returns its version ID. Each put is dated one minute after the previous one.
+func (s *Server) Put(bucket, key, content string) string {
+	s.CreateBucket(bucket)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(time.Minute)
+	versions := s.buckets[bucket][key]
+	id := fmt.Sprintf("v%d", len(versions)+1)
+	s.buckets[bucket][key] = append(versions, object{versionID: id, content: []byte(content), lastModified: s.now})
+	return id
+}
+
+// Delete removes an object with all of its versions
+func (s *Server) Delete(bucket, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.buckets[bucket], key)
+}
+
+// Requests returns how many requests of an operation, such as "GetObject",
+// the server has handled
+func (s *Server) Requests(operation string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[operation]
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	objects, ok := s.buckets[bucket]
+	if !ok {
+		s.fail(w, r, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodHead && key == "":
+		s.requests["HeadBucket"]++
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodGet && key == "" && query.Has("versions"):
+		s.requests["ListObjectVersions"]++
+		s.listVersions(w, bucket, objects, query.Get("prefix"), query.Get("key-marker"))
+	case r.Method == http.MethodGet && key == "":
+		s.requests["ListObjectsV2"]++
+		s.listObjects(w, bucket, objects, query.Get("prefix"), query.Get("continuation-token"))
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		if r.Method == http.MethodHead {
+			s.requests["HeadObject"]++
+		} else {
+			s.requests["GetObject"]++
+		}
+		obj, ok := find(objects[key], query.Get("versionId"))
+		if !ok {
+			s.fail(w, r, http.StatusNotFound, "NoSuchKey", "The specified key does not exist")
+			return
+		}
+		s.getObject(w, r, obj)
+	default:
+		s.fail(w, r, http.StatusNotImplemented, "NotImplemented", "The fake does not implement this operation")
+	}
+}
+
+// find returns the requested version of an object, or its latest one
+func find(versions []object, versionID string) (object, bool) {
+	if len(versions) == 0 {
+		return object{}, false
+	}
+	if versionID == "" {
+		return versions[len(versions)-1], true
+	}
+	for _, v := range versions {
+		if v.versionID == versionID {
+			return v, true
+		}
+	}
+	return object{}, false
+}
+
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request, obj object) {
+	content := obj.content
+	status := http.StatusOK
+	if start, end, ok := parseRange(r.Header.Get("Range"), len(content)); ok {
+		content = content[start:end]
+		status = http.StatusPartialContent
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.Header().Set("Last-Modified", obj.lastModified.Format(http.TimeFormat))
+	w.Header().Set("ETag", fmt.Sprintf("%q", obj.versionID))
+	w.Header().Set("x-amz-version-id", obj.versionID)
+	w.WriteHeader(status)
+	if r.Method == http.MethodGet {
+		w.Write(content)
+	}
+}
+
+// parseRange reads a "bytes=start-end" header, clamped to the object size
+func parseRange(header string, size int) (int, int, bool) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok {
+		return 0, 0, false
+	}
+	from, to, _ := strings.Cut(spec, "-")
+	start, err := strconv.Atoi(from)
+	if err != nil || start > size {
+		return 0, 0, false
+	}
+	end, err := strconv.Atoi(to)
+	if err != nil || end >= size {
+		end = size - 1
+	}
+	return start, end + 1, true
+}
+
+type listBucketResult struct {
+	XMLName               xml.Name          `xml:"ListBucketResult"`
+	Name                  string            `xml:"Name"`
+	Prefix                string            `xml:"Prefix"`
+	KeyCount              int               `xml:"KeyCount"`
+	MaxKeys               int               `xml:"MaxKeys"`
+	IsTruncated           bool              `xml:"IsTruncated"`
+	NextContinuationToken string            `xml:"NextContinuationToken,omitempty"`
+	Contents              []listedObjectXML `xml:"Contents"`
+}
+
+type listedObjectXML struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int    `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+func (s *Server) listObjects(w http.ResponseWriter, bucket string, objects map[string][]object, prefix, token string) {
+	keys := sortedKeys(objects, prefix, token)
+	result := listBucketResult{Name: bucket, Prefix: prefix, MaxKeys: s.PageSize}
+	if len(keys) > s.PageSize {
+		keys = keys[:s.PageSize]
+		result.IsTruncated = true
+		result.NextContinuationToken = keys[len(keys)-1]
+	}
+	for _, key := range keys {
+		latest := objects[key][len(objects[key])-1]
+		result.Contents = append(result.Contents, listedObjectXML{
+			Key:          key,
+			LastModified: latest.lastModified.Format(time.RFC3339),
+			ETag:         fmt.Sprintf("%q", latest.versionID),
+			Size:         len(latest.content),
+			StorageClass: "STANDARD",
+		})
+	}
+	result.KeyCount = len(result.Contents)
+	writeXML(w, http.StatusOK, result)
+}
+
+type listVersionsResult struct {
+	XMLName             xml.Name     `xml:"ListVersionsResult"`
+	Name                string       `xml:"Name"`
+	Prefix              string       `xml:"Prefix"`
+	MaxKeys             int          `xml:"MaxKeys"`
+	IsTruncated         bool         `xml:"IsTruncated"`
+	NextKeyMarker       string       `xml:"NextKeyMarker,omitempty"`
+	NextVersionIdMarker string       `xml:"NextVersionIdMarker,omitempty"`
+	Versions            []versionXML `xml:"Version"`
+}
+
+type versionXML struct {
+	Key          string `xml:"Key"`
+	VersionID    string `xml:"VersionId"`
+	IsLatest     bool   `xml:"IsLatest"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int    `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+// listVersions pages by whole keys, newest version first within each key
+func (s *Server) listVersions(w http.ResponseWriter, bucket string, objects map[string][]object, prefix, marker string) {
+	keys := sortedKeys(objects, prefix, marker)
+	result := listVersionsResult{Name: bucket, Prefix: prefix, MaxKeys: s.PageSize}
+	if len(keys) > s.PageSize {
+		keys = keys[:s.PageSize]
+		result.IsTruncated = true
+		result.NextKeyMarker = keys[len(keys)-1]
+		result.NextVersionIdMarker = objects[keys[len(keys)-1]][0].versionID
+	}
+	for _, key := range keys {
+		versions := objects[key]
+		for i := len(versions) - 1; i >= 0; i-- {
+			result.Versions = append(result.Versions, versionXML{
+				Key:          key,
+				VersionID:    versions[i].versionID,
+				IsLatest:     i == len(versions)-1,
+				LastModified: versions[i].lastModified.Format(time.RFC3339),
+				ETag:         fmt.Sprintf("%q", versions[i].versionID),
+				Size:         len(versions[i].content),
+				StorageClass: "STANDARD",
+			})
+		}
+	}
+	writeXML(w, http.StatusOK, result)
+}
+
+// sortedKeys returns the keys under prefix that sort after marker
+func sortedKeys(objects map[string][]object, prefix, marker string) []string {
+	var keys []string
+	for key := range objects {
+		if strings.HasPrefix(key, prefix) && key > marker {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type errorXML struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string   `xml:"Code"`
+	Message string   `xml:"Message"`
+}
+
+// fail sends an S3 error; HEAD responses carry no body
+func (s *Server) fail(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	if r.Method == http.MethodHead {
+		w.WriteHeader(status)
+		return
+	}
+	writeXML(w, status, errorXML{Code: code, Message: message})
+}
+
+func writeXML(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	w.Write([]byte(xml.Header))
+	xml.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,144 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/source"
+)
+
+// Source is a named bucket, or a prefix within one, read as a spec source
+type Source struct {
+	name   string
+	prefix string
+	client *Client
+}
+
+var _ source.Source = (*Source)(nil)
+
+// NewSource creates a source for the keys under prefix in the client's bucket
+func NewSource(name, prefix string, client *Client) *Source {
+	return &Source{name: name, prefix: prefix, client: client}
+}
+
+// Name is the configured name of the source
+func (s *Source) Name() string {
+	return s.name
+}
+
+// Root is the s3:// URI of the bucket and prefix
+func (s *Source) Root() string {
+	return s.URI(s.prefix)
+}
+
+// URI returns the s3:// URI of a key
+func (s *Source) URI(key string) string {
+	return fmt.Sprintf("s3://%s/%s", s.client.bucket, key)
+}
+
+// Key returns the key of an s3:// URI in the bucket and under the prefix
+func (s *Source) Key(uri string) (string, bool) {
+	key, ok := strings.CutPrefix(uri, s.URI(""))
+	if !ok || key == "" || !s.contains(key) {
+		return "", false
+	}
+	return key, true
+}
+
+// List lists the spec files under prefix, relative to the source prefix
+// unless it already includes it
+func (s *Source) List(ctx context.Context, prefix string) ([]source.File, error) {
+	if !strings.HasPrefix(prefix, s.prefix) {
+		prefix = s.prefix + prefix
+	}
+	listed, err := s.client.ListYAMLFiles(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]source.File, 0, len(listed))
+	for _, file := range listed {
+		files = append(files, toFile(&file))
+	}
+	return files, nil
+}
+
+// Get reads a spec file, or one of its object versions
+func (s *Source) Get(ctx context.Context, key, version string) (*source.File, error) {
+	if err := s.check(key); err != nil {
+		return nil, err
+	}
+	file, err := s.client.GetYAMLFileVersion(ctx, key, version)
+	if err != nil {
+		return nil, err
+	}
+	out := toFile(file)
+	return &out, nil
+}
+
+// Head returns the metadata of a spec file
+func (s *Source) Head(ctx context.Context, key string) (*source.File, error) {
+	if err := s.check(key); err != nil {
+		return nil, err
+	}
+	file, err := s.client.HeadYAMLFile(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	out := toFile(file)
+	return &out, nil
+}
+
+// Versions lists the object versions of a spec file
+func (s *Source) Versions(ctx context.Context, key string) ([]source.Version, error) {
+	if err := s.check(key); err != nil {
+		return nil, err
+	}
+	listed, err := s.client.ListVersions(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]source.Version, 0, len(listed))
+	for _, v := range listed {
+		versions = append(versions, source.Version{
+			ID:           v.ID,
+			Size:         v.Size,
+			LastModified: v.LastModified,
+			Current:      v.IsLatest,
+		})
+	}
+	return versions, nil
+}
+
+// Check verifies that the bucket can be read
+func (s *Source) Check(ctx context.Context) error {
+	return s.client.TestConnection(ctx)
+}
+
+// CheckCredentials resolves the credentials the bucket is read with and
+// returns where they came from
+func (s *Source) CheckCredentials(ctx context.Context) (string, error) {
+	return s.client.CheckCredentials(ctx)
+}
+
+func (s *Source) contains(key string) bool {
+	return strings.HasPrefix(key, s.prefix)
+}
+
+func (s *Source) check(key string) error {
+	if !s.contains(key) {
+		return fmt.Errorf("key %s is outside source %s (prefix %s)", key, s.name, s.prefix)
+	}
+	return nil
+}
+
+func toFile(file *YAMLFile) source.File {
+	return source.File{
+		Key:          file.Key,
+		Name:         file.Name,
+		Size:         file.Size,
+		LastModified: file.LastModified,
+		MimeType:     file.MimeType,
+		Content:      file.Content,
+	}
+}
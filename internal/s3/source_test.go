@@ -0,0 +1,250 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/logging"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/s3/s3test"
+)
+
+func newTestSource(t *testing.T, prefix string) (*Source, *s3test.Server) {
+	t.Helper()
+	fake := s3test.NewServer()
+	t.Cleanup(fake.Close)
+
+	fake.Put("specs", "apis/cards.yaml", "openapi: 3.0.3\ninfo: {title: Cards, version: '1'}\n")
+	fake.Put("specs", "apis/users.json", `{"openapi": "3.0.3", "info": {"title": "Users", "version": "1"}}`)
+	fake.Put("specs", "apis/package.json", `{"name": "not-a-spec"}`)
+	fake.Put("specs", "apis/README.md", "# Specs")
+	fake.Put("specs", "events/mailer.yml", "asyncapi: 3.0.0\n")
+
+	client, err := New(Options{Region: "us-east-1", Bucket: "specs", AccessKey: "test", SecretKey: "test", Endpoint: fake.URL})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return NewSource("test", prefix, client), fake
+}
+
+func TestSourceList(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		list     string
+		pageSize int
+		want     []string
+	}{
+		{name: "all specs", want: []string{"apis/cards.yaml", "apis/users.json", "events/mailer.yml"}},
+		{name: "paginated", pageSize: 1, want: []string{"apis/cards.yaml", "apis/users.json", "events/mailer.yml"}},
+		{name: "list prefix", list: "events/", want: []string{"events/mailer.yml"}},
+		{name: "source prefix", prefix: "apis/", want: []string{"apis/cards.yaml", "apis/users.json"}},
+		{name: "list prefix within source prefix", prefix: "apis/", list: "apis/users", want: []string{"apis/users.json"}},
+		{name: "list prefix relative to source prefix", prefix: "apis/", list: "cards", want: []string{"apis/cards.yaml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, fake := newTestSource(t, tt.prefix)
+			if tt.pageSize > 0 {
+				fake.PageSize = tt.pageSize
+			}
+
+			files, err := src.List(context.Background(), tt.list)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			var keys []string
+			for _, file := range files {
+				keys = append(keys, file.Key)
+			}
+			if strings.Join(keys, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("List(%q) = %v, want %v", tt.list, keys, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceListCachesJSONSniffing(t *testing.T) {
+	src, fake := newTestSource(t, "")
+	ctx := context.Background()
+	list := func() []string {
+		t.Helper()
+		files, err := src.List(ctx, "apis/")
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		var keys []string
+		for _, file := range files {
+			keys = append(keys, file.Key)
+		}
+		return keys
+	}
+
+	list()
+	sniffs := fake.Requests("GetObject")
+	if sniffs != 2 {
+		t.Errorf("first listing read %d objects, want the 2 JSON files", sniffs)
+	}
+	list()
+	if got := fake.Requests("GetObject"); got != sniffs {
+		t.Errorf("second listing read %d more objects, want none", got-sniffs)
+	}
+
+	// A changed object has a new ETag and is read again
+	fake.Put("specs", "apis/package.json", `{"openapi": "3.1.0", "info": {"title": "Package", "version": "1"}}`)
+	if keys := list(); strings.Join(keys, ",") != "apis/cards.yaml,apis/package.json,apis/users.json" {
+		t.Errorf("List after change = %v", keys)
+	}
+	if got := fake.Requests("GetObject"); got != sniffs+1 {
+		t.Errorf("listing after a change read %d objects, want 1", got-sniffs)
+	}
+}
+
+func TestSourceListPrunesJSONSniffing(t *testing.T) {
+	src, fake := newTestSource(t, "")
+	ctx := context.Background()
+	cached := func() int {
+		src.client.sniffMu.Lock()
+		defer src.client.sniffMu.Unlock()
+		return len(src.client.sniffed)
+	}
+
+	for i := 0; i < 20; i++ {
+		fake.Put("specs", fmt.Sprintf("bulk/spec%02d.json", i), `{"openapi": "3.0.3"}`)
+	}
+	files, err := src.List(ctx, "")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(files) != 23 || cached() != 22 {
+		t.Fatalf("listed %d files with %d cached answers, want 23 and 22", len(files), cached())
+	}
+
+	fake.Delete("specs", "apis/package.json")
+	for i := 0; i < 20; i++ {
+		fake.Delete("specs", fmt.Sprintf("bulk/spec%02d.json", i))
+	}
+	// A narrower listing leaves answers outside its prefix alone
+	if _, err := src.List(ctx, "apis/"); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got := cached(); got != 21 {
+		t.Errorf("cached answers after listing apis/ = %d, want 21", got)
+	}
+	if _, err := src.List(ctx, ""); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got := cached(); got != 1 {
+		t.Errorf("cached answers after deletes = %d, want 1", got)
+	}
+}
+
+func TestSourceGet(t *testing.T) {
+	src, fake := newTestSource(t, "")
+	first := fake.Put("specs", "apis/orders.yaml", "openapi: 3.0.3\ninfo: {version: '1'}\n")
+	fake.Put("specs", "apis/orders.yaml", "openapi: 3.0.3\ninfo: {version: '2'}\n")
+
+	tests := []struct {
+		name    string
+		key     string
+		version string
+		want    string
+		wantErr string
+	}{
+		{name: "latest", key: "apis/orders.yaml", want: "version: '2'"},
+		{name: "earlier version", key: "apis/orders.yaml", version: first, want: "version: '1'"},
+		{name: "json spec", key: "apis/users.json", want: `"title": "Users"`},
+		{name: "missing key", key: "apis/missing.yaml", wantErr: "failed to get object metadata"},
+		{name: "missing version", key: "apis/orders.yaml", version: "v9", wantErr: "failed to get object metadata"},
+		{name: "not a spec file", key: "apis/README.md", wantErr: "not a YAML or JSON file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := src.Get(context.Background(), tt.key, tt.version)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Get error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if !strings.Contains(file.Content, tt.want) {
+				t.Errorf("Get content = %q, want it to contain %q", file.Content, tt.want)
+			}
+			if file.Size != int64(len(file.Content)) {
+				t.Errorf("Get size = %d, want %d", file.Size, len(file.Content))
+			}
+		})
+	}
+}
+
+func TestSourceVersions(t *testing.T) {
+	src, fake := newTestSource(t, "")
+	fake.Put("specs", "apis/cards.yaml", "openapi: 3.0.3\ninfo: {title: Cards, version: '2'}\n")
+	fake.Put("specs", "apis/cards.yaml.bak", "old")
+
+	versions, err := src.Versions(context.Background(), "apis/cards.yaml")
+	if err != nil {
+		t.Fatalf("Versions: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("Versions returned %d versions, want 2: %+v", len(versions), versions)
+	}
+	if versions[0].ID != "v2" || !versions[0].Current || versions[1].ID != "v1" || versions[1].Current {
+		t.Errorf("Versions = %+v, want v2 (current) then v1", versions)
+	}
+}
+
+func TestSourceKeys(t *testing.T) {
+	src, _ := newTestSource(t, "apis/")
+
+	if got := src.URI("apis/cards.yaml"); got != "s3://specs/apis/cards.yaml" {
+		t.Errorf("URI = %s", got)
+	}
+	for uri, want := range map[string]bool{
+		"s3://specs/apis/cards.yaml":    true,
+		"s3://specs/events/mailer.yml":  false,
+		"s3://other/apis/cards.yaml":    false,
+		"file:///specs/apis/cards.yaml": false,
+	} {
+		if _, ok := src.Key(uri); ok != want {
+			t.Errorf("Key(%s) ok = %v, want %v", uri, ok, want)
+		}
+	}
+	if _, err := src.Get(context.Background(), "events/mailer.yml", ""); err == nil || !strings.Contains(err.Error(), "outside source") {
+		t.Errorf("Get outside the prefix error = %v", err)
+	}
+}
+
+func TestSourceCheck(t *testing.T) {
+	src, fake := newTestSource(t, "")
+	if err := src.Check(context.Background()); err != nil {
+		t.Errorf("Check: %v", err)
+	}
+
+	client, err := New(Options{Region: "us-east-1", Bucket: "missing", AccessKey: "test", SecretKey: "test", Endpoint: fake.URL})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := NewSource("missing", "", client).Check(context.Background()); err == nil {
+		t.Error("Check of a missing bucket succeeded")
+	}
+}
+
+func TestSourceCountsCalls(t *testing.T) {
+	src, _ := newTestSource(t, "")
+	req := &logging.Request{ID: "1"}
+	ctx := logging.NewContext(context.Background(), req)
+
+	if _, err := src.Get(ctx, "apis/cards.yaml", ""); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if req.S3Calls() == 0 {
+		t.Error("Get made no S3 calls counted against the request")
+	}
+}
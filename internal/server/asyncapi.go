@@ -0,0 +1,266 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/asyncapi"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/pkg/mcp"
+)
+
+// asyncSpec is a parsed AsyncAPI document and where it was read from
+type asyncSpec struct {
+	Ref specRef
+	Doc *asyncapi.Document
+}
+
+// loadAsyncSpecs loads the AsyncAPI document named by the key argument, or
+// every AsyncAPI document in the selected sources when no key is given
+func (s *Server) loadAsyncSpecs(ctx context.Context, args map[string]interface{}) ([]asyncSpec, error) {
+	refs, err := s.specRefs(ctx, args, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var specs []asyncSpec
+	for _, ref := range refs {
+		file, err := ref.Source.Get(ctx, ref.Key, "")
+		if err != nil {
+			if len(refs) == 1 {
+				return nil, err
+			}
+			s.logger.WarnContext(ctx, "Failed to read file", "source", ref.Source.Name(), "key", ref.Key, "error", err)
+			continue
+		}
+		doc, err := s.parseAsyncAPI(ctx, ref, []byte(file.Content))
+		if err != nil {
+			if len(refs) == 1 {
+				return nil, err
+			}
+			s.skipSpec(ctx, ref, err)
+			continue
+		}
+		specs = append(specs, asyncSpec{Ref: ref, Doc: doc})
+	}
+	return specs, nil
+}
+
+// handleListChannels handles the list_channels tool
+func (s *Server) handleListChannels(ctx context.Context, request *mcp.RequestMessage, args map[string]interface{}) error {
+	specs, err := s.loadAsyncSpecs(ctx, args)
+	if err != nil {
+		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to load AsyncAPI specs: %v", err))
+	}
+	structured := channelListOutput{Specs: []channelSpecOutput{}}
+	if len(specs) == 0 {
+		return s.sendToolResult(request, args, "❌ No AsyncAPI documents found in bucket", structured)
+	}
+
+	var resultText strings.Builder
+	for _, spec := range specs {
+		specOutput := channelSpecOutput{Source: spec.Ref.Source.Name(), Key: spec.Ref.Key, Title: serviceName(spec), Version: spec.Doc.Version, Channels: []channelOutput{}}
+		for _, ch := range spec.Doc.Channels {
+			specOutput.Channels = append(specOutput.Channels, newChannelOutput(ch))
+		}
+		structured.Specs = append(structured.Specs, specOutput)
+
+		resultText.WriteString(fmt.Sprintf("📄 **%s** (%s, AsyncAPI %s)\n", serviceName(spec), s.location(spec.Ref), spec.Doc.Version))
+		if len(spec.Doc.Channels) == 0 {
+			resultText.WriteString("   No channels defined.\n\n")
+			continue
+		}
+		for _, ch := range spec.Doc.Channels {
+			resultText.WriteString(fmt.Sprintf("📡 `%s`", ch.Address))
+			if ch.Name != ch.Address {
+				resultText.WriteString(fmt.Sprintf(" (%s)", ch.Name))
+			}
+			resultText.WriteString("\n")
+			if ch.Description != "" {
+				resultText.WriteString(fmt.Sprintf("   %s\n", firstLine(ch.Description)))
+			}
+			for _, op := range ch.Operations {
+				resultText.WriteString(fmt.Sprintf("   %s %s", actionIcon(op.Action), actionVerb(op.Action)))
+				if op.ID != "" {
+					resultText.WriteString(fmt.Sprintf(" via `%s`", op.ID))
+				}
+				if op.Keyword != op.Action {
+					resultText.WriteString(fmt.Sprintf(" (AsyncAPI `%s`)", op.Keyword))
+				}
+				resultText.WriteString("\n")
+			}
+			if len(ch.Messages) > 0 {
+				names := make([]string, 0, len(ch.Messages))
+				for _, m := range ch.Messages {
+					names = append(names, m.Name)
+				}
+				resultText.WriteString(fmt.Sprintf("   ✉️ Messages: %s\n", strings.Join(names, ", ")))
+			}
+		}
+		resultText.WriteString("\n")
+	}
+
+	return s.sendToolResult(request, args, resultText.String(), structured)
+}
+
+// handleGetMessageSchema handles the get_message_schema tool
+func (s *Server) handleGetMessageSchema(ctx context.Context, request *mcp.RequestMessage, args map[string]interface{}) error {
+	channel, ok := args["channel"].(string)
+	if !ok {
+		return s.sendError(request.ID, -32602, "Channel parameter is required and must be a string")
+	}
+	message, _ := args["message"].(string)
+
+	specs, err := s.loadAsyncSpecs(ctx, args)
+	if err != nil {
+		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to load AsyncAPI specs: %v", err))
+	}
+
+	var resultText strings.Builder
+	structured := messageListOutput{Channel: channel, Messages: []messageOutput{}}
+	for _, spec := range specs {
+		ch := spec.Doc.FindChannel(channel)
+		if ch == nil {
+			continue
+		}
+		for _, m := range ch.Messages {
+			if message != "" && m.Name != message {
+				continue
+			}
+			structured.Messages = append(structured.Messages, messageOutput{
+				Source:      spec.Ref.Source.Name(),
+				Key:         spec.Ref.Key,
+				Channel:     ch.Address,
+				Name:        m.Name,
+				Title:       m.Title,
+				Summary:     m.Summary,
+				ContentType: m.ContentType,
+				Payload:     m.Payload,
+				Headers:     m.Headers,
+			})
+			resultText.WriteString(fmt.Sprintf("✉️ **%s** on `%s` (%s)\n", m.Name, ch.Address, s.location(spec.Ref)))
+			if m.Title != "" {
+				resultText.WriteString(fmt.Sprintf("**Title:** %s\n", m.Title))
+			}
+			if m.Summary != "" {
+				resultText.WriteString(fmt.Sprintf("**Summary:** %s\n", m.Summary))
+			}
+			if m.ContentType != "" {
+				resultText.WriteString(fmt.Sprintf("**Content type:** %s\n", m.ContentType))
+			}
+			writeSchemaBlock(&resultText, "Headers", m.Headers)
+			writeSchemaBlock(&resultText, "Payload", m.Payload)
+			if len(m.Examples) > 0 {
+				if data, err := json.MarshalIndent(m.Examples[0], "", "  "); err == nil {
+					resultText.WriteString(fmt.Sprintf("\n**Example:**\n```json\n%s\n```\n", data))
+				}
+			}
+			resultText.WriteString("\n")
+		}
+	}
+
+	if len(structured.Messages) == 0 {
+		text := fmt.Sprintf("❌ No messages found on channel '%s'", channel)
+		if message != "" {
+			text += fmt.Sprintf(" named '%s'", message)
+		}
+		return s.sendToolResult(request, args, text+"\n\nTip: Use list_channels to see available channels and messages", structured)
+	}
+
+	return s.sendToolResult(request, args, resultText.String(), structured)
+}
+
+// handleFindChannelParticipants handles the find_channel_participants tool
+func (s *Server) handleFindChannelParticipants(ctx context.Context, request *mcp.RequestMessage, args map[string]interface{}) error {
+	channel, ok := args["channel"].(string)
+	if !ok {
+		return s.sendError(request.ID, -32602, "Channel parameter is required and must be a string")
+	}
+
+	// Participants are looked up across every spec, so only the source applies
+	specs, err := s.loadAsyncSpecs(ctx, map[string]interface{}{"source": args["source"]})
+	if err != nil {
+		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to load AsyncAPI specs: %v", err))
+	}
+
+	var publishers, subscribers []string
+	structured := participantsOutput{Channel: channel, Publishers: []participantOutput{}, Subscribers: []participantOutput{}}
+	for _, spec := range specs {
+		for _, ch := range spec.Doc.Channels {
+			if !ch.Matches(channel) {
+				continue
+			}
+			for _, action := range ch.Actions() {
+				entry := fmt.Sprintf("- **%s** on `%s` (%s)", serviceName(spec), ch.Address, s.location(spec.Ref))
+				participant := participantOutput{Service: serviceName(spec), Source: spec.Ref.Source.Name(), Key: spec.Ref.Key, Channel: ch.Address}
+				if action == asyncapi.ActionSend {
+					publishers = append(publishers, entry)
+					structured.Publishers = append(structured.Publishers, participant)
+				} else {
+					subscribers = append(subscribers, entry)
+					structured.Subscribers = append(structured.Subscribers, participant)
+				}
+			}
+		}
+	}
+
+	if len(publishers) == 0 && len(subscribers) == 0 {
+		return s.sendToolResult(request, args, fmt.Sprintf("❌ No services publish or subscribe to channels matching '%s'", channel), structured)
+	}
+
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("🔀 Services using channels matching '%s':\n\n", channel))
+	resultText.WriteString(fmt.Sprintf("📤 **Publishers** (%d):\n", len(publishers)))
+	for _, p := range publishers {
+		resultText.WriteString(p + "\n")
+	}
+	resultText.WriteString(fmt.Sprintf("\n📥 **Subscribers** (%d):\n", len(subscribers)))
+	for _, sub := range subscribers {
+		resultText.WriteString(sub + "\n")
+	}
+
+	return s.sendToolResult(request, args, resultText.String(), structured)
+}
+
+// writeSchemaBlock renders an expanded schema as a YAML code block
+func writeSchemaBlock(b *strings.Builder, title string, schema interface{}) {
+	if schema == nil {
+		return
+	}
+	text, err := asyncapi.MarshalSchema(schema)
+	if err != nil {
+		return
+	}
+	b.WriteString(fmt.Sprintf("\n**%s:**\n```yaml\n%s```\n", title, text))
+}
+
+// serviceName names the service an AsyncAPI document describes
+func serviceName(spec asyncSpec) string {
+	if spec.Doc.Title != "" {
+		return spec.Doc.Title
+	}
+	return spec.Ref.Key
+}
+
+func actionVerb(action string) string {
+	if action == asyncapi.ActionSend {
+		return "Publishes"
+	}
+	return "Subscribes"
+}
+
+func actionIcon(action string) string {
+	if action == asyncapi.ActionSend {
+		return "📤"
+	}
+	return "📥"
+}
+
+// firstLine returns the first line of a possibly multi-line description
+func firstLine(s string) string {
+	if i := strings.Index(s, "\n"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
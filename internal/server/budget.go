@@ -0,0 +1,343 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/budget"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/pkg/mcp"
+)
+
+// toolBudgetHint tells the client how to get the rest of a truncated tool result
+const toolBudgetHint = "Narrow the request (prefix, key, path or method) or call again with a larger max_chars."
+
+// Input properties accepted by every tool to cap the size of its result
+var (
+	maxCharsProperty = map[string]interface{}{
+		"type":        "integer",
+		"description": "Truncate the result to about this many characters, summarizing lists and eliding examples",
+	}
+	maxTokensProperty = map[string]interface{}{
+		"type":        "integer",
+		"description": fmt.Sprintf("Like max_chars, counted in tokens of about %d characters", budget.CharsPerToken),
+	}
+	offsetProperty = map[string]interface{}{
+		"type":        "integer",
+		"description": "Skip this many files, to fetch the rest of a truncated listing",
+	}
+)
+
+// outputBudget turns max_chars and max_tokens into a character budget,
+// falling back to the configured default when neither is set
+func (s *Server) outputBudget(maxChars, maxTokens int) int {
+	switch {
+	case maxChars > 0:
+		return maxChars
+	case maxTokens > 0:
+		return maxTokens * budget.CharsPerToken
+	default:
+		return s.config.MaxOutputChars
+	}
+}
+
+// toolBudget reads the output budget from tool arguments
+func (s *Server) toolBudget(args map[string]interface{}) (int, error) {
+	maxChars, err := intArg(args, "max_chars")
+	if err != nil {
+		return 0, err
+	}
+	maxTokens, err := intArg(args, "max_tokens")
+	if err != nil {
+		return 0, err
+	}
+	return s.outputBudget(maxChars, maxTokens), nil
+}
+
+// resourceBudget reads the output budget from a resource URI query
+func (s *Server) resourceBudget(query url.Values) (int, error) {
+	values := make([]int, 2)
+	for i, name := range []string{"max_chars", "max_tokens"} {
+		if query.Get(name) == "" {
+			continue
+		}
+		n, err := strconv.Atoi(query.Get(name))
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%s must be a non-negative integer", name)
+		}
+		values[i] = n
+	}
+	return s.outputBudget(values[0], values[1]), nil
+}
+
+// fitToolResult truncates the text blocks of a tool result to the budget of
+// the tool call in progress. Blocks with a MIME type carry machine-readable
+// payloads, such as generated code or an exported collection, that a cut
+// would leave invalid; they are sent whole.
+func (s *Server) fitToolResult(result *mcp.ToolResult) {
+	if s.budget <= 0 {
+		return
+	}
+	for i, content := range result.Content {
+		if content.Type == "text" && content.MimeType == "" {
+			result.Content[i].Text, _ = budget.Text(content.Text, s.budget, toolBudgetHint)
+		}
+	}
+}
+
+// truncatable is a structured result whose lists can be cut to fit the
+// output budget
+type truncatable interface {
+	// entries counts the list entries of the result
+	entries() int
+	// truncate returns a copy keeping the first n entries
+	truncate(n int) interface{}
+}
+
+// fitStructured keeps as many leading entries of a structured result as fit
+// the budget of the tool call in progress once encoded
+func (s *Server) fitStructured(structured interface{}, encode func(interface{}) ([]byte, error)) interface{} {
+	list, ok := structured.(truncatable)
+	if !ok || s.budget <= 0 {
+		return structured
+	}
+	fits := func(v interface{}) bool {
+		data, err := encode(v)
+		return err == nil && len(data) <= s.budget
+	}
+	if fits(structured) {
+		return structured
+	}
+	kept := sort.Search(list.entries(), func(n int) bool { return !fits(list.truncate(n + 1)) })
+	return list.truncate(kept)
+}
+
+// writeFileList writes file entries from offset on until the budget runs
+// out, summarizing the files left by folder. It returns the files shown and
+// the offset to continue from, or zero when the listing is complete.
+func (s *Server) writeFileList(b *strings.Builder, files []sourceFile, offset int) ([]sourceFile, int) {
+	if offset > len(files) {
+		offset = len(files)
+	}
+	// Leave room for the summary of the files that do not fit
+	limit := s.budget - 500
+
+	end := offset
+	for ; end < len(files); end++ {
+		entry := s.fileEntry(files[end])
+		if s.budget > 0 && end > offset && b.Len()+len(entry) > limit {
+			break
+		}
+		b.WriteString(entry)
+	}
+
+	if end == len(files) {
+		return files[offset:end], 0
+	}
+
+	b.WriteString(fmt.Sprintf("… and %d more files not shown: %s\n", len(files)-end, folderSummary(files[end:])))
+	b.WriteString(fmt.Sprintf("Call again with offset=%d to fetch the rest, or narrow the listing with a prefix.\n", end))
+	return files[offset:end], end
+}
+
+// fileEntry renders one file of a listing
+func (s *Server) fileEntry(file sourceFile) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("📄 **%s**\n", file.Name))
+	if len(s.sources) > 1 {
+		b.WriteString(fmt.Sprintf("   - Source: %s\n", file.Source.Name()))
+	}
+	b.WriteString(fmt.Sprintf("   - Key: %s\n", file.Key))
+	b.WriteString(fmt.Sprintf("   - Size: %d bytes\n", file.Size))
+	b.WriteString(fmt.Sprintf("   - Modified: %s\n", file.LastModified))
+	b.WriteString(fmt.Sprintf("   - URI: %s\n\n", file.Source.URI(file.Key)))
+	return b.String()
+}
+
+// folderSummary counts files per folder, largest first
+func folderSummary(files []sourceFile) string {
+	counts := make(map[string]int)
+	for _, file := range files {
+		counts[path.Dir(file.Key)+"/"]++
+	}
+	folders := make([]string, 0, len(counts))
+	for folder := range counts {
+		folders = append(folders, folder)
+	}
+	sort.Slice(folders, func(i, j int) bool {
+		if counts[folders[i]] != counts[folders[j]] {
+			return counts[folders[i]] > counts[folders[j]]
+		}
+		return folders[i] < folders[j]
+	})
+
+	parts := make([]string, 0, len(folders))
+	for i, folder := range folders {
+		if i == 10 {
+			parts = append(parts, fmt.Sprintf("%d more folders", len(folders)-i))
+			break
+		}
+		if folder == "./" {
+			folder = "(root)"
+		}
+		parts = append(parts, fmt.Sprintf("%s (%d)", folder, counts[folders[i]]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (o fileListOutput) entries() int { return len(o.Files) }
+
+// truncate keeps the first n files and moves nextOffset back to the first
+// file dropped
+func (o fileListOutput) truncate(n int) interface{} {
+	o.Files = o.Files[:n]
+	o.NextOffset = o.Offset + n
+	o.truncation = newTruncation(o.Count, o.NextOffset)
+	return o
+}
+
+func (o endpointListOutput) entries() int { return len(o.Endpoints) }
+
+func (o endpointListOutput) truncate(n int) interface{} {
+	o.truncation = newTruncation(len(o.Endpoints), n)
+	o.Endpoints = o.Endpoints[:n]
+	return o
+}
+
+func (o authListOutput) entries() int { return len(o.Endpoints) }
+
+func (o authListOutput) truncate(n int) interface{} {
+	o.truncation = newTruncation(len(o.Endpoints), n)
+	o.Endpoints = o.Endpoints[:n]
+	return o
+}
+
+// entries counts error responses, the groups being their operations
+func (o errorListOutput) entries() int {
+	total := 0
+	for _, op := range o.Operations {
+		total += len(op.Errors)
+	}
+	return total
+}
+
+func (o errorListOutput) truncate(n int) interface{} {
+	o.truncation = newTruncation(o.entries(), n)
+	operations := []errorOperationOutput{}
+	for _, op := range o.Operations {
+		if n <= 0 {
+			break
+		}
+		if len(op.Errors) > n {
+			op.Errors = op.Errors[:n]
+		}
+		n -= len(op.Errors)
+		operations = append(operations, op)
+	}
+	o.Operations = operations
+	return o
+}
+
+// entries counts deprecated items, the groups being their specs
+func (o deprecationListOutput) entries() int {
+	total := 0
+	for _, spec := range o.Specs {
+		total += len(spec.Items)
+	}
+	return total
+}
+
+func (o deprecationListOutput) truncate(n int) interface{} {
+	o.truncation = newTruncation(o.entries(), n)
+	specs := []deprecationSpecOutput{}
+	for _, spec := range o.Specs {
+		if n <= 0 {
+			break
+		}
+		if len(spec.Items) > n {
+			spec.Items = spec.Items[:n]
+		}
+		n -= len(spec.Items)
+		specs = append(specs, spec)
+	}
+	o.Specs = specs
+	return o
+}
+
+// entries counts channels, the groups being their specs
+func (o channelListOutput) entries() int {
+	total := 0
+	for _, spec := range o.Specs {
+		total += len(spec.Channels)
+	}
+	return total
+}
+
+func (o channelListOutput) truncate(n int) interface{} {
+	o.truncation = newTruncation(o.entries(), n)
+	specs := []channelSpecOutput{}
+	for _, spec := range o.Specs {
+		if n <= 0 {
+			break
+		}
+		if len(spec.Channels) > n {
+			spec.Channels = spec.Channels[:n]
+		}
+		n -= len(spec.Channels)
+		specs = append(specs, spec)
+	}
+	o.Specs = specs
+	return o
+}
+
+func (o messageListOutput) entries() int { return len(o.Messages) }
+
+func (o messageListOutput) truncate(n int) interface{} {
+	o.truncation = newTruncation(len(o.Messages), n)
+	o.Messages = o.Messages[:n]
+	return o
+}
+
+// entries counts publishers, then subscribers
+func (o participantsOutput) entries() int { return len(o.Publishers) + len(o.Subscribers) }
+
+func (o participantsOutput) truncate(n int) interface{} {
+	o.truncation = newTruncation(o.entries(), n)
+	if len(o.Publishers) > n {
+		o.Publishers = o.Publishers[:n]
+	}
+	o.Subscribers = o.Subscribers[:n-len(o.Publishers)]
+	return o
+}
+
+func (o versionListOutput) entries() int { return len(o.Versions) }
+
+func (o versionListOutput) truncate(n int) interface{} {
+	o.truncation = newTruncation(len(o.Versions), n)
+	o.Versions = o.Versions[:n]
+	return o
+}
+
+func (o compareOutput) entries() int { return len(o.Changes) }
+
+func (o compareOutput) truncate(n int) interface{} {
+	o.truncation = newTruncation(len(o.Changes), n)
+	o.Changes = o.Changes[:n]
+	return o
+}
+
+// intArg reads an optional non-negative integer argument
+func intArg(args map[string]interface{}, name string) (int, error) {
+	value, ok := args[name]
+	if !ok || value == nil {
+		return 0, nil
+	}
+	n, ok := value.(float64)
+	if !ok || n < 0 || n != float64(int(n)) {
+		return 0, fmt.Errorf("%s must be a non-negative integer", name)
+	}
+	return int(n), nil
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/codegen"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/openapi"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/pkg/mcp"
+)
+
+// handleGenerateGoClient handles the generate_go_client tool
+func (s *Server) handleGenerateGoClient(ctx context.Context, request *mcp.RequestMessage, args map[string]interface{}) error {
+	key, ok := args["key"].(string)
+	if !ok {
+		return s.sendError(request.ID, -32602, "Key parameter is required and must be a string")
+	}
+
+	pkg := "client"
+	if p, ok := args["package"].(string); ok && p != "" {
+		pkg = p
+	}
+
+	ref, err := s.specRefArg(ctx, args, key)
+	if err != nil {
+		return s.sendError(request.ID, -32602, err.Error())
+	}
+	doc, err := s.loadSpec(ctx, ref)
+	if err != nil {
+		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to load spec: %v", err))
+	}
+
+	ops, err := doc.SelectOperations(stringSliceArg(args, "operations"))
+	if err != nil {
+		return s.sendError(request.ID, -32602, err.Error())
+	}
+
+	src, err := codegen.GoClient(doc, ops, pkg)
+	if err != nil {
+		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to generate Go client: %v", err))
+	}
+
+	result := &mcp.ToolResult{
+		Content: []mcp.ToolContent{
+			{
+				Type: "text",
+				Text: fmt.Sprintf("Go client for %d operation(s) from %s:", len(ops), key),
+			},
+			{
+				Type:     "text",
+				Text:     src,
+				MimeType: "text/x-go",
+			},
+		},
+	}
+
+	return s.sendResponse(request.ID, result)
+}
+
+// handleGenerateTypeScriptTypes handles the generate_typescript_types tool
+func (s *Server) handleGenerateTypeScriptTypes(ctx context.Context, request *mcp.RequestMessage, args map[string]interface{}) error {
+	key, ok := args["key"].(string)
+	if !ok {
+		return s.sendError(request.ID, -32602, "Key parameter is required and must be a string")
+	}
+
+	ref, err := s.specRefArg(ctx, args, key)
+	if err != nil {
+		return s.sendError(request.ID, -32602, err.Error())
+	}
+	doc, err := s.loadSpec(ctx, ref)
+	if err != nil {
+		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to load spec: %v", err))
+	}
+
+	schemas := stringSliceArg(args, "schemas")
+	selectors := stringSliceArg(args, "operations")
+
+	// Without a selection, convert every component and every operation
+	var ops []openapi.OperationRef
+	if len(schemas) == 0 && len(selectors) == 0 {
+		for name := range doc.Components.Schemas {
+			schemas = append(schemas, name)
+		}
+		sort.Strings(schemas)
+		ops = doc.Operations()
+	} else if len(selectors) > 0 {
+		ops, err = doc.SelectOperations(selectors)
+		if err != nil {
+			return s.sendError(request.ID, -32602, err.Error())
+		}
+	}
+
+	src, err := codegen.TypeScriptTypes(doc, schemas, ops)
+	if err != nil {
+		return s.sendError(request.ID, -32602, err.Error())
+	}
+
+	result := &mcp.ToolResult{
+		Content: []mcp.ToolContent{
+			{
+				Type: "text",
+				Text: fmt.Sprintf("TypeScript types for %d schema(s) and %d operation(s) from %s:", len(schemas), len(ops), key),
+			},
+			{
+				Type:     "text",
+				Text:     src,
+				MimeType: "text/x-typescript",
+			},
+		},
+	}
+
+	return s.sendResponse(request.ID, result)
+}
+
+// handleRenderMarkdown handles the render_markdown tool
+func (s *Server) handleRenderMarkdown(ctx context.Context, request *mcp.RequestMessage, args map[string]interface{}) error {
+	key, ok := args["key"].(string)
+	if !ok {
+		return s.sendError(request.ID, -32602, "Key parameter is required and must be a string")
+	}
+
+	ref, err := s.specRefArg(ctx, args, key)
+	if err != nil {
+		return s.sendError(request.ID, -32602, err.Error())
+	}
+	doc, err := s.loadSpec(ctx, ref)
+	if err != nil {
+		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to load spec: %v", err))
+	}
+
+	return s.sendText(request.ID, codegen.Markdown(doc))
+}
+
+// ExportCollection converts the spec at key, or every spec under prefix,
+// into a Postman v2.1 collection. An empty source means the one source
+// holding a key and every source for a prefix.
+func (s *Server) ExportCollection(ctx context.Context, sourceName, key, prefix string) (*codegen.PostmanCollection, error) {
+	refs, err := s.specRefs(ctx, map[string]interface{}{"source": sourceName, "key": key}, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list YAML files: %w", err)
+	}
+
+	if key != "" {
+		doc, err := s.loadSpec(ctx, refs[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to load spec %s: %w", key, err)
+		}
+		name := doc.Info.Title
+		if name == "" {
+			name = key
+		}
+		return codegen.Postman(name, []codegen.NamedSpec{{Name: name, Doc: doc}}), nil
+	}
+
+	var specs []codegen.NamedSpec
+	for _, ref := range refs {
+		doc, err := s.loadSpec(ctx, ref)
+		if err != nil {
+			s.skipSpec(ctx, ref, err)
+			continue
+		}
+		name := doc.Info.Title
+		if name == "" {
+			name = path.Base(ref.Key)
+		}
+		specs = append(specs, codegen.NamedSpec{Name: name, Doc: doc})
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no OpenAPI specs found under prefix '%s'", prefix)
+	}
+
+	name := prefix
+	if sourceName != "" {
+		name = fmt.Sprintf("%s/%s", sourceName, prefix)
+	}
+	return codegen.Postman(strings.TrimSuffix(name, "/"), specs), nil
+}
+
+// handleExportCollection handles the export_collection tool
+func (s *Server) handleExportCollection(ctx context.Context, request *mcp.RequestMessage, args map[string]interface{}) error {
+	key, _ := args["key"].(string)
+	prefix, _ := args["prefix"].(string)
+	if key == "" && prefix == "" {
+		return s.sendError(request.ID, -32602, "Either key or prefix parameter is required")
+	}
+
+	sourceName, _ := args["source"].(string)
+	collection, err := s.ExportCollection(ctx, sourceName, key, prefix)
+	if err != nil {
+		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to export collection: %v", err))
+	}
+
+	data, err := json.MarshalIndent(collection, "", "  ")
+	if err != nil {
+		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to encode collection: %v", err))
+	}
+
+	result := &mcp.ToolResult{
+		Content: []mcp.ToolContent{
+			{
+				Type:     "text",
+				Text:     string(data),
+				MimeType: "application/json",
+			},
+		},
+	}
+
+	return s.sendResponse(request.ID, result)
+}
+
+// stringSliceArg reads an optional array-of-strings tool argument
+func stringSliceArg(args map[string]interface{}, name string) []string {
+	raw, ok := args[name].([]interface{})
+	if !ok {
+		return nil
+	}
+	var values []string
+	for _, v := range raw {
+		if str, ok := v.(string); ok && str != "" {
+			values = append(values, str)
+		}
+	}
+	return values
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/openapi"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/pkg/mcp"
+)
+
+// handleCompareSpec handles the compare_spec tool. The spec in the selected
+// source, typically a git working branch, is compared against a published
+// copy in another source or another version of the same file.
+func (s *Server) handleCompareSpec(ctx context.Context, request *mcp.RequestMessage, args map[string]interface{}) error {
+	key, ok := args["key"].(string)
+	if !ok {
+		return s.sendError(request.ID, -32602, "Key parameter is required and must be a string")
+	}
+
+	head, err := s.specRefArg(ctx, args, key)
+	if err != nil {
+		return s.sendError(request.ID, -32602, err.Error())
+	}
+	head.Version, _ = args["version"].(string)
+
+	base := specRef{Source: head.Source, Key: key}
+	if name, ok := args["against"].(string); ok && name != "" {
+		if base.Source, err = s.findSource(name); err != nil {
+			return s.sendError(request.ID, -32602, err.Error())
+		}
+	}
+	if againstKey, ok := args["against_key"].(string); ok && againstKey != "" {
+		base.Key = againstKey
+	}
+	base.Version, _ = args["against_version"].(string)
+	if base == head {
+		return s.sendError(request.ID, -32602, "Nothing to compare: give another source with against, another key with against_key, or a version")
+	}
+
+	headFile, err := head.Source.Get(ctx, head.Key, head.Version)
+	if err != nil {
+		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to read %s: %v", s.location(head), err))
+	}
+	baseFile, err := base.Source.Get(ctx, base.Key, base.Version)
+	if err != nil {
+		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to read %s: %v", s.location(base), err))
+	}
+
+	structured := compareOutput{
+		Base:      newCompareSideOutput(base, baseFile.Size),
+		Head:      newCompareSideOutput(head, headFile.Size),
+		Identical: baseFile.Content == headFile.Content,
+		Compared:  "openapi",
+		Changes:   []changeOutput{},
+	}
+
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("🔀 Comparing %s against %s\n\n", compareLabel(head), compareLabel(base)))
+	if structured.Identical {
+		resultText.WriteString("✅ The files are identical\n")
+		return s.sendToolResult(request, args, resultText.String(), structured)
+	}
+
+	// AsyncAPI documents and other YAML are only compared as text
+	baseDoc, baseErr := s.parseOpenAPI(ctx, base, []byte(baseFile.Content))
+	headDoc, headErr := s.parseOpenAPI(ctx, head, []byte(headFile.Content))
+	if baseErr != nil || headErr != nil {
+		structured.Compared = "text"
+		resultText.WriteString(fmt.Sprintf("📝 The files differ (%d → %d bytes, %d → %d lines). They are not both OpenAPI documents, so only their text was compared.\n",
+			baseFile.Size, headFile.Size, strings.Count(baseFile.Content, "\n"), strings.Count(headFile.Content, "\n")))
+		return s.sendToolResult(request, args, resultText.String(), structured)
+	}
+
+	changes := openapi.Compare(baseDoc, headDoc)
+	var breaking, other []openapi.Change
+	for _, change := range changes {
+		structured.Changes = append(structured.Changes, changeOutput{
+			Kind:     change.Kind,
+			Location: change.Location,
+			Detail:   change.Detail,
+			Breaking: change.Breaking,
+		})
+		if change.Breaking {
+			breaking = append(breaking, change)
+		} else {
+			other = append(other, change)
+		}
+	}
+	structured.Breaking = len(breaking)
+
+	if len(changes) == 0 {
+		resultText.WriteString("✅ No API changes; the files differ only in descriptions, examples or formatting\n")
+		return s.sendToolResult(request, args, resultText.String(), structured)
+	}
+
+	resultText.WriteString(fmt.Sprintf("Found %d change(s), %d breaking.\n", len(changes), len(breaking)))
+	if len(breaking) > 0 {
+		resultText.WriteString("\n### 💥 Breaking changes\n\n")
+		writeChanges(&resultText, breaking)
+	}
+	if len(other) > 0 {
+		resultText.WriteString("\n### Other changes\n\n")
+		writeChanges(&resultText, other)
+	}
+
+	return s.sendToolResult(request, args, resultText.String(), structured)
+}
+
+// compareLabel names one side of a comparison, with its version when given
+func compareLabel(ref specRef) string {
+	label := fmt.Sprintf("%s (source %s)", ref.Key, ref.Source.Name())
+	if ref.Version != "" {
+		label += " at " + ref.Version
+	}
+	return label
+}
+
+func writeChanges(b *strings.Builder, changes []openapi.Change) {
+	icons := map[string]string{openapi.ChangeAdded: "➕", openapi.ChangeRemoved: "➖", openapi.ChangeModified: "✏️"}
+	for _, change := range changes {
+		b.WriteString(fmt.Sprintf("- %s `%s`: %s\n", icons[change.Kind], change.Location, change.Detail))
+	}
+}
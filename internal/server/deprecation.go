@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/openapi"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/pkg/mcp"
+)
+
+// specDeprecations holds the deprecated elements found in one spec
+type specDeprecations struct {
+	Ref   specRef
+	Title string
+	Items []openapi.Deprecation
+}
+
+// handleListDeprecated handles the list_deprecated tool
+func (s *Server) handleListDeprecated(ctx context.Context, request *mcp.RequestMessage, args map[string]interface{}) error {
+	key, _ := args["key"].(string)
+	prefix, _ := args["prefix"].(string)
+
+	refs, err := s.specRefs(ctx, args, prefix)
+	if err != nil {
+		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to list YAML files: %v", err))
+	}
+
+	var specs []specDeprecations
+	total := 0
+	for _, ref := range refs {
+		doc, err := s.loadSpec(ctx, ref)
+		if err != nil {
+			if key != "" {
+				return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to load spec: %v", err))
+			}
+			s.skipSpec(ctx, ref, err)
+			continue
+		}
+		items := doc.Deprecations()
+		if len(items) == 0 {
+			continue
+		}
+		title := doc.Info.Title
+		if title == "" {
+			title = ref.Key
+		}
+		specs = append(specs, specDeprecations{Ref: ref, Title: title, Items: items})
+		total += len(items)
+	}
+
+	structured := deprecationListOutput{Count: total, Specs: []deprecationSpecOutput{}}
+	if total == 0 {
+		return s.sendToolResult(request, args, "✅ No deprecated operations, parameters or properties found", structured)
+	}
+
+	// Specs with the most urgent sunset come first
+	sort.SliceStable(specs, func(i, j int) bool {
+		a, b := specs[i].Items[0].Date, specs[j].Items[0].Date
+		if a.IsZero() != b.IsZero() {
+			return !a.IsZero()
+		}
+		return a.Before(b)
+	})
+
+	now := time.Now()
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("⚠️ Found %d deprecated item(s) in %d spec(s):\n\n", total, len(specs)))
+	for _, spec := range specs {
+		specOutput := deprecationSpecOutput{Source: spec.Ref.Source.Name(), Key: spec.Ref.Key, Title: spec.Title, Items: []deprecationOutput{}}
+		resultText.WriteString(fmt.Sprintf("📄 **%s** (%s)\n", spec.Title, s.location(spec.Ref)))
+		for _, item := range spec.Items {
+			specOutput.Items = append(specOutput.Items, newDeprecationOutput(item, now))
+			switch {
+			case item.Sunset == "":
+				resultText.WriteString("- ⏳ no sunset")
+			case item.Date.IsZero():
+				resultText.WriteString(fmt.Sprintf("- 🗓️ %s", item.Sunset))
+			case item.Date.Before(now):
+				resultText.WriteString(fmt.Sprintf("- 🔥 %s (past due)", item.Sunset))
+			default:
+				resultText.WriteString(fmt.Sprintf("- 🗓️ %s", item.Sunset))
+			}
+			resultText.WriteString(fmt.Sprintf(" · %s `%s`", item.Kind, item.Location))
+			if item.Note != "" {
+				resultText.WriteString(" — " + firstLine(item.Note))
+			}
+			resultText.WriteString("\n")
+		}
+		resultText.WriteString("\n")
+		structured.Specs = append(structured.Specs, specOutput)
+	}
+
+	return s.sendToolResult(request, args, resultText.String(), structured)
+}
@@ -0,0 +1,266 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/aws/smithy-go"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/asyncapi"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/config"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/openapi"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/source"
+)
+
+// maxListedProblems caps the spec files named in a failed parse check
+const maxListedProblems = 5
+
+// doctorStatus is the outcome of a doctor check
+type doctorStatus int
+
+const (
+	doctorOK doctorStatus = iota
+	doctorWarn
+	doctorFail
+)
+
+// doctorCheck is one diagnostic of a source, with a hint on how to fix it
+// when it did not pass
+type doctorCheck struct {
+	Status doctorStatus
+	Name   string
+	Detail string
+	Hint   string
+}
+
+// credentialChecker is implemented by sources signing their requests, such
+// as S3 buckets
+type credentialChecker interface {
+	CheckCredentials(ctx context.Context) (string, error)
+}
+
+// Doctor checks the settings, credentials, reachability, permissions and
+// spec files of every source, printing diagnostics to w. It reports whether
+// every check passed; warnings do not fail.
+func (s *Server) Doctor(ctx context.Context, w io.Writer) bool {
+	healthy := true
+	for i, src := range s.sources {
+		fmt.Fprintf(w, "🩺 Source %s (%s)\n", src.Name(), src.Root())
+		for _, check := range s.diagnose(ctx, src, s.config.Sources[i]) {
+			icon := "✅"
+			switch check.Status {
+			case doctorWarn:
+				icon = "⚠️"
+			case doctorFail:
+				icon = "❌"
+				healthy = false
+			}
+			fmt.Fprintf(w, "  %s %s: %s\n", icon, check.Name, check.Detail)
+			if check.Hint != "" {
+				fmt.Fprintf(w, "     → %s\n", check.Hint)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	if healthy {
+		fmt.Fprintln(w, "✅ All checks passed")
+	} else {
+		fmt.Fprintln(w, "❌ Some checks failed")
+	}
+	return healthy
+}
+
+// diagnose runs the checks of one source, stopping at the first failure
+// later checks depend on
+func (s *Server) diagnose(ctx context.Context, src source.Source, cfg config.Source) []doctorCheck {
+	checks := []doctorCheck{settingsCheck(cfg)}
+	if checks[0].Status == doctorFail {
+		return checks
+	}
+
+	if checker, ok := src.(credentialChecker); ok {
+		from, err := checker.CheckCredentials(ctx)
+		if err != nil {
+			return append(checks, doctorCheck{Status: doctorFail, Name: "Credentials", Detail: err.Error(), Hint: credentialsHint(cfg)})
+		}
+		checks = append(checks, doctorCheck{Name: "Credentials", Detail: "resolved from " + from})
+	}
+
+	if err := src.Check(ctx); err != nil {
+		return append(checks, doctorCheck{Status: doctorFail, Name: "Reachable", Detail: err.Error(), Hint: errorHint(err, cfg)})
+	}
+	checks = append(checks, doctorCheck{Name: "Reachable", Detail: "the source can be read"})
+
+	files, err := src.List(ctx, "")
+	if err != nil {
+		return append(checks, doctorCheck{Status: doctorFail, Name: "List", Detail: err.Error(), Hint: errorHint(err, cfg)})
+	}
+	if len(files) == 0 {
+		return append(checks, doctorCheck{Status: doctorWarn, Name: "List", Detail: "no spec files found",
+			Hint: "Only .yaml, .yml and .json files with an openapi, swagger or asyncapi key are served; check the prefix or directory"})
+	}
+	checks = append(checks, doctorCheck{Name: "List", Detail: fmt.Sprintf("%d spec file(s)", len(files))})
+
+	first, err := src.Get(ctx, files[0].Key, "")
+	if err != nil {
+		return append(checks, doctorCheck{Status: doctorFail, Name: "Get", Detail: err.Error(), Hint: errorHint(err, cfg)})
+	}
+	checks = append(checks, doctorCheck{Name: "Get", Detail: fmt.Sprintf("read %s (%d bytes)", first.Key, first.Size)})
+
+	return append(checks, s.parseCheck(ctx, src, files))
+}
+
+// settingsCheck describes the settings of a source and catches mistakes
+// that would fail every request
+func settingsCheck(cfg config.Source) doctorCheck {
+	check := doctorCheck{Name: "Settings"}
+	switch {
+	case cfg.Dir != "":
+		check.Detail = "directory " + cfg.Dir
+		if info, err := os.Stat(cfg.Dir); err != nil || !info.IsDir() {
+			check.Status, check.Hint = doctorFail, "The directory does not exist or is not a directory: check SPECS_DIR"
+		}
+		return check
+	case cfg.Git != "":
+		check.Detail = fmt.Sprintf("git repository %s at %s", cfg.Git, cfg.Ref)
+		return check
+	}
+
+	details := []string{"bucket " + cfg.Bucket, "region " + cfg.Region}
+	if cfg.Prefix != "" {
+		details = append(details, "prefix "+cfg.Prefix)
+	}
+	if cfg.Endpoint != "" {
+		details = append(details, "endpoint "+cfg.Endpoint+" with path-style addressing")
+		if u, err := url.Parse(cfg.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			check.Status, check.Hint = doctorFail, "The endpoint must be a URL such as http://localhost:9000: check S3_ENDPOINT"
+		}
+	} else {
+		details = append(details, "AWS endpoint with virtual-hosted addressing")
+		if strings.Contains(cfg.Bucket, ".") {
+			check.Status, check.Hint = doctorWarn, "Bucket names with dots break TLS with virtual-hosted addressing; set S3_ENDPOINT to use path-style addressing"
+		}
+	}
+
+	switch {
+	case cfg.Auth.Profile != "":
+		details = append(details, "profile "+cfg.Auth.Profile)
+	case cfg.AccessKey != "":
+		details = append(details, "static keys")
+	default:
+		details = append(details, "default credential chain")
+	}
+	if cfg.Auth.RoleARN != "" {
+		details = append(details, "assuming "+cfg.Auth.RoleARN)
+	}
+	if file := cfg.Auth.WebIdentityTokenFile; file != "" {
+		if _, err := os.Stat(file); err != nil {
+			check.Status, check.Hint = doctorFail, "The web identity token file cannot be read: check S3_WEB_IDENTITY_TOKEN_FILE"
+		}
+	}
+	check.Detail = strings.Join(details, ", ")
+	return check
+}
+
+// parseCheck parses every spec file of a source
+func (s *Server) parseCheck(ctx context.Context, src source.Source, files []source.File) doctorCheck {
+	var openAPI, asyncAPI, other int
+	var problems []string
+	for _, file := range files {
+		ref := specRef{Source: src, Key: file.Key}
+		got, err := src.Get(ctx, file.Key, "")
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", file.Key, err))
+			continue
+		}
+		content := []byte(got.Content)
+
+		_, err = s.parseOpenAPI(ctx, ref, content)
+		if err == nil {
+			openAPI++
+			continue
+		}
+		if !errors.Is(err, openapi.ErrNotOpenAPI) {
+			problems = append(problems, fmt.Sprintf("%s: %v", file.Key, err))
+			continue
+		}
+
+		_, err = s.parseAsyncAPI(ctx, ref, content)
+		switch {
+		case err == nil:
+			asyncAPI++
+		case errors.Is(err, asyncapi.ErrNotAsyncAPI):
+			other++
+		default:
+			problems = append(problems, fmt.Sprintf("%s: %v", file.Key, err))
+		}
+	}
+
+	check := doctorCheck{Name: "Specs", Detail: fmt.Sprintf("%d OpenAPI and %d AsyncAPI document(s) parse", openAPI, asyncAPI)}
+	if other > 0 {
+		check.Detail += fmt.Sprintf(", %d YAML file(s) are neither", other)
+	}
+	if len(problems) > 0 {
+		check.Status = doctorFail
+		check.Detail = fmt.Sprintf("%d of %d file(s) failed to read or parse", len(problems), len(files))
+		if len(problems) > maxListedProblems {
+			problems = append(problems[:maxListedProblems], fmt.Sprintf("and %d more", len(problems)-maxListedProblems))
+		}
+		check.Hint = "Fix or remove: " + strings.Join(problems, "; ")
+	}
+	return check
+}
+
+// credentialsHint suggests how to provide credentials that resolve
+func credentialsHint(cfg config.Source) string {
+	switch {
+	case cfg.Auth.RoleARN != "":
+		return "Assuming the role failed: check that its trust policy allows these credentials, and the external ID (S3_EXTERNAL_ID) it expects"
+	case cfg.Auth.Profile != "":
+		return "Check that the profile exists in ~/.aws/config or ~/.aws/credentials, and run aws sso login for SSO profiles"
+	}
+	return "No credentials were found: set S3_PROFILE, or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, or run where an IAM role is available"
+}
+
+// errorHint suggests a fix for an error reading a source
+func errorHint(err error, cfg config.Source) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "AccessDenied", "Forbidden", "AllAccessDisabled":
+			return "The credentials lack permission: grant s3:ListBucket on the bucket and s3:GetObject on its objects"
+		case "NoSuchBucket", "NotFound":
+			return "The bucket was not found: check S3_BUCKET, and that S3_REGION and S3_ENDPOINT point where it lives"
+		case "PermanentRedirect", "MovedPermanently", "AuthorizationHeaderMalformed":
+			return fmt.Sprintf("The bucket is not in region %s: set S3_REGION to its region", cfg.Region)
+		case "InvalidAccessKeyId", "SignatureDoesNotMatch", "ExpiredToken", "InvalidToken":
+			return "The credentials were rejected: check the access keys, or refresh the session of the profile"
+		}
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if cfg.Endpoint == "" {
+			return "The bucket host name does not resolve: check S3_BUCKET and S3_REGION"
+		}
+		return "The endpoint host name does not resolve: check S3_ENDPOINT"
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return "The endpoint cannot be reached: check S3_ENDPOINT, proxies and network access"
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "The source did not answer in time: check network access"
+	}
+	if cfg.Git != "" {
+		return "Check that SPECS_GIT is a git repository and SPECS_GIT_REF a branch, tag or commit in it"
+	}
+	return ""
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/openapi"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/pkg/mcp"
+)
+
+// handleListErrorResponses handles the list_error_responses tool
+func (s *Server) handleListErrorResponses(ctx context.Context, request *mcp.RequestMessage, args map[string]interface{}) error {
+	path, _ := args["path"].(string)
+	method := ""
+	if m, ok := args["method"].(string); ok {
+		method = strings.ToUpper(m)
+	}
+
+	highlight := stringSliceArg(args, "highlight")
+	if len(highlight) == 0 {
+		highlight = s.config.HighlightFields
+	}
+
+	refs, err := s.specRefs(ctx, args, "")
+	if err != nil {
+		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to list YAML files: %v", err))
+	}
+
+	var resultText strings.Builder
+	found := 0
+	structured := errorListOutput{Operations: []errorOperationOutput{}}
+	for _, ref := range refs {
+		doc, err := s.loadSpec(ctx, ref)
+		if err != nil {
+			if len(refs) == 1 {
+				return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to load spec: %v", err))
+			}
+			s.skipSpec(ctx, ref, err)
+			continue
+		}
+
+		var specText strings.Builder
+		for _, op := range doc.Operations() {
+			if path != "" && !s.pathMatches(op.Path, path) {
+				continue
+			}
+			if method != "" && op.Method != method {
+				continue
+			}
+			responses := doc.ErrorResponses(op)
+			if len(responses) == 0 {
+				continue
+			}
+			found++
+			opOutput := errorOperationOutput{Source: ref.Source.Name(), Key: ref.Key, Method: op.Method, Path: op.Path, Errors: []errorResponseOutput{}}
+			specText.WriteString(fmt.Sprintf("🔍 **%s %s**\n", op.Method, op.Path))
+			for _, resp := range responses {
+				specText.WriteString(formatErrorResponse(resp, highlight))
+				opOutput.Errors = append(opOutput.Errors, newErrorResponseOutput(resp, highlight))
+			}
+			specText.WriteString("\n")
+			structured.Operations = append(structured.Operations, opOutput)
+		}
+
+		if specText.Len() > 0 {
+			resultText.WriteString(fmt.Sprintf("📄 **Found in %s**:\n%s", s.location(ref), specText.String()))
+		}
+	}
+
+	if found == 0 {
+		text := "❌ No error responses found"
+		if path != "" {
+			text += fmt.Sprintf(" for endpoints matching path '%s'", path)
+		}
+		if method != "" {
+			text += fmt.Sprintf(" with method %s", method)
+		}
+		return s.sendToolResult(request, args, text, structured)
+	}
+
+	structured.Count = found
+	return s.sendToolResult(request, args, fmt.Sprintf("⚠️ Error responses for %d operation(s):\n\n%s", found, resultText.String()), structured)
+}
+
+// formatErrorResponse renders an error response with its enumerated codes,
+// calling out highlighted fields
+func formatErrorResponse(resp openapi.ErrorResponse, highlight []string) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("   ❌ **%s**", resp.Status))
+	if resp.Description != "" {
+		b.WriteString(" — " + firstLine(resp.Description))
+	}
+	if resp.SchemaName != "" {
+		b.WriteString(fmt.Sprintf(" (schema `%s`)", resp.SchemaName))
+	}
+	b.WriteString("\n")
+
+	if len(resp.Fields) == 0 {
+		return b.String()
+	}
+
+	fields := make([]string, 0, len(resp.Fields))
+	for _, f := range resp.Fields {
+		if f.Type != "" {
+			fields = append(fields, fmt.Sprintf("%s (%s)", f.Path, f.Type))
+		} else {
+			fields = append(fields, f.Path)
+		}
+	}
+	b.WriteString(fmt.Sprintf("      Fields: %s\n", strings.Join(fields, ", ")))
+
+	for _, f := range resp.Fields {
+		marked := isHighlighted(f.Path, highlight)
+		if len(f.Enum) == 0 && !marked {
+			continue
+		}
+
+		icon := "🏷️"
+		if marked {
+			icon = "🔴"
+		}
+		b.WriteString(fmt.Sprintf("      %s `%s`", icon, f.Path))
+		if len(f.Enum) > 0 {
+			values := make([]string, 0, len(f.Enum))
+			for _, v := range f.Enum {
+				values = append(values, fmt.Sprint(v))
+			}
+			b.WriteString(": " + strings.Join(values, ", "))
+		}
+		if f.Description != "" {
+			b.WriteString(" — " + firstLine(f.Description))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// isHighlighted reports whether the last segment of a field path is one of
+// the highlight fields
+func isHighlighted(path string, highlight []string) bool {
+	name := path[strings.LastIndex(path, ".")+1:]
+	name = strings.TrimSuffix(name, "[]")
+	for _, h := range highlight {
+		if strings.EqualFold(name, h) || strings.EqualFold(path, h) {
+			return true
+		}
+	}
+	return false
+}
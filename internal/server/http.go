@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/metrics"
+)
+
+// readyTimeout bounds the source checks of a readiness probe
+const readyTimeout = 5 * time.Second
+
+// listenHTTP listens on addr and serves the HTTP endpoints there
+func (s *Server) listenHTTP(ctx context.Context, addr string) (func(), error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return s.serveHTTP(ctx, listener), nil
+}
+
+// serveHTTP serves /metrics, /healthz and /readyz on listener until ctx is
+// done or the returned function is called
+func (s *Server) serveHTTP(ctx context.Context, listener net.Listener) func() {
+	addr := listener.Addr().String()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.ErrorContext(ctx, "HTTP listener stopped", "addr", addr, "error", err)
+		}
+	}()
+	stop := context.AfterFunc(ctx, func() { srv.Close() })
+	s.logger.InfoContext(ctx, "Serving metrics and health checks", "url", "http://"+addr)
+
+	return func() {
+		stop()
+		srv.Close()
+	}
+}
+
+// handleHealthz reports that the process is up and serving
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, "ok")
+}
+
+// handleReadyz checks that every source can be read, answering 503 with
+// the failures otherwise
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+
+	var body strings.Builder
+	ready := true
+	for _, src := range s.sources {
+		if err := src.Check(ctx); err != nil {
+			ready = false
+			body.WriteString(fmt.Sprintf("fail %s: %v\n", src.Name(), err))
+			continue
+		}
+		body.WriteString(fmt.Sprintf("ok %s\n", src.Name()))
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	fmt.Fprint(w, body.String())
+}
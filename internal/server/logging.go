@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/logging"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/pkg/mcp"
+)
+
+// defaultClientLogLevel is the level from which log entries are forwarded to
+// the client until it sets one with logging/setLevel
+const defaultClientLogLevel = slog.LevelWarn
+
+// clientLogLevels maps the syslog levels of MCP to slog levels. slog has no
+// notice, critical, alert or emergency level; they sit between and above its
+// own.
+var clientLogLevels = map[string]slog.Level{
+	"debug":     slog.LevelDebug,
+	"info":      slog.LevelInfo,
+	"notice":    slog.LevelInfo + 2,
+	"warning":   slog.LevelWarn,
+	"error":     slog.LevelError,
+	"critical":  slog.LevelError + 4,
+	"alert":     slog.LevelError + 8,
+	"emergency": slog.LevelError + 12,
+}
+
+// clientLogLevel names level as an MCP log level
+func clientLogLevel(level slog.Level) string {
+	switch {
+	case level >= clientLogLevels["emergency"]:
+		return "emergency"
+	case level >= clientLogLevels["alert"]:
+		return "alert"
+	case level >= clientLogLevels["critical"]:
+		return "critical"
+	case level >= slog.LevelError:
+		return "error"
+	case level >= slog.LevelWarn:
+		return "warning"
+	case level >= clientLogLevels["notice"]:
+		return "notice"
+	case level >= slog.LevelInfo:
+		return "info"
+	}
+	return "debug"
+}
+
+// handleSetLogLevel handles the logging/setLevel request
+func (s *Server) handleSetLogLevel(request *mcp.RequestMessage) error {
+	var params mcp.SetLevelParams
+	if err := s.unmarshalParams(request.Params, &params); err != nil {
+		return s.sendError(request.ID, -32602, "Invalid params")
+	}
+
+	level, ok := clientLogLevels[params.Level]
+	if !ok {
+		return s.sendError(request.ID, -32602, fmt.Sprintf("Unknown log level: %s", params.Level))
+	}
+	s.clientLevel.Set(level)
+	return s.sendResponse(request.ID, struct{}{})
+}
+
+// clientHandler sends the entries logged while handling a request to the
+// client as notifications/message, on top of logging them with next.
+// Entries logged outside a request, such as at startup, are not sent: the
+// client may not have initialized yet.
+type clientHandler struct {
+	next   slog.Handler
+	server *Server
+	attrs  []slog.Attr
+	group  string
+}
+
+// newClientHandler wraps next so entries are also sent to the client of s
+func newClientHandler(next slog.Handler, s *Server) *clientHandler {
+	return &clientHandler{next: next, server: s}
+}
+
+// Enabled reports whether either the log or the client wants level
+func (h *clientHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.forwards(ctx, level) || h.next.Enabled(ctx, level)
+}
+
+// forwards reports whether an entry at level is sent to the client
+func (h *clientHandler) forwards(ctx context.Context, level slog.Level) bool {
+	return logging.FromContext(ctx) != nil && ctx.Value(localOnlyKey{}) == nil && level >= h.server.clientLevel.Level()
+}
+
+// localOnlyKey marks a context whose entries are not sent to the client
+type localOnlyKey struct{}
+
+// localOnly returns a context whose entries are logged but not sent to the
+// client, for entries such as request outcomes the client already has
+func localOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, localOnlyKey{}, true)
+}
+
+// Handle sends the entry to the client and passes it on
+func (h *clientHandler) Handle(ctx context.Context, record slog.Record) error {
+	if h.forwards(ctx, record.Level) {
+		data := map[string]interface{}{"message": record.Message}
+		for _, attr := range h.attrs {
+			addAttr(data, attr)
+		}
+		record.Attrs(func(attr slog.Attr) bool {
+			addAttr(data, h.qualify(attr))
+			return true
+		})
+		// A client that went away is noticed when the response is sent
+		_ = h.server.sendMessage(mcp.NewNotification("notifications/message", &mcp.LoggingMessageParams{
+			Level:  clientLogLevel(record.Level),
+			Logger: serverName,
+			Data:   data,
+		}))
+	}
+
+	if h.next.Enabled(ctx, record.Level) {
+		return h.next.Handle(ctx, record)
+	}
+	return nil
+}
+
+// WithAttrs keeps the attributes for the client and passes them on
+func (h *clientHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	derived := *h
+	derived.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, attr := range attrs {
+		derived.attrs = append(derived.attrs, h.qualify(attr))
+	}
+	derived.next = h.next.WithAttrs(attrs)
+	return &derived
+}
+
+// WithGroup prefixes later attribute keys sent to the client with name
+func (h *clientHandler) WithGroup(name string) slog.Handler {
+	derived := *h
+	derived.group = h.qualify(slog.String(name, "")).Key
+	derived.next = h.next.WithGroup(name)
+	return &derived
+}
+
+// qualify prefixes the key of attr with the open group
+func (h *clientHandler) qualify(attr slog.Attr) slog.Attr {
+	if h.group != "" {
+		attr.Key = h.group + "." + attr.Key
+	}
+	return attr
+}
+
+// addAttr sets attr in data as a JSON value. Errors are sent as their
+// message, which encoding/json would otherwise render as {}.
+func addAttr(data map[string]interface{}, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindGroup:
+		group := make(map[string]interface{})
+		for _, a := range value.Group() {
+			addAttr(group, a)
+		}
+		data[attr.Key] = group
+	case slog.KindDuration:
+		data[attr.Key] = value.Duration().String()
+	case slog.KindTime:
+		data[attr.Key] = value.Time().Format(time.RFC3339)
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			data[attr.Key] = err.Error()
+		} else {
+			data[attr.Key] = value.Any()
+		}
+	default:
+		data[attr.Key] = value.Any()
+	}
+}
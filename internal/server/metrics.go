@@ -0,0 +1,21 @@
+package server
+
+import (
+	"time"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/metrics"
+)
+
+// observeRequest records the outcome of the request in progress. Unknown
+// methods are labelled "unknown", as handleCallTool labels unknown tools,
+// so clients cannot add labels.
+func (s *Server) observeRequest(duration time.Duration, err error) {
+	method, tool := s.request.Method, s.request.Tool
+	if s.failure != nil && s.failure.Code == -32601 && tool == "" {
+		method = "unknown"
+	}
+
+	failed := err != nil || s.failure != nil
+	metrics.Requests.WithLabelValues(method, tool, metrics.Status(failed)).Inc()
+	metrics.RequestDuration.WithLabelValues(method, tool).Observe(duration.Seconds())
+}
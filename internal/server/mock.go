@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/mock"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/pkg/mcp"
+)
+
+// defaultMockAddr is where mock servers listen unless told otherwise
+const defaultMockAddr = "127.0.0.1:4010"
+
+// mockStopTimeout bounds the graceful shutdown of a mock server
+const mockStopTimeout = 5 * time.Second
+
+// ServeMock serves a mock of the spec at key in source, or in the one
+// source holding it when source is empty, until the context is cancelled
+func (s *Server) ServeMock(ctx context.Context, sourceName, key, addr string) error {
+	ref, err := s.specRefArg(ctx, map[string]interface{}{"source": sourceName}, key)
+	if err != nil {
+		return err
+	}
+	doc, err := s.loadSpec(ctx, ref)
+	if err != nil {
+		return fmt.Errorf("failed to load spec: %w", err)
+	}
+
+	srv, err := mock.Start(key, addr, doc)
+	if err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "Mock server listening", "key", key, "url", "http://"+srv.Addr)
+
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), mockStopTimeout)
+	defer cancel()
+	return srv.Stop(shutdownCtx)
+}
+
+// handleStartMockServer handles the start_mock_server tool
+func (s *Server) handleStartMockServer(ctx context.Context, request *mcp.RequestMessage, args map[string]interface{}) error {
+	key, ok := args["key"].(string)
+	if !ok {
+		return s.sendError(request.ID, -32602, "Key parameter is required and must be a string")
+	}
+
+	addr := defaultMockAddr
+	if a, ok := args["addr"].(string); ok && a != "" {
+		addr = a
+	}
+	if bound, ok := s.findMock(addr); ok {
+		return s.sendError(request.ID, -32602, fmt.Sprintf("A mock server for %s is already running on %s", s.mocks[bound].Key, bound))
+	}
+
+	ref, err := s.specRefArg(ctx, args, key)
+	if err != nil {
+		return s.sendError(request.ID, -32602, err.Error())
+	}
+	doc, err := s.loadSpec(ctx, ref)
+	if err != nil {
+		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to load spec: %v", err))
+	}
+
+	srv, err := mock.Start(key, addr, doc)
+	if err != nil {
+		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to start mock server: %v", err))
+	}
+	// Key by the bound address, which is the one reported and the one a
+	// port of 0 resolves to
+	s.mocks[srv.Addr] = srv
+	s.logger.InfoContext(ctx, "Mock server listening", "key", key, "url", "http://"+srv.Addr)
+
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("✅ Mock server for %s listening on http://%s\n\n", key, srv.Addr))
+	resultText.WriteString(fmt.Sprintf("Serving %d operation(s). Requests are validated against the contract;\n", len(doc.Operations())))
+	resultText.WriteString("send 'Prefer: code=404' to get a specific documented response.\n")
+
+	return s.sendText(request.ID, resultText.String())
+}
+
+// handleStopMockServer handles the stop_mock_server tool
+func (s *Server) handleStopMockServer(ctx context.Context, request *mcp.RequestMessage, args map[string]interface{}) error {
+	var addrs []string
+	if a, ok := args["addr"].(string); ok && a != "" {
+		bound, running := s.findMock(a)
+		if !running {
+			return s.sendError(request.ID, -32602, fmt.Sprintf("No mock server running on %s", a))
+		}
+		addrs = []string{bound}
+	} else {
+		for a := range s.mocks {
+			addrs = append(addrs, a)
+		}
+		sort.Strings(addrs)
+	}
+
+	if len(addrs) == 0 {
+		return s.sendText(request.ID, "No mock servers are running.\n")
+	}
+
+	var resultText strings.Builder
+	for _, a := range addrs {
+		srv := s.mocks[a]
+		if err := srv.Stop(ctx); err != nil {
+			s.logger.WarnContext(ctx, "Failed to stop mock server", "addr", a, "error", err)
+		}
+		delete(s.mocks, a)
+		resultText.WriteString(fmt.Sprintf("🛑 Stopped mock server for %s on %s\n", srv.Key, a))
+	}
+
+	return s.sendText(request.ID, resultText.String())
+}
+
+// findMock returns the bound address of the running mock server addr
+// refers to, comparing resolved addresses so that localhost:4010 finds the
+// server on 127.0.0.1:4010
+func (s *Server) findMock(addr string) (string, bool) {
+	if _, ok := s.mocks[addr]; ok {
+		return addr, true
+	}
+	want, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil || want.Port == 0 {
+		return "", false
+	}
+	for bound := range s.mocks {
+		got, err := net.ResolveTCPAddr("tcp", bound)
+		if err == nil && got.Port == want.Port && got.IP.Equal(want.IP) {
+			return bound, true
+		}
+	}
+	return "", false
+}
+
+// stopMocks stops the mock servers still running when the session ends, so
+// their listeners do not outlive the client
+func (s *Server) stopMocks(ctx context.Context) {
+	if len(s.mocks) == 0 {
+		return
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), mockStopTimeout)
+	defer cancel()
+	for addr, srv := range s.mocks {
+		if err := srv.Stop(shutdownCtx); err != nil {
+			s.logger.WarnContext(ctx, "Failed to stop mock server", "addr", addr, "error", err)
+		}
+		delete(s.mocks, addr)
+	}
+}
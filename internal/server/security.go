@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/openapi"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/pkg/mcp"
+)
+
+// handleGetEndpointAuth handles the get_endpoint_auth tool
+func (s *Server) handleGetEndpointAuth(ctx context.Context, request *mcp.RequestMessage, args map[string]interface{}) error {
+	path, ok := args["path"].(string)
+	if !ok {
+		return s.sendError(request.ID, -32602, "Path parameter is required and must be a string")
+	}
+
+	method := ""
+	if m, ok := args["method"].(string); ok {
+		method = strings.ToUpper(m)
+	}
+
+	refs, err := s.specRefs(ctx, args, "")
+	if err != nil {
+		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to list YAML files: %v", err))
+	}
+
+	var resultText strings.Builder
+	found := 0
+	structured := authListOutput{Path: path, Method: method, Endpoints: []endpointAuthOutput{}}
+	for _, ref := range refs {
+		doc, err := s.loadSpec(ctx, ref)
+		if err != nil {
+			if len(refs) == 1 {
+				return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to load spec: %v", err))
+			}
+			s.skipSpec(ctx, ref, err)
+			continue
+		}
+
+		summary, n := s.endpointAuthSummary(doc, path, method)
+		if n == 0 {
+			continue
+		}
+		found += n
+		resultText.WriteString(fmt.Sprintf("📄 **Found in %s**:\n%s\n", s.location(ref), summary))
+
+		for _, op := range doc.Operations() {
+			if s.pathMatches(op.Path, path) && (method == "" || op.Method == method) {
+				structured.Endpoints = append(structured.Endpoints, endpointAuthOutput{
+					Source: ref.Source.Name(),
+					Key:    ref.Key,
+					Method: op.Method,
+					Path:   op.Path,
+					Auth:   newAuthOutput(doc.ResolveAuth(op.Operation)),
+				})
+			}
+		}
+	}
+	structured.Count = found
+
+	if found == 0 {
+		text := fmt.Sprintf("❌ No endpoints found matching path '%s'", path)
+		if method != "" {
+			text += fmt.Sprintf(" with method %s", method)
+		}
+		return s.sendToolResult(request, args, text, structured)
+	}
+
+	return s.sendToolResult(request, args, resultText.String(), structured)
+}
+
+// endpointAuthSummary describes the auth of every operation matching the
+// path and method, returning the text and the number of operations
+func (s *Server) endpointAuthSummary(doc *openapi.Document, path, method string) (string, int) {
+	var b strings.Builder
+	count := 0
+	for _, op := range doc.Operations() {
+		if !s.pathMatches(op.Path, path) || (method != "" && op.Method != method) {
+			continue
+		}
+		count++
+		b.WriteString(fmt.Sprintf("🔐 **%s %s**\n", op.Method, op.Path))
+		b.WriteString(formatAuth(doc.ResolveAuth(op.Operation)))
+	}
+	return b.String(), count
+}
+
+// formatAuth renders resolved security requirements as Markdown
+func formatAuth(auth openapi.Auth) string {
+	var b strings.Builder
+
+	switch auth.Source {
+	case openapi.SecurityNone:
+		b.WriteString("   No authentication required\n")
+		return b.String()
+	case openapi.SecurityFromOperation:
+		if len(auth.Alternatives) == 0 {
+			b.WriteString("   No authentication required (operation-level `security: []` overrides global)\n")
+			return b.String()
+		}
+		b.WriteString("   Source: operation-level `security` (overrides global)\n")
+	default:
+		b.WriteString("   Source: global `security`\n")
+	}
+
+	if len(auth.Alternatives) > 1 {
+		b.WriteString("   Any one of the following:\n")
+	}
+	for i, alt := range auth.Alternatives {
+		indent := "   "
+		if len(auth.Alternatives) > 1 {
+			b.WriteString(fmt.Sprintf("   Option %d:\n", i+1))
+			indent = "      "
+		}
+		if len(alt.Schemes) == 0 {
+			b.WriteString(indent + "- Anonymous access\n")
+			continue
+		}
+		for _, scheme := range alt.Schemes {
+			b.WriteString(fmt.Sprintf("%s- `%s`: %s\n", indent, scheme.Name, scheme.Describe()))
+			for _, flow := range scheme.Flows() {
+				b.WriteString(fmt.Sprintf("%s  Flow: %s\n", indent, flow))
+			}
+			for _, scope := range scheme.Scopes {
+				if desc := scheme.ScopeDescription(scope); desc != "" {
+					b.WriteString(fmt.Sprintf("%s  Scope: `%s` — %s\n", indent, scope, desc))
+				} else {
+					b.WriteString(fmt.Sprintf("%s  Scope: `%s`\n", indent, scope))
+				}
+			}
+		}
+	}
+	return b.String()
+}
@@ -4,59 +4,116 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/asyncapi"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/budget"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/codegen"
 	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/config"
-	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/s3"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/logging"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/metrics"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/mock"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/openapi"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/source"
 	"github.com/ander-castiblanco-stori/s3-mcp-server/pkg/mcp"
 )
 
+// serverName is the name the server gives the client
+const serverName = "s3-yaml-mcp-server"
+
 // Server represents the MCP server
 type Server struct {
-	config   *config.Config
-	s3Client *s3.Client
-	reader   *bufio.Reader
-	writer   io.Writer
+	config  *config.Config
+	sources []source.Source
+	reader  *bufio.Reader
+	writer  io.Writer
+	mocks   map[string]*mock.Server
+
+	// logger logs to the configured destination and forwards entries at
+	// clientLevel and above to the client
+	logger      *slog.Logger
+	clientLevel slog.LevelVar
+
+	// requests counts the messages read, numbering their request IDs
+	requests uint64
+	// request is the request in progress, and failure the error response
+	// sent for it, if any
+	request *logging.Request
+	failure *mcp.ErrorObj
+
+	// budget is the output budget in characters of the tool call in progress
+	budget int
+
+	// protocolVersion is the MCP version agreed on in initialize
+	protocolVersion string
 }
 
-// New creates a new MCP server instance
-func New() (*Server, error) {
-	cfg := config.Load()
+// New creates a new MCP server instance serving over stdio
+func New(cfg *config.Config) (*Server, error) {
+	return newServer(cfg, os.Stdin, os.Stdout)
+}
 
+// newServer creates a server exchanging messages over in and out
+func newServer(cfg *config.Config, in io.Reader, out io.Writer) (*Server, error) {
 	// Validate required configuration
-	if cfg.S3Bucket == "" {
-		return nil, fmt.Errorf("S3_BUCKET environment variable is required")
+	if len(cfg.Sources) == 0 {
+		return nil, fmt.Errorf("S3_BUCKET, SPECS_DIR, SPECS_GIT or S3_SOURCES environment variable is required")
 	}
 
-	// Create S3 client
-	s3Client, err := s3.New(cfg.S3Region, cfg.S3Bucket, cfg.S3AccessKey, cfg.S3SecretKey, cfg.S3Endpoint)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	// Create a spec source per configured bucket or directory
+	var sources []source.Source
+	for _, sc := range cfg.Sources {
+		src, err := newSource(sc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create spec source: %w", err)
+		}
+		sources = append(sources, src)
 	}
 
-	return &Server{
-		config:   cfg,
-		s3Client: s3Client,
-		reader:   bufio.NewReader(os.Stdin),
-		writer:   os.Stdout,
-	}, nil
+	s := &Server{
+		config:  cfg,
+		sources: sources,
+		reader:  bufio.NewReader(in),
+		writer:  out,
+		mocks:   make(map[string]*mock.Server),
+
+		protocolVersion: protocolVersions[0],
+	}
+	s.clientLevel.Set(defaultClientLogLevel)
+	s.logger = slog.New(newClientHandler(slog.Default().Handler(), s))
+	return s, nil
 }
 
 // Start starts the MCP server
 func (s *Server) Start(ctx context.Context) error {
-	log.Printf("Starting S3 MCP Server - Bucket: %s, Region: %s", s.config.S3Bucket, s.config.S3Region)
+	if s.config.MetricsAddr != "" {
+		stop, err := s.listenHTTP(ctx, s.config.MetricsAddr)
+		if err != nil {
+			return err
+		}
+		defer stop()
+	}
+
+	for _, src := range s.sources {
+		s.logger.InfoContext(ctx, "Starting S3 MCP Server", "source", src.Name(), "root", src.Root())
 
-	// Test S3 connection
-	if err := s.s3Client.TestConnection(ctx); err != nil {
-		return fmt.Errorf("S3 connection test failed: %w", err)
+		// Test the connection to each source
+		if err := src.Check(ctx); err != nil {
+			return fmt.Errorf("connection test failed for source %s: %w", src.Name(), err)
+		}
 	}
 
-	log.Println("S3 connection successful")
-	log.Println("Server ready - listening for MCP messages...")
+	s.logger.InfoContext(ctx, "Source connections successful")
+	s.logger.InfoContext(ctx, "Server ready - listening for MCP messages...")
+	defer s.stopMocks(ctx)
 
 	// Main message processing loop
 	for {
@@ -66,10 +123,10 @@ func (s *Server) Start(ctx context.Context) error {
 		default:
 			if err := s.processMessage(ctx); err != nil {
 				if err == io.EOF {
-					log.Println("Client disconnected")
+					s.logger.InfoContext(ctx, "Client disconnected")
 					return nil
 				}
-				log.Printf("Error processing message: %v", err)
+				s.logger.ErrorContext(ctx, "Error processing message", "error", err)
 				continue
 			}
 		}
@@ -88,12 +145,45 @@ func (s *Server) processMessage(ctx context.Context) error {
 		return nil
 	}
 
+	s.requests++
+	s.request = &logging.Request{ID: strconv.FormatUint(s.requests, 10)}
+	s.failure = nil
+	ctx = logging.NewContext(ctx, s.request)
+	start := time.Now()
+
 	var request mcp.RequestMessage
 	if err := json.Unmarshal([]byte(line), &request); err != nil {
+		s.logger.WarnContext(localOnly(ctx), "Failed to parse message", "error", err)
 		return s.sendError(nil, -32700, "Parse error")
 	}
+	s.request.Method = request.Method
+
+	ctx, span := s.startRequestSpan(ctx, &request)
+	metrics.RequestsInFlight.Inc()
+	err = s.handleRequest(ctx, &request)
+	metrics.RequestsInFlight.Dec()
+
+	duration := time.Since(start)
+	s.logRequest(ctx, duration, err)
+	s.observeRequest(duration, err)
+	s.endRequestSpan(span, err)
+	return err
+}
 
-	return s.handleRequest(ctx, &request)
+// logRequest logs the outcome of the request in progress: errors sent back
+// as warnings, the rest at info level
+func (s *Server) logRequest(ctx context.Context, duration time.Duration, err error) {
+	attrs := []slog.Attr{slog.Float64("duration_ms", float64(duration.Microseconds())/1000)}
+	level := slog.LevelInfo
+	switch {
+	case err != nil:
+		level = slog.LevelError
+		attrs = append(attrs, slog.String("error", err.Error()))
+	case s.failure != nil:
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.Int("code", s.failure.Code), slog.String("error", s.failure.Message))
+	}
+	s.logger.LogAttrs(localOnly(ctx), level, "Request handled", attrs...)
 }
 
 // handleRequest handles an MCP request
@@ -111,6 +201,8 @@ func (s *Server) handleRequest(ctx context.Context, request *mcp.RequestMessage)
 		return s.handleListTools(request)
 	case "tools/call":
 		return s.handleCallTool(ctx, request)
+	case "logging/setLevel":
+		return s.handleSetLogLevel(request)
 	default:
 		return s.sendError(request.ID, -32601, fmt.Sprintf("Method not found: %s", request.Method))
 	}
@@ -118,8 +210,14 @@ func (s *Server) handleRequest(ctx context.Context, request *mcp.RequestMessage)
 
 // handleInitialize handles the initialize request
 func (s *Server) handleInitialize(request *mcp.RequestMessage) error {
+	var params mcp.InitializeParams
+	if err := s.unmarshalParams(request.Params, &params); err != nil {
+		return s.sendError(request.ID, -32602, "Invalid params")
+	}
+	s.protocolVersion = negotiateProtocol(params.ProtocolVersion)
+
 	result := &mcp.InitializeResult{
-		ProtocolVersion: "2024-11-05",
+		ProtocolVersion: s.protocolVersion,
 		Capabilities: mcp.ServerCapabilities{
 			Resources: &mcp.ResourceCapabilities{
 				Subscribe:   false,
@@ -128,9 +226,10 @@ func (s *Server) handleInitialize(request *mcp.RequestMessage) error {
 			Tools: &mcp.ToolCapabilities{
 				ListChanged: false,
 			},
+			Logging: &mcp.LoggingCapabilities{},
 		},
 		ServerInfo: mcp.ServerInfo{
-			Name:    "s3-yaml-mcp-server",
+			Name:    serverName,
 			Version: "1.0.0",
 		},
 	}
@@ -140,13 +239,13 @@ func (s *Server) handleInitialize(request *mcp.RequestMessage) error {
 
 // handleInitialized handles the initialized notification
 func (s *Server) handleInitialized(request *mcp.RequestMessage) error {
-	log.Println("Client initialized")
+	s.logger.Info("Client initialized")
 	return nil
 }
 
 // handleListResources lists all YAML resources in S3
 func (s *Server) handleListResources(ctx context.Context, request *mcp.RequestMessage) error {
-	files, err := s.s3Client.ListYAMLFiles(ctx, "")
+	files, err := s.listFiles(ctx, s.sources, "")
 	if err != nil {
 		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to list YAML files: %v", err))
 	}
@@ -154,10 +253,10 @@ func (s *Server) handleListResources(ctx context.Context, request *mcp.RequestMe
 	var resources []mcp.Resource
 	for _, file := range files {
 		resources = append(resources, mcp.Resource{
-			URI:         fmt.Sprintf("s3://%s/%s", s.config.S3Bucket, file.Key),
+			URI:         file.Source.URI(file.Key),
 			Name:        file.Name,
-			Description: fmt.Sprintf("Swagger/OpenAPI YAML documentation (Size: %d bytes, Modified: %s)", file.Size, file.LastModified),
-			MimeType:    "application/x-yaml",
+			Description: fmt.Sprintf("Swagger/OpenAPI documentation from source %s (Size: %d bytes, Modified: %s)", file.Source.Name(), file.Size, file.LastModified),
+			MimeType:    file.MimeType,
 		})
 	}
 
@@ -175,25 +274,80 @@ func (s *Server) handleReadResource(ctx context.Context, request *mcp.RequestMes
 		return s.sendError(request.ID, -32602, "Invalid params")
 	}
 
-	// Extract S3 key from URI
-	key := s.extractS3Key(params.URI)
-	if key == "" {
-		return s.sendError(request.ID, -32602, "Invalid S3 URI")
+	// Split off the optional ?format=, ?pointer= and budget selectors
+	uri, query := params.URI, url.Values{}
+	if i := strings.Index(uri, "?"); i >= 0 {
+		var err error
+		if query, err = url.ParseQuery(uri[i+1:]); err != nil {
+			return s.sendError(request.ID, -32602, "Invalid resource URI query")
+		}
+		uri = uri[:i]
 	}
-
-	file, err := s.s3Client.GetYAMLFile(ctx, key)
+	format, pointer, version := query.Get("format"), query.Get("pointer"), query.Get("version")
+	limit, err := s.resourceBudget(query)
 	if err != nil {
-		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to read file: %v", err))
+		return s.sendError(request.ID, -32602, err.Error())
+	}
+
+	// Find the source and key the URI points at
+	src, key := s.resolveURI(uri)
+	if src == nil {
+		return s.sendError(request.ID, -32602, "Invalid resource URI or source not configured")
+	}
+	ref := specRef{Source: src, Key: key, Version: version}
+
+	var content mcp.ResourceContent
+	switch format {
+	case "":
+		file, err := src.Get(ctx, key, version)
+		if err != nil {
+			return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to read file: %v", err))
+		}
+		content = mcp.ResourceContent{URI: params.URI, MimeType: file.MimeType, Text: file.Content}
+	case "markdown":
+		doc, err := s.loadSpec(ctx, ref)
+		if err != nil {
+			return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to load spec: %v", err))
+		}
+		hint := "Use get_endpoint_details for single operations, or read again with a larger max_chars."
+		text, _ := budget.Text(codegen.Markdown(doc), limit, hint)
+		content = mcp.ResourceContent{URI: params.URI, MimeType: "text/markdown", Text: text}
+	case "openapi3":
+		// Swagger 2.0 specs come back converted, OpenAPI 3 specs normalized
+		doc, err := s.loadSpec(ctx, ref)
+		if err != nil {
+			return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to load spec: %v", err))
+		}
+		data, err := openapi.Marshal(doc)
+		if err != nil {
+			return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to encode spec: %v", err))
+		}
+		content = mcp.ResourceContent{URI: params.URI, MimeType: "application/x-yaml", Text: string(data)}
+	default:
+		return s.sendError(request.ID, -32602, fmt.Sprintf("Unsupported resource format: %s", format))
+	}
+
+	// Spec views can be narrowed to a subtree and compacted to the budget;
+	// JSON specs come back as YAML once either applies
+	if format != "markdown" && (pointer != "" || (limit > 0 && len(content.Text) > limit)) {
+		data, err := openapi.ToYAML([]byte(content.Text))
+		if err != nil {
+			return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to parse spec: %v", err))
+		}
+		if pointer != "" {
+			if data, err = budget.Select(data, pointer); err != nil {
+				return s.sendError(request.ID, -32602, err.Error())
+			}
+		}
+		hint := fmt.Sprintf("Read collapsed sections with %s?pointer=<pointer>, or read again with a larger max_chars.", uri)
+		if data, _, err = budget.YAML(data, limit, hint); err != nil {
+			return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to compact spec: %v", err))
+		}
+		content.MimeType, content.Text = "application/x-yaml", string(data)
 	}
 
 	result := &mcp.ReadResourceResult{
-		Contents: []mcp.ResourceContent{
-			{
-				URI:      params.URI,
-				MimeType: "application/x-yaml",
-				Text:     file.Content,
-			},
-		},
+		Contents: []mcp.ResourceContent{content},
 	}
 
 	return s.sendResponse(request.ID, result)
@@ -204,7 +358,7 @@ func (s *Server) handleListTools(request *mcp.RequestMessage) error {
 	tools := []mcp.Tool{
 		{
 			Name:        "search_yaml_files",
-			Description: "Search for YAML files by name or content pattern",
+			Description: "Search for YAML and JSON spec files by name or content pattern",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
@@ -218,7 +372,7 @@ func (s *Server) handleListTools(request *mcp.RequestMessage) error {
 		},
 		{
 			Name:        "list_yaml_files",
-			Description: "List all YAML files in the S3 bucket with optional prefix filter",
+			Description: "List all YAML and JSON spec files in the S3 bucket with optional prefix filter",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
@@ -247,6 +401,325 @@ func (s *Server) handleListTools(request *mcp.RequestMessage) error {
 				"required": []string{"path"},
 			},
 		},
+		{
+			Name:        "get_endpoint_auth",
+			Description: "Explain what authentication an API endpoint needs: effective security requirements (global or operation override), API key header names, HTTP schemes and OAuth scopes",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "API endpoint path (e.g., '/cards/{id}', '/users')",
+					},
+					"method": map[string]interface{}{
+						"type":        "string",
+						"description": "HTTP method (GET, POST, PUT, DELETE, etc.)",
+					},
+					"key": map[string]interface{}{
+						"type":        "string",
+						"description": "S3 key of the spec (default: search every spec)",
+					},
+				},
+				"required": []string{"path"},
+			},
+		},
+		{
+			Name:        "list_error_responses",
+			Description: "List the 4xx, 5xx and default responses of each operation with their schemas and enumerated error codes, highlighting domain error fields",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "API endpoint path or part of it (default: every operation)",
+					},
+					"method": map[string]interface{}{
+						"type":        "string",
+						"description": "HTTP method (GET, POST, PUT, DELETE, etc.)",
+					},
+					"key": map[string]interface{}{
+						"type":        "string",
+						"description": "S3 key of the spec (default: search every spec)",
+					},
+					"highlight": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Error body fields to call out, e.g. [\"blocked_reason\"] (default: HIGHLIGHT_FIELDS)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "generate_request_snippet",
+			Description: "Generate a ready-to-run curl or HTTPie command for an operation in an OpenAPI spec, with parameters, auth headers and an example body filled in",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"key": map[string]interface{}{
+						"type":        "string",
+						"description": "S3 key of the OpenAPI spec",
+					},
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Exact API path as written in the spec (e.g., '/cards/{id}')",
+					},
+					"method": map[string]interface{}{
+						"type":        "string",
+						"description": "HTTP method (GET, POST, PUT, DELETE, PATCH)",
+					},
+					"server": map[string]interface{}{
+						"type":        "integer",
+						"description": "Index into the spec's servers list (default: 0)",
+					},
+					"format": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"curl", "httpie", "both"},
+						"description": "Snippet format (default: curl)",
+					},
+				},
+				"required": []string{"key", "path", "method"},
+			},
+		},
+		{
+			Name:        "generate_go_client",
+			Description: "Generate typed Go request/response structs and client methods for selected operations (or a whole OpenAPI spec)",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"key": map[string]interface{}{
+						"type":        "string",
+						"description": "S3 key of the OpenAPI spec",
+					},
+					"operations": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Optional operationIds or 'METHOD /path' selectors; all operations when omitted",
+					},
+					"package": map[string]interface{}{
+						"type":        "string",
+						"description": "Go package name for the generated code (default: client)",
+					},
+				},
+				"required": []string{"key"},
+			},
+		},
+		{
+			Name:        "generate_typescript_types",
+			Description: "Convert component schemas and operation payloads of an OpenAPI spec into TypeScript interfaces and union types",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"key": map[string]interface{}{
+						"type":        "string",
+						"description": "S3 key of the OpenAPI spec",
+					},
+					"schemas": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Optional component schema names to convert",
+					},
+					"operations": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Optional operationIds or 'METHOD /path' selectors whose payloads to convert",
+					},
+				},
+				"required": []string{"key"},
+			},
+		},
+		{
+			Name:        "render_markdown",
+			Description: "Render an OpenAPI spec as a Markdown API reference with a table of contents by tag, parameter tables, schemas and examples (also readable as the resource s3://bucket/key?format=markdown)",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"key": map[string]interface{}{
+						"type":        "string",
+						"description": "S3 key of the OpenAPI spec",
+					},
+				},
+				"required": []string{"key"},
+			},
+		},
+		{
+			Name:        "export_collection",
+			Description: "Export an OpenAPI spec, or all specs under a prefix, as a Postman v2.1 collection (also importable into Insomnia)",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"key": map[string]interface{}{
+						"type":        "string",
+						"description": "S3 key of a single OpenAPI spec",
+					},
+					"prefix": map[string]interface{}{
+						"type":        "string",
+						"description": "Export every spec under this prefix instead",
+					},
+				},
+			},
+		},
+		{
+			Name:        "start_mock_server",
+			Description: "Start a local HTTP mock server for an OpenAPI spec that returns example responses and validates requests against the contract",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"key": map[string]interface{}{
+						"type":        "string",
+						"description": "S3 key of the OpenAPI spec",
+					},
+					"addr": map[string]interface{}{
+						"type":        "string",
+						"description": "Listen address (default: 127.0.0.1:4010)",
+					},
+				},
+				"required": []string{"key"},
+			},
+		},
+		{
+			Name:        "stop_mock_server",
+			Description: "Stop a running mock server, or all of them when no address is given",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"addr": map[string]interface{}{
+						"type":        "string",
+						"description": "Listen address of the mock server to stop",
+					},
+				},
+			},
+		},
+		{
+			Name:        "list_deprecated",
+			Description: "Report every operation, parameter, schema and property marked deprecated or carrying an x-sunset style extension, grouped by spec and sorted by sunset date",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"key": map[string]interface{}{
+						"type":        "string",
+						"description": "S3 key of a single spec (default: every spec in the bucket)",
+					},
+					"prefix": map[string]interface{}{
+						"type":        "string",
+						"description": "Only scan specs under this key prefix",
+					},
+				},
+			},
+		},
+		{
+			Name:        "list_channels",
+			Description: "List the channels of AsyncAPI event contracts with the messages on each and whether the service publishes or subscribes",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"key": map[string]interface{}{
+						"type":        "string",
+						"description": "S3 key of an AsyncAPI document (default: every AsyncAPI document in the bucket)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "get_message_schema",
+			Description: "Get the payload and header schemas of the messages on an AsyncAPI channel, with references expanded",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"channel": map[string]interface{}{
+						"type":        "string",
+						"description": "Channel name or address (e.g. user.signedup)",
+					},
+					"message": map[string]interface{}{
+						"type":        "string",
+						"description": "Message name (default: all messages on the channel)",
+					},
+					"key": map[string]interface{}{
+						"type":        "string",
+						"description": "S3 key of an AsyncAPI document (default: search every AsyncAPI document)",
+					},
+				},
+				"required": []string{"channel"},
+			},
+		},
+		{
+			Name:        "find_channel_participants",
+			Description: "Find which services publish to or subscribe to a channel across all AsyncAPI documents in the bucket",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"channel": map[string]interface{}{
+						"type":        "string",
+						"description": "Channel name or address, or a part of it",
+					},
+				},
+				"required": []string{"channel"},
+			},
+		},
+		{
+			Name:        "list_spec_versions",
+			Description: "List the stored versions of a spec file, such as S3 object versions, with how to read each one",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"key": map[string]interface{}{
+						"type":        "string",
+						"description": "Key of the spec file",
+					},
+				},
+				"required": []string{"key"},
+			},
+		},
+		{
+			Name:        "compare_spec",
+			Description: "Compare a spec with another copy of it, such as a git working branch against what is published in S3, listing breaking and other API changes",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"key": map[string]interface{}{
+						"type":        "string",
+						"description": "Key of the spec file to compare, read from source",
+					},
+					"version": map[string]interface{}{
+						"type":        "string",
+						"description": "Version of the spec to compare, such as a git branch, tag or commit (default: current)",
+					},
+					"against": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the source holding the copy to compare against, such as the published bucket (default: the same source)",
+					},
+					"against_key": map[string]interface{}{
+						"type":        "string",
+						"description": "Key of the copy to compare against (default: key)",
+					},
+					"against_version": map[string]interface{}{
+						"type":        "string",
+						"description": "Version of the copy to compare against (default: current)",
+					},
+				},
+				"required": []string{"key"},
+			},
+		},
+	}
+
+	// Tools with structured results declare an output schema and accept
+	// format; every tool accepts a source and an output budget
+	outputSchemas := toolOutputSchemas()
+	for i := range tools {
+		properties := tools[i].InputSchema["properties"].(map[string]interface{})
+		if schema, ok := outputSchemas[tools[i].Name]; ok {
+			if s.structuredOutput() {
+				tools[i].OutputSchema = schema
+			}
+			properties["format"] = formatProperty
+		}
+		if tools[i].Name != "stop_mock_server" {
+			properties["source"] = sourceProperty
+		}
+		properties["max_chars"] = maxCharsProperty
+		properties["max_tokens"] = maxTokensProperty
+		if tools[i].Name == "list_yaml_files" || tools[i].Name == "search_yaml_files" {
+			properties["offset"] = offsetProperty
+		}
 	}
 
 	result := &mcp.ListToolsResult{
@@ -256,23 +729,60 @@ func (s *Server) handleListTools(request *mcp.RequestMessage) error {
 	return s.sendResponse(request.ID, result)
 }
 
+// toolHandlers maps the name of every tool to its handler
+var toolHandlers = map[string]func(*Server, context.Context, *mcp.RequestMessage, map[string]interface{}) error{
+	"search_yaml_files":         (*Server).handleSearchYAMLFiles,
+	"list_yaml_files":           (*Server).handleListYAMLFilesTool,
+	"get_endpoint_details":      (*Server).handleGetEndpointDetails,
+	"get_endpoint_auth":         (*Server).handleGetEndpointAuth,
+	"list_error_responses":      (*Server).handleListErrorResponses,
+	"generate_request_snippet":  (*Server).handleGenerateRequestSnippet,
+	"generate_go_client":        (*Server).handleGenerateGoClient,
+	"generate_typescript_types": (*Server).handleGenerateTypeScriptTypes,
+	"render_markdown":           (*Server).handleRenderMarkdown,
+	"export_collection":         (*Server).handleExportCollection,
+	"start_mock_server":         (*Server).handleStartMockServer,
+	"stop_mock_server":          (*Server).handleStopMockServer,
+	"list_deprecated":           (*Server).handleListDeprecated,
+	"list_channels":             (*Server).handleListChannels,
+	"get_message_schema":        (*Server).handleGetMessageSchema,
+	"find_channel_participants": (*Server).handleFindChannelParticipants,
+	"list_spec_versions":        (*Server).handleListSpecVersions,
+	"compare_spec":              (*Server).handleCompareSpec,
+}
+
 // handleCallTool handles tool execution
 func (s *Server) handleCallTool(ctx context.Context, request *mcp.RequestMessage) error {
 	var params mcp.CallToolParams
 	if err := s.unmarshalParams(request.Params, &params); err != nil {
 		return s.sendError(request.ID, -32602, "Invalid params")
 	}
-
-	switch params.Name {
-	case "search_yaml_files":
-		return s.handleSearchYAMLFiles(ctx, request, params.Arguments)
-	case "list_yaml_files":
-		return s.handleListYAMLFilesTool(ctx, request, params.Arguments)
-	case "get_endpoint_details":
-		return s.handleGetEndpointDetails(ctx, request, params.Arguments)
-	default:
+	// Only registered names become labels, so clients cannot add them
+	handler, ok := toolHandlers[params.Name]
+	if req := logging.FromContext(ctx); req != nil {
+		req.Tool = "unknown"
+		if ok {
+			req.Tool = params.Name
+		}
+	}
+	if !ok {
 		return s.sendError(request.ID, -32601, fmt.Sprintf("Unknown tool: %s", params.Name))
 	}
+
+	limit, err := s.toolBudget(params.Arguments)
+	if err != nil {
+		return s.sendError(request.ID, -32602, err.Error())
+	}
+	s.budget = limit
+	defer func() { s.budget = 0 }()
+
+	if name, ok := params.Arguments["source"].(string); ok && name != "" {
+		if _, err := s.findSource(name); err != nil {
+			return s.sendError(request.ID, -32602, err.Error())
+		}
+	}
+
+	return handler(s, ctx, request, params.Arguments)
 }
 
 // handleSearchYAMLFiles handles the search_yaml_files tool
@@ -282,32 +792,41 @@ func (s *Server) handleSearchYAMLFiles(ctx context.Context, request *mcp.Request
 		return s.sendError(request.ID, -32602, "Pattern parameter is required and must be a string")
 	}
 
-	files, err := s.s3Client.SearchYAMLFiles(ctx, pattern)
+	sources, err := s.sourcesArg(args)
+	if err != nil {
+		return s.sendError(request.ID, -32602, err.Error())
+	}
+
+	listed, err := s.listFiles(ctx, sources, "")
 	if err != nil {
 		return s.sendError(request.ID, -32603, fmt.Sprintf("Search failed: %v", err))
 	}
 
+	var files []sourceFile
+	for _, file := range listed {
+		if source.Matches(file.File, pattern) {
+			files = append(files, file)
+		}
+	}
+
+	offset, err := intArg(args, "offset")
+	if err != nil {
+		return s.sendError(request.ID, -32602, err.Error())
+	}
+
 	var resultText strings.Builder
 	resultText.WriteString(fmt.Sprintf("Found %d YAML files matching pattern '%s':\n\n", len(files), pattern))
 
-	for _, file := range files {
-		resultText.WriteString(fmt.Sprintf("📄 **%s**\n", file.Name))
-		resultText.WriteString(fmt.Sprintf("   - S3 Key: %s\n", file.Key))
-		resultText.WriteString(fmt.Sprintf("   - Size: %d bytes\n", file.Size))
-		resultText.WriteString(fmt.Sprintf("   - Modified: %s\n", file.LastModified))
-		resultText.WriteString(fmt.Sprintf("   - URI: s3://%s/%s\n\n", s.config.S3Bucket, file.Key))
+	shown, next := s.writeFileList(&resultText, files, offset)
+	structured := fileListOutput{Pattern: pattern, Count: len(files), Offset: offset, NextOffset: next, Files: []fileOutput{}}
+	if next > 0 {
+		structured.truncation = newTruncation(len(files), next)
 	}
-
-	result := &mcp.ToolResult{
-		Content: []mcp.ToolContent{
-			{
-				Type: "text",
-				Text: resultText.String(),
-			},
-		},
+	for _, file := range shown {
+		structured.Files = append(structured.Files, newFileOutput(file))
 	}
 
-	return s.sendResponse(request.ID, result)
+	return s.sendToolResult(request, args, resultText.String(), structured)
 }
 
 // handleListYAMLFilesTool handles the list_yaml_files tool
@@ -316,8 +835,17 @@ func (s *Server) handleListYAMLFilesTool(ctx context.Context, request *mcp.Reque
 	if p, ok := args["prefix"].(string); ok {
 		prefix = p
 	}
+	offset, err := intArg(args, "offset")
+	if err != nil {
+		return s.sendError(request.ID, -32602, err.Error())
+	}
+
+	sources, err := s.sourcesArg(args)
+	if err != nil {
+		return s.sendError(request.ID, -32602, err.Error())
+	}
 
-	files, err := s.s3Client.ListYAMLFiles(ctx, prefix)
+	files, err := s.listFiles(ctx, sources, prefix)
 	if err != nil {
 		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to list files: %v", err))
 	}
@@ -329,24 +857,16 @@ func (s *Server) handleListYAMLFilesTool(ctx context.Context, request *mcp.Reque
 		resultText.WriteString(fmt.Sprintf("Found %d YAML files in bucket:\n\n", len(files)))
 	}
 
-	for _, file := range files {
-		resultText.WriteString(fmt.Sprintf("📄 **%s**\n", file.Name))
-		resultText.WriteString(fmt.Sprintf("   - S3 Key: %s\n", file.Key))
-		resultText.WriteString(fmt.Sprintf("   - Size: %d bytes\n", file.Size))
-		resultText.WriteString(fmt.Sprintf("   - Modified: %s\n", file.LastModified))
-		resultText.WriteString(fmt.Sprintf("   - URI: s3://%s/%s\n\n", s.config.S3Bucket, file.Key))
+	shown, next := s.writeFileList(&resultText, files, offset)
+	structured := fileListOutput{Prefix: prefix, Count: len(files), Offset: offset, NextOffset: next, Files: []fileOutput{}}
+	if next > 0 {
+		structured.truncation = newTruncation(len(files), next)
 	}
-
-	result := &mcp.ToolResult{
-		Content: []mcp.ToolContent{
-			{
-				Type: "text",
-				Text: resultText.String(),
-			},
-		},
+	for _, file := range shown {
+		structured.Files = append(structured.Files, newFileOutput(file))
 	}
 
-	return s.sendResponse(request.ID, result)
+	return s.sendToolResult(request, args, resultText.String(), structured)
 }
 
 // handleGetEndpointDetails handles the get_endpoint_details tool
@@ -361,26 +881,49 @@ func (s *Server) handleGetEndpointDetails(ctx context.Context, request *mcp.Requ
 		method = strings.ToUpper(m)
 	}
 
+	sources, err := s.sourcesArg(args)
+	if err != nil {
+		return s.sendError(request.ID, -32602, err.Error())
+	}
+
 	// Get all YAML files
-	files, err := s.s3Client.ListYAMLFiles(ctx, "")
+	files, err := s.listFiles(ctx, sources, "")
 	if err != nil {
 		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to list YAML files: %v", err))
 	}
 
 	var resultText strings.Builder
 	var foundEndpoints []string
+	structured := endpointListOutput{Path: path, Method: method, Endpoints: []endpointOutput{}}
 
 	// Search through each YAML file
 	for _, file := range files {
-		yamlFile, err := s.s3Client.GetYAMLFile(ctx, file.Key)
+		yamlFile, err := file.Source.Get(ctx, file.Key, "")
+		if err != nil {
+			s.logger.WarnContext(ctx, "Failed to read file", "source", file.Source.Name(), "key", file.Key, "error", err)
+			continue
+		}
+
+		// JSON specs are scanned through their YAML rendering
+		content, err := openapi.ToYAML([]byte(yamlFile.Content))
 		if err != nil {
-			log.Printf("Failed to read file %s: %v", file.Key, err)
+			s.logger.WarnContext(ctx, "Failed to convert file", "source", file.Source.Name(), "key", file.Key, "error", err)
 			continue
 		}
 
-		endpointInfo := s.searchEndpointInContent(yamlFile.Content, path, method, file.Name)
+		endpointInfo := s.searchEndpointInContent(string(content), path, method, file.Name)
 		if endpointInfo != "" {
-			foundEndpoints = append(foundEndpoints, fmt.Sprintf("📄 **Found in %s**:\n%s\n", file.Name, endpointInfo))
+			if doc, err := s.parseOpenAPI(ctx, specRef{Source: file.Source, Key: file.Key}, content); err == nil {
+				if auth, n := s.endpointAuthSummary(doc, path, method); n > 0 {
+					endpointInfo += "\n" + auth
+				}
+				for _, op := range doc.Operations() {
+					if s.pathMatches(op.Path, path) && (method == "" || op.Method == method) {
+						structured.Endpoints = append(structured.Endpoints, newEndpointOutput(specRef{Source: file.Source, Key: file.Key}, doc, op))
+					}
+				}
+			}
+			foundEndpoints = append(foundEndpoints, fmt.Sprintf("📄 **Found in %s**%s:\n%s\n", file.Name, s.sourceSuffix(file.Source), endpointInfo))
 		}
 	}
 
@@ -403,16 +946,8 @@ func (s *Server) handleGetEndpointDetails(ctx context.Context, request *mcp.Requ
 		}
 	}
 
-	result := &mcp.ToolResult{
-		Content: []mcp.ToolContent{
-			{
-				Type: "text",
-				Text: resultText.String(),
-			},
-		},
-	}
-
-	return s.sendResponse(request.ID, result)
+	structured.Count = len(structured.Endpoints)
+	return s.sendToolResult(request, args, resultText.String(), structured)
 }
 
 // searchEndpointInContent searches for endpoint details in YAML content
@@ -481,7 +1016,7 @@ func (s *Server) searchEndpointInContent(content, searchPath, method, fileName s
 				strings.Contains(trimmed, "responses:") ||
 				strings.Contains(trimmed, "requestBody:") ||
 				strings.Contains(trimmed, "parameters:") ||
-				strings.Contains(trimmed, "blocked_reason") ||
+				s.highlighted(trimmed, s.config.HighlightFields) ||
 				strings.Contains(trimmed, "schema:") ||
 				strings.Contains(trimmed, "$ref:") ||
 				strings.Contains(trimmed, "type:") ||
@@ -617,9 +1152,9 @@ func (s *Server) formatEndpointDetails(path, method string, details []string) st
 
 	if len(responses) > 0 {
 		result.WriteString("   📥 Responses:\n")
-		// Highlight lines containing blocked_reason
+		// Highlight lines mentioning configured fields
 		for _, r := range responses {
-			if strings.Contains(strings.ToLower(r), "blocked_reason") {
+			if s.highlighted(r, s.config.HighlightFields) {
 				result.WriteString(fmt.Sprintf("   🔴 %s\n", r))
 			} else {
 				result.WriteString(fmt.Sprintf("   %s\n", r))
@@ -632,15 +1167,43 @@ func (s *Server) formatEndpointDetails(path, method string, details []string) st
 
 // Helper methods
 
+// highlighted reports whether a line mentions one of the highlight fields
+func (s *Server) highlighted(line string, fields []string) bool {
+	line = strings.ToLower(line)
+	for _, field := range fields {
+		if strings.Contains(line, strings.ToLower(field)) {
+			return true
+		}
+	}
+	return false
+}
+
 // sendResponse sends a successful response
 func (s *Server) sendResponse(id interface{}, result interface{}) error {
+	if toolResult, ok := result.(*mcp.ToolResult); ok {
+		s.fitToolResult(toolResult)
+	}
 	response := mcp.NewResponseMessage(id, result)
 	return s.sendMessage(response)
 }
 
+// sendText sends a tool result with a single text block
+func (s *Server) sendText(id interface{}, text string) error {
+	result := &mcp.ToolResult{
+		Content: []mcp.ToolContent{
+			{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+	return s.sendResponse(id, result)
+}
+
 // sendError sends an error response
 func (s *Server) sendError(id interface{}, code int, message string) error {
 	response := mcp.NewErrorResponse(id, code, message)
+	s.failure = response.Error
 	return s.sendMessage(response)
 }
 
@@ -669,12 +1232,21 @@ func (s *Server) unmarshalParams(params interface{}, target interface{}) error {
 	return json.Unmarshal(data, target)
 }
 
-// extractS3Key extracts the S3 key from an S3 URI
-func (s *Server) extractS3Key(uri string) string {
-	// Remove s3:// prefix and bucket name
-	prefix := fmt.Sprintf("s3://%s/", s.config.S3Bucket)
-	if strings.HasPrefix(uri, prefix) {
-		return strings.TrimPrefix(uri, prefix)
+// skipSpec logs a spec left out of a prefix-wide tool call. Documents of
+// the other kind, such as AsyncAPI files next to OpenAPI specs, are expected
+// under a shared prefix and skipped quietly.
+func (s *Server) skipSpec(ctx context.Context, ref specRef, err error) {
+	if errors.Is(err, openapi.ErrNotOpenAPI) || errors.Is(err, asyncapi.ErrNotAsyncAPI) {
+		return
+	}
+	s.logger.WarnContext(ctx, "Skipping spec", "source", ref.Source.Name(), "key", ref.Key, "error", err)
+}
+
+// loadSpec downloads and parses an OpenAPI spec from its source
+func (s *Server) loadSpec(ctx context.Context, ref specRef) (*openapi.Document, error) {
+	file, err := ref.Source.Get(ctx, ref.Key, ref.Version)
+	if err != nil {
+		return nil, err
 	}
-	return ""
+	return s.parseOpenAPI(ctx, ref, []byte(file.Content))
 }
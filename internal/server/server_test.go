@@ -0,0 +1,949 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/config"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/logging"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/metrics"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/s3/s3test"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/pkg/mcp"
+)
+
+// rpcCase is one JSON-RPC exchange with the server
+type rpcCase struct {
+	name   string
+	method string
+	params interface{}
+	// raw is sent verbatim instead of a request built from method and params
+	raw string
+
+	// wantCode is the expected JSON-RPC error code, zero for success
+	wantCode int
+	// want and notWant are matched against the text of the result
+	want    []string
+	notWant []string
+	// structured expects a tool result with structuredContent
+	structured bool
+}
+
+// rpcResponse is a decoded response line
+type rpcResponse struct {
+	ID     interface{}     `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func tool(name string, args map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"name": name, "arguments": args}
+}
+
+func resource(uri string) map[string]interface{} {
+	return map[string]interface{}{"uri": uri}
+}
+
+var rpcCases = []rpcCase{
+	// Protocol
+	{name: "initialize", method: "initialize", params: map[string]interface{}{"protocolVersion": "2025-06-18"}, want: []string{`"protocolVersion":"2025-06-18"`, "s3-yaml-mcp-server"}},
+	{name: "unknown method", method: "prompts/list", wantCode: -32601},
+	{name: "parse error", raw: "{not json", wantCode: -32700},
+	{name: "set log level", method: "logging/setLevel", params: map[string]interface{}{"level": "warning"}},
+	{name: "set unknown log level", method: "logging/setLevel", params: map[string]interface{}{"level": "loud"}, wantCode: -32602},
+	{name: "tools list", method: "tools/list", want: []string{"search_yaml_files", "compare_spec", "outputSchema"}},
+
+	// Resources
+	{name: "resources list", method: "resources/list", want: []string{"s3://specs/cards.yaml", "s3://specs/events/mailer-v3.yaml", "application/x-yaml"}},
+	{name: "read raw", method: "resources/read", params: resource("s3://specs/cards.yaml"), want: []string{"title: Cards API", "version: 1.3.0"}},
+	{name: "read version", method: "resources/read", params: resource("s3://specs/cards.yaml?version=v1"), want: []string{"version: 1.2.0"}, notWant: []string{"1.3.0"}},
+	{name: "read markdown", method: "resources/read", params: resource("s3://specs/cards.yaml?format=markdown"), want: []string{"# Cards API", "createCard"}},
+	{name: "read swagger as openapi3", method: "resources/read", params: resource("s3://specs/petstore2.yaml?format=openapi3"), want: []string{"openapi: 3.0", "/v2"}},
+	{name: "read pointer", method: "resources/read", params: resource("s3://specs/cards.yaml?pointer=/info"), want: []string{"title: Cards API"}, notWant: []string{"paths:"}},
+	{name: "read within budget", method: "resources/read", params: resource("s3://specs/cards.yaml?max_chars=600"), want: []string{"collapsed"}},
+	{name: "read within small budget", method: "resources/read", params: resource("s3://specs/cards.yaml?max_chars=100"), want: []string{"openapi: 3.0.3", "Truncated"}},
+	{name: "read unknown format", method: "resources/read", params: resource("s3://specs/cards.yaml?format=pdf"), wantCode: -32602},
+	{name: "read missing key", method: "resources/read", params: resource("s3://specs/missing.yaml"), wantCode: -32603},
+	{name: "read unknown uri", method: "resources/read", params: resource("s3://elsewhere/cards.yaml"), wantCode: -32602},
+
+	// Tools
+	{name: "search", method: "tools/call", params: tool("search_yaml_files", map[string]interface{}{"pattern": "CARD"}), want: []string{"cards.yaml"}, notWant: []string{"petstore2.yaml"}, structured: true},
+	{name: "list", method: "tools/call", params: tool("list_yaml_files", nil), want: []string{"cards.yaml", "petstore2.yaml", "events/users-v2.yaml", "events/mailer-v3.yaml"}, structured: true},
+	{name: "list prefix", method: "tools/call", params: tool("list_yaml_files", map[string]interface{}{"prefix": "events/"}), want: []string{"mailer-v3.yaml"}, notWant: []string{"cards.yaml"}, structured: true},
+	{name: "list json format", method: "tools/call", params: tool("list_yaml_files", map[string]interface{}{"format": "json"}), want: []string{`"count": 4`}, structured: true},
+	{name: "list within budget", method: "tools/call", params: tool("list_yaml_files", map[string]interface{}{"max_chars": 600}), want: []string{"offset="}, structured: true},
+	{name: "list bad budget", method: "tools/call", params: tool("list_yaml_files", map[string]interface{}{"max_chars": -1}), wantCode: -32602},
+	{name: "endpoint details", method: "tools/call", params: tool("get_endpoint_details", map[string]interface{}{"path": "/cards", "method": "POST"}), want: []string{"POST /cards", "Create card", "cards.yaml"}, structured: true},
+	{name: "endpoint details missing path", method: "tools/call", params: tool("get_endpoint_details", map[string]interface{}{}), wantCode: -32602},
+	{name: "endpoint auth", method: "tools/call", params: tool("get_endpoint_auth", map[string]interface{}{"path": "/cards", "method": "POST"}), want: []string{"apiKey", "X-API-Key"}, structured: true},
+	{name: "error responses", method: "tools/call", params: tool("list_error_responses", map[string]interface{}{"path": "/cards", "method": "POST"}), want: []string{"422", "blocked_reason"}, structured: true},
+	{name: "request snippet", method: "tools/call", params: tool("generate_request_snippet", map[string]interface{}{"key": "cards.yaml", "path": "/cards", "method": "GET"}), want: []string{"curl", "https://sandbox.api.example.com/v1/cards"}},
+	{name: "go client", method: "tools/call", params: tool("generate_go_client", map[string]interface{}{"key": "cards.yaml"}), want: []string{"type Card struct", "func (c *Client) CreateCard"}},
+	{name: "typescript types", method: "tools/call", params: tool("generate_typescript_types", map[string]interface{}{"key": "cards.yaml"}), want: []string{"export interface NewCard"}},
+	{name: "render markdown", method: "tools/call", params: tool("render_markdown", map[string]interface{}{"key": "petstore2.yaml"}), want: []string{"# Pets", "uploadPhoto"}},
+	{name: "export collection", method: "tools/call", params: tool("export_collection", map[string]interface{}{"key": "petstore2.yaml"}), want: []string{"schema.getpostman.com", "listPets"}},
+	{name: "export collection missing key", method: "tools/call", params: tool("export_collection", map[string]interface{}{"key": "missing.yaml"}), wantCode: -32603},
+	{name: "start mock", method: "tools/call", params: tool("start_mock_server", map[string]interface{}{"key": "cards.yaml", "addr": "127.0.0.1:0"}), want: []string{"Mock server for cards.yaml listening", "Serving 3 operation(s)"}},
+	{name: "stop mock", method: "tools/call", params: tool("stop_mock_server", nil), want: []string{"Stopped mock server for cards.yaml"}},
+	{name: "stop mock when none", method: "tools/call", params: tool("stop_mock_server", nil), want: []string{"No mock servers are running"}},
+	{name: "deprecated", method: "tools/call", params: tool("list_deprecated", nil), want: []string{"GET /cards/{card_id}", "2025-06-01"}, structured: true},
+	{name: "channels", method: "tools/call", params: tool("list_channels", nil), want: []string{"user/signedup", "user/deleted"}, structured: true},
+	{name: "message schema", method: "tools/call", params: tool("get_message_schema", map[string]interface{}{"channel": "user/signedup"}), want: []string{"UserSignedUp", "email"}, structured: true},
+	{name: "channel participants", method: "tools/call", params: tool("find_channel_participants", map[string]interface{}{"channel": "user/signedup"}), want: []string{"User Service", "Mailer"}, structured: true},
+	{name: "spec versions", method: "tools/call", params: tool("list_spec_versions", map[string]interface{}{"key": "cards.yaml"}), want: []string{"2 version(s)", "`v2`", "(current)", "`v1`"}, structured: true},
+	{name: "compare versions", method: "tools/call", params: tool("compare_spec", map[string]interface{}{"key": "cards.yaml", "against_version": "v1"}), want: []string{"1 breaking", "GET /cards query parameter 'limit'", "now required", "1.2.0 → 1.3.0"}, structured: true},
+	{name: "compare with itself", method: "tools/call", params: tool("compare_spec", map[string]interface{}{"key": "cards.yaml"}), wantCode: -32602},
+	{name: "unknown source", method: "tools/call", params: tool("list_yaml_files", map[string]interface{}{"source": "nope"}), wantCode: -32602},
+	{name: "unknown tool", method: "tools/call", params: tool("delete_bucket", nil), wantCode: -32601},
+}
+
+// setupFakeS3 points the configuration at a fake bucket holding the specs
+// under testdata. cards.yaml gets a second version that makes the limit
+// parameter required.
+func setupFakeS3(t *testing.T) *s3test.Server {
+	t.Helper()
+	fake := s3test.NewServer()
+	t.Cleanup(fake.Close)
+
+	err := filepath.WalkDir("testdata", func(p string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel("testdata", p)
+		fake.Put("specs", filepath.ToSlash(rel), string(content))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("loading testdata: %v", err)
+	}
+
+	cards, err := os.ReadFile("testdata/cards.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2 := strings.Replace(string(cards), "version: 1.2.0", "version: 1.3.0", 1)
+	v2 = strings.Replace(v2, "        - name: limit\n          in: query\n", "        - name: limit\n          in: query\n          required: true\n", 1)
+	fake.Put("specs", "cards.yaml", v2)
+
+	env := map[string]string{
+		"S3_BUCKET":                   "specs",
+		"S3_ENDPOINT":                 fake.URL,
+		"S3_REGION":                   "us-east-1",
+		"AWS_ACCESS_KEY_ID":           "test",
+		"AWS_SECRET_ACCESS_KEY":       "test",
+		"AWS_CONFIG_FILE":             filepath.Join(t.TempDir(), "config"),
+		"AWS_SHARED_CREDENTIALS_FILE": filepath.Join(t.TempDir(), "credentials"),
+		"XDG_CONFIG_HOME":             t.TempDir(),
+	}
+	for _, name := range []string{"S3_PREFIX", "S3_SOURCES", "SPECS_DIR", "SPECS_GIT", "MAX_OUTPUT_CHARS", "HIGHLIGHT_FIELDS", "AWS_PROFILE", "S3_PROFILE", "S3_ROLE_ARN", "S3_WEB_IDENTITY_TOKEN_FILE"} {
+		env[name] = ""
+	}
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+	return fake
+}
+
+func loadConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg, err := config.Load(config.Options{})
+	if err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+	return cfg
+}
+
+// runSession sends the lines to a server over its JSON-RPC loop and returns
+// the responses by request ID
+func runSession(t *testing.T, lines []string) map[float64]rpcResponse {
+	t.Helper()
+	responses := make(map[float64]rpcResponse)
+	for _, resp := range runMessages(t, lines) {
+		if resp.Method != "" {
+			continue
+		}
+		id, _ := resp.ID.(float64)
+		responses[id] = resp
+	}
+	return responses
+}
+
+// runMessages sends the lines to a server over its JSON-RPC loop and
+// returns every message it sent back, notifications included, in order
+func runMessages(t *testing.T, lines []string) []rpcResponse {
+	t.Helper()
+	var out bytes.Buffer
+	srv, err := newServer(loadConfig(t), strings.NewReader(strings.Join(lines, "\n")+"\n"), &out)
+	if err != nil {
+		t.Fatalf("newServer: %v", err)
+	}
+	if err := srv.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	var messages []rpcResponse
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var resp rpcResponse
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("invalid response %q: %v", line, err)
+		}
+		messages = append(messages, resp)
+	}
+	return messages
+}
+
+// resultText flattens a result into the text a client would show
+func resultText(raw json.RawMessage) string {
+	var result struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+		Contents []struct {
+			Text string `json:"text"`
+		} `json:"contents"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return string(raw)
+	}
+	var texts []string
+	for _, c := range result.Content {
+		texts = append(texts, c.Text)
+	}
+	for _, c := range result.Contents {
+		texts = append(texts, c.Text)
+	}
+	if len(texts) == 0 {
+		return string(raw)
+	}
+	return strings.Join(texts, "\n")
+}
+
+func TestServerSession(t *testing.T) {
+	setupFakeS3(t)
+
+	// Requests are numbered from 1; the parse error comes back with a null
+	// ID, read as 0. The initialized notification gets no response.
+	lines := []string{`{"jsonrpc":"2.0","method":"initialized"}`}
+	ids := make(map[string]float64)
+	for i, tc := range rpcCases {
+		if tc.raw != "" {
+			lines = append(lines, tc.raw)
+			continue
+		}
+		id := float64(i + 1)
+		ids[tc.name] = id
+		line, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": tc.method, "params": tc.params})
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, string(line))
+	}
+
+	responses := runSession(t, lines)
+	if len(responses) != len(rpcCases) {
+		t.Errorf("got %d responses for %d requests", len(responses), len(rpcCases))
+	}
+
+	for _, tc := range rpcCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, ok := responses[ids[tc.name]]
+			if !ok {
+				t.Fatal("no response")
+			}
+			if tc.wantCode != 0 {
+				if resp.Error == nil || resp.Error.Code != tc.wantCode {
+					t.Fatalf("error = %+v, want code %d", resp.Error, tc.wantCode)
+				}
+				return
+			}
+			if resp.Error != nil {
+				t.Fatalf("unexpected error %d: %s", resp.Error.Code, resp.Error.Message)
+			}
+
+			text := resultText(resp.Result)
+			for _, want := range tc.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("result does not contain %q:\n%s", want, text)
+				}
+			}
+			for _, notWant := range tc.notWant {
+				if strings.Contains(text, notWant) {
+					t.Errorf("result contains %q:\n%s", notWant, text)
+				}
+			}
+			if hasStructured := strings.Contains(string(resp.Result), `"structuredContent"`); hasStructured != tc.structured {
+				t.Errorf("structuredContent present = %v, want %v", hasStructured, tc.structured)
+			}
+		})
+	}
+}
+
+// TestEveryToolCovered keeps rpcCases in step with the advertised tools
+func TestEveryToolCovered(t *testing.T) {
+	setupFakeS3(t)
+	responses := runSession(t, []string{`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`})
+
+	var result struct {
+		Tools []struct {
+			Name         string                 `json:"name"`
+			OutputSchema map[string]interface{} `json:"outputSchema"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(responses[1].Result, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	called := make(map[string]bool)
+	structured := make(map[string]bool)
+	for _, tc := range rpcCases {
+		if params, ok := tc.params.(map[string]interface{}); ok && tc.method == "tools/call" && tc.wantCode == 0 {
+			name, _ := params["name"].(string)
+			called[name] = true
+			structured[name] = structured[name] || tc.structured
+		}
+	}
+	for _, tool := range result.Tools {
+		if !called[tool.Name] {
+			t.Errorf("tool %s has no successful test case", tool.Name)
+		}
+		if (tool.OutputSchema != nil) != structured[tool.Name] {
+			t.Errorf("tool %s declares an output schema = %v, but its cases expect structured content = %v", tool.Name, tool.OutputSchema != nil, structured[tool.Name])
+		}
+	}
+}
+
+func TestServerProtocolVersion(t *testing.T) {
+	setupFakeS3(t)
+
+	tests := []struct {
+		requested  string
+		agreed     string
+		structured bool
+	}{
+		{requested: "2025-06-18", agreed: "2025-06-18", structured: true},
+		{requested: "2025-03-26", agreed: "2025-03-26"},
+		{requested: "2024-11-05", agreed: "2024-11-05"},
+		{requested: "2099-01-01", agreed: "2025-06-18", structured: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.requested, func(t *testing.T) {
+			responses := runSession(t, []string{
+				`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"` + tt.requested + `"}}`,
+				`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+				`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"list_yaml_files","arguments":{"format":"json"}}}`,
+			})
+
+			if agreed := `"protocolVersion":"` + tt.agreed + `"`; !strings.Contains(string(responses[1].Result), agreed) {
+				t.Errorf("initialize = %s, want %s", responses[1].Result, agreed)
+			}
+			if got := strings.Contains(string(responses[2].Result), `"outputSchema"`); got != tt.structured {
+				t.Errorf("tools/list has output schemas = %v, want %v", got, tt.structured)
+			}
+			if got := strings.Contains(string(responses[3].Result), `"structuredContent"`); got != tt.structured {
+				t.Errorf("tool result has structuredContent = %v, want %v", got, tt.structured)
+			}
+			if text := resultText(responses[3].Result); !strings.Contains(text, `"count": 4`) {
+				t.Errorf("JSON text = %s", text)
+			}
+		})
+	}
+}
+
+func TestServerStructuredBudget(t *testing.T) {
+	setupFakeS3(t)
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		// list is the array of structuredContent checked for cut entries
+		list string
+	}{
+		{name: "list_yaml_files", args: map[string]interface{}{"max_chars": 600}, list: "files"},
+		{name: "get_endpoint_details", args: map[string]interface{}{"path": "/cards", "max_chars": 1500}, list: "endpoints"},
+		{name: "list_deprecated", args: map[string]interface{}{"max_chars": 100}, list: "specs"},
+		{name: "compare_spec", args: map[string]interface{}{"key": "cards.yaml", "against_version": "v1", "max_chars": 400, "format": "json"}, list: "changes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": tool(tt.name, tt.args)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp := runSession(t, []string{string(line)})[1]
+			if resp.Error != nil {
+				t.Fatalf("unexpected error %d: %s", resp.Error.Code, resp.Error.Message)
+			}
+
+			var result struct {
+				StructuredContent json.RawMessage `json:"structuredContent"`
+			}
+			if err := json.Unmarshal(resp.Result, &result); err != nil {
+				t.Fatal(err)
+			}
+			if limit := tt.args["max_chars"].(int); len(result.StructuredContent) > limit {
+				t.Errorf("structuredContent has %d characters, over the budget of %d:\n%s", len(result.StructuredContent), limit, result.StructuredContent)
+			}
+
+			var structured map[string]interface{}
+			if err := json.Unmarshal(result.StructuredContent, &structured); err != nil {
+				t.Fatal(err)
+			}
+			if structured["truncated"] != true || structured["omitted"] == nil {
+				t.Errorf("structuredContent not marked truncated: %s", result.StructuredContent)
+			}
+			if _, ok := structured[tt.list].([]interface{}); !ok {
+				t.Errorf("structuredContent lacks the %s list: %s", tt.list, result.StructuredContent)
+			}
+			if tt.args["format"] == "json" && !strings.Contains(resultText(resp.Result), `"truncated": true`) {
+				t.Errorf("JSON text does not match the truncated structure:\n%s", resultText(resp.Result))
+			}
+		})
+	}
+}
+
+func TestServerBudgetKeepsPayloadsWhole(t *testing.T) {
+	setupFakeS3(t)
+
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		mimeType string
+	}{
+		{name: "export_collection", args: map[string]interface{}{"key": "petstore2.yaml"}, mimeType: "application/json"},
+		{name: "generate_go_client", args: map[string]interface{}{"key": "cards.yaml"}, mimeType: "text/x-go"},
+		{name: "generate_typescript_types", args: map[string]interface{}{"key": "cards.yaml"}, mimeType: "text/x-typescript"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budgeted := map[string]interface{}{"max_chars": 200}
+			for k, v := range tt.args {
+				budgeted[k] = v
+			}
+			var lines []string
+			for i, args := range []map[string]interface{}{tt.args, budgeted} {
+				line, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": i + 1, "method": "tools/call", "params": tool(tt.name, args)})
+				if err != nil {
+					t.Fatal(err)
+				}
+				lines = append(lines, string(line))
+			}
+			responses := runSession(t, lines)
+
+			payload := func(id float64) string {
+				t.Helper()
+				var result mcp.ToolResult
+				if err := json.Unmarshal(responses[id].Result, &result); err != nil {
+					t.Fatal(err)
+				}
+				for _, content := range result.Content {
+					if content.MimeType == tt.mimeType {
+						return content.Text
+					}
+				}
+				t.Fatalf("no %s block in %s", tt.mimeType, responses[id].Result)
+				return ""
+			}
+			full, cut := payload(1), payload(2)
+			if len(full) <= 200 || cut != full {
+				t.Errorf("budgeted payload has %d of %d characters", len(cut), len(full))
+			}
+			if tt.mimeType == "application/json" && !json.Valid([]byte(cut)) {
+				t.Errorf("budgeted export is not valid JSON:\n%s", cut)
+			}
+		})
+	}
+}
+
+// TestServerGitSource serves a git clone holding the first version of
+// cards.yaml next to the bucket, which has the second
+func TestServerGitSource(t *testing.T) {
+	setupFakeS3(t)
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	cards, err := os.ReadFile("testdata/cards.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "cards.yaml"), cards, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet", "--initial-branch=main"},
+		{"add", "cards.yaml"},
+		{"-c", "user.name=Ana", "-c", "user.email=ana@example.com", "commit", "--quiet", "--message", "Add cards"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", args[0], err, out)
+		}
+	}
+	t.Setenv("SPECS_GIT", repo)
+	t.Setenv("SPECS_GIT_REF", "main")
+
+	responses := runSession(t, []string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"list_yaml_files","arguments":{"source":"git"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"list_spec_versions","arguments":{"source":"git","key":"cards.yaml"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"compare_spec","arguments":{"source":"git","key":"cards.yaml","against":"default"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"compare_spec","arguments":{"source":"git","key":"cards.yaml","against":"default","against_version":"v1"}}}`,
+	})
+
+	wants := map[float64][]string{
+		1: {"Found 1 YAML files", "git+file://" + filepath.ToSlash(repo) + "/cards.yaml"},
+		2: {"1 version(s)", "Add cards", "(current)"},
+		3: {"1.3.0 → 1.2.0", "'limit'"},
+		4: {"identical"},
+	}
+	for id, want := range wants {
+		resp := responses[id]
+		if resp.Error != nil {
+			t.Errorf("request %v: unexpected error %d: %s", id, resp.Error.Code, resp.Error.Message)
+			continue
+		}
+		text := resultText(resp.Result)
+		for _, w := range want {
+			if !strings.Contains(text, w) {
+				t.Errorf("request %v: result does not contain %q:\n%s", id, w, text)
+			}
+		}
+	}
+}
+
+// TestServerKeyAcrossSources reads keys without naming a source while a
+// local directory sits next to the bucket
+func TestServerKeyAcrossSources(t *testing.T) {
+	setupFakeS3(t)
+	dir := t.TempDir()
+	cards, err := os.ReadFile("testdata/cards.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"cards.yaml", "accounts.yaml"} {
+		if err := os.WriteFile(filepath.Join(dir, name), cards, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("SPECS_DIR", dir)
+
+	generate := `{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":"generate_go_client","arguments":{%s"key":"%s"}}}`
+	responses := runSession(t, []string{
+		fmt.Sprintf(generate, 1, "", "accounts.yaml"),
+		fmt.Sprintf(generate, 2, "", "petstore2.yaml"),
+		fmt.Sprintf(generate, 3, `"source":"local",`, "cards.yaml"),
+		fmt.Sprintf(generate, 4, "", "cards.yaml"),
+		fmt.Sprintf(generate, 5, "", "missing.yaml"),
+	})
+
+	for _, id := range []float64{1, 2, 3} {
+		if resp := responses[id]; resp.Error != nil {
+			t.Errorf("request %v: unexpected error %d: %s", id, resp.Error.Code, resp.Error.Message)
+		}
+	}
+	wants := map[float64]string{4: "exists in several sources (default, local)", 5: "missing.yaml not found in any source"}
+	for id, want := range wants {
+		if resp := responses[id]; resp.Error == nil || !strings.Contains(resp.Error.Message, want) {
+			t.Errorf("request %v = %+v, want an error containing %q", id, resp.Error, want)
+		}
+	}
+}
+
+func TestServerSourceCheck(t *testing.T) {
+	setupFakeS3(t)
+	t.Setenv("S3_BUCKET", "missing")
+
+	srv, err := newServer(loadConfig(t), strings.NewReader(""), &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("newServer: %v", err)
+	}
+	if err := srv.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "connection test failed for source default") {
+		t.Errorf("Start error = %v", err)
+	}
+}
+
+func TestServerStopsMocks(t *testing.T) {
+	setupFakeS3(t)
+
+	var out bytes.Buffer
+	srv, err := newServer(loadConfig(t), strings.NewReader(
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"start_mock_server","arguments":{"key":"cards.yaml","addr":"127.0.0.1:0"}}}`+"\n"), &out)
+	if err != nil {
+		t.Fatalf("newServer: %v", err)
+	}
+	if err := srv.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	_, rest, found := strings.Cut(out.String(), "listening on http://")
+	addr, _, _ := strings.Cut(rest, "\\n")
+	if !found || addr == "" {
+		t.Fatalf("no mock server address in %s", &out)
+	}
+	if len(srv.mocks) != 0 {
+		t.Errorf("%d mock server(s) still registered", len(srv.mocks))
+	}
+	if conn, err := net.Dial("tcp", addr); err == nil {
+		conn.Close()
+		t.Errorf("mock server on %s still accepts connections after EOF", addr)
+	}
+}
+
+func TestServerStopMockByAddress(t *testing.T) {
+	setupFakeS3(t)
+
+	start := `{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":"start_mock_server","arguments":{"key":"cards.yaml","addr":"127.0.0.1:0"}}}`
+	stop := `{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":"stop_mock_server","arguments":{"addr":%q}}}`
+
+	// Start a mock on a free port to learn an address, then stop it by the
+	// reported address and by a host name resolving to it
+	responses := runSession(t, []string{fmt.Sprintf(start, 1)})
+	_, rest, _ := strings.Cut(resultText(responses[1].Result), "listening on http://")
+	addr, _, _ := strings.Cut(rest, "\n")
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("no mock server address in %s", responses[1].Result)
+	}
+
+	responses = runSession(t, []string{
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"start_mock_server","arguments":{"key":"cards.yaml","addr":%q}}}`, addr),
+		fmt.Sprintf(stop, 2, addr),
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"start_mock_server","arguments":{"key":"cards.yaml","addr":%q}}}`, addr),
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"start_mock_server","arguments":{"key":"cards.yaml","addr":%q}}}`, "localhost:"+port),
+		fmt.Sprintf(stop, 5, "localhost:"+port),
+		fmt.Sprintf(stop, 6, addr),
+	})
+
+	if text := resultText(responses[2].Result); !strings.Contains(text, "Stopped mock server for cards.yaml on "+addr) {
+		t.Errorf("stop by reported address = %s", text)
+	}
+	if resp := responses[4]; resp.Error == nil || !strings.Contains(resp.Error.Message, "already running on "+addr) {
+		t.Errorf("second start through localhost = %+v", resp.Error)
+	}
+	if text := resultText(responses[5].Result); !strings.Contains(text, "Stopped mock server for cards.yaml on "+addr) {
+		t.Errorf("stop through localhost = %s", text)
+	}
+	if resp := responses[6]; resp.Error == nil || !strings.Contains(resp.Error.Message, "No mock server running") {
+		t.Errorf("stop after stopping = %s", resp.Result)
+	}
+}
+
+func TestServerRequestLogs(t *testing.T) {
+	setupFakeS3(t)
+
+	var out, logs bytes.Buffer
+	srv, err := newServer(loadConfig(t), strings.NewReader(strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"list_yaml_files","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"export_collection","arguments":{"key":"missing.yaml"}}}`,
+		`not json`,
+	}, "\n")+"\n"), &out)
+	if err != nil {
+		t.Fatalf("newServer: %v", err)
+	}
+	srv.logger, err = logging.NewWriter(&logs, slog.LevelInfo, "json")
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	if err := srv.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	var handled, parseErrors []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid log entry %q: %v", line, err)
+		}
+		switch entry["msg"] {
+		case "Request handled":
+			handled = append(handled, entry)
+		case "Failed to parse message":
+			parseErrors = append(parseErrors, entry)
+		}
+	}
+	if len(handled) != 2 {
+		t.Fatalf("logged %d handled requests, want 2:\n%s", len(handled), logs.String())
+	}
+
+	first, second := handled[0], handled[1]
+	if first["level"] != "INFO" || first["request_id"] != "1" || first["method"] != "tools/call" || first["tool"] != "list_yaml_files" {
+		t.Errorf("first request entry = %v", first)
+	}
+	if calls, _ := first["s3_calls"].(float64); calls < 1 {
+		t.Errorf("first request made %v S3 calls, want at least 1", first["s3_calls"])
+	}
+	if _, ok := first["duration_ms"].(float64); !ok {
+		t.Errorf("first request entry has no duration: %v", first)
+	}
+	if second["level"] != "WARN" || second["request_id"] != "2" || second["code"] != float64(-32603) {
+		t.Errorf("second request entry = %v", second)
+	}
+	if len(parseErrors) != 1 || parseErrors[0]["request_id"] != "3" {
+		t.Errorf("parse error entries = %v", parseErrors)
+	}
+}
+
+func TestServerLogNotifications(t *testing.T) {
+	fake := setupFakeS3(t)
+	fake.Put("specs", "broken.yaml", "openapi: 3.0.3\npaths: [1, 2]\n")
+	listDeprecated := `{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":"list_deprecated","arguments":{}}}`
+
+	messages := runMessages(t, []string{
+		fmt.Sprintf(listDeprecated, 1),
+		`{"jsonrpc":"2.0","id":2,"method":"logging/setLevel","params":{"level":"error"}}`,
+		fmt.Sprintf(listDeprecated, 3),
+	})
+
+	var notifications []mcp.LoggingMessageParams
+	for i, msg := range messages {
+		if msg.Method == "" {
+			continue
+		}
+		if msg.Method != "notifications/message" {
+			t.Fatalf("unexpected notification %s", msg.Method)
+		}
+		if next := messages[i+1]; next.Method == "" && next.ID != float64(1) {
+			t.Errorf("notification sent before the response to request %v, want before request 1", next.ID)
+		}
+		var params mcp.LoggingMessageParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			t.Fatal(err)
+		}
+		notifications = append(notifications, params)
+	}
+
+	// The AsyncAPI event specs of the fake bucket are skipped quietly
+	if len(notifications) != 1 {
+		t.Fatalf("got %d notifications, want one for the broken spec: %+v", len(notifications), notifications)
+	}
+	data, _ := notifications[0].Data.(map[string]interface{})
+	if n := notifications[0]; n.Level != "warning" || n.Logger != serverName || data["message"] != "Skipping spec" || data["key"] != "broken.yaml" || data["error"] == "" {
+		t.Errorf("notification = %+v", n)
+	}
+}
+
+func TestServerMetrics(t *testing.T) {
+	fake := setupFakeS3(t)
+	fake.Put("specs", "events.json", `{"asyncapi": "2.6.0"}`)
+	t.Setenv("METRICS_ADDR", "127.0.0.1:0")
+
+	runSession(t, []string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"list_yaml_files","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"s3://specs/cards.yaml"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"drop_tables","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"drop/tables"}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"drop_views","arguments":{"max_chars":"lots"}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"list_yaml_files","arguments":{}}}`,
+	})
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`s3mcp_requests_total{method="tools/call",status="ok",tool="list_yaml_files"}`,
+		`s3mcp_requests_total{method="tools/call",status="error",tool="unknown"}`,
+		`s3mcp_requests_total{method="unknown",status="error",tool=""}`,
+		`s3mcp_request_duration_seconds_count{method="resources/read",tool=""}`,
+		`s3mcp_requests_in_flight 0`,
+		`s3mcp_s3_calls_total{operation="ListObjectsV2",status="ok"}`,
+		`s3mcp_s3_received_bytes_total{operation="GetObject"}`,
+		`s3mcp_cache_lookups_total{cache="s3_sniff",result="hit"}`,
+		`s3mcp_cache_lookups_total{cache="s3_sniff",result="miss"}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %s", want)
+		}
+	}
+	for _, notWant := range []string{"drop_tables", "drop_views", "drop/tables", `s3mcp_s3_received_bytes_total{operation="HeadObject"}`} {
+		if strings.Contains(body, notWant) {
+			t.Errorf("metrics contain %s", notWant)
+		}
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	setupFakeS3(t)
+	srv, err := newServer(loadConfig(t), strings.NewReader(""), &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("newServer: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := srv.serveHTTP(context.Background(), listener)
+	defer stop()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "s3mcp_requests_in_flight") {
+		t.Errorf("GET /metrics = %d:\n%s", resp.StatusCode, body)
+	}
+
+	for path, want := range map[string]string{"/healthz": "ok", "/readyz": "ok default"} {
+		resp, err := http.Get("http://" + listener.Addr().String() + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || strings.TrimSpace(string(body)) != want {
+			t.Errorf("GET %s = %d:\n%s", path, resp.StatusCode, body)
+		}
+	}
+
+	if _, err := srv.listenHTTP(context.Background(), "bad address"); err == nil {
+		t.Error("listenHTTP accepted an invalid address")
+	}
+}
+
+func TestServerNotReady(t *testing.T) {
+	setupFakeS3(t)
+	t.Setenv("S3_BUCKET", "missing")
+	srv, err := newServer(loadConfig(t), strings.NewReader(""), &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("newServer: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.handleReadyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable || !strings.HasPrefix(rec.Body.String(), "fail default: ") {
+		t.Errorf("GET /readyz = %d:\n%s", rec.Code, rec.Body)
+	}
+	rec = httptest.NewRecorder()
+	srv.handleHealthz(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /healthz = %d", rec.Code)
+	}
+}
+
+func TestServerDoctor(t *testing.T) {
+	setupFakeS3(t)
+	srv, err := newServer(loadConfig(t), strings.NewReader(""), &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("newServer: %v", err)
+	}
+	var out bytes.Buffer
+	healthy := srv.Doctor(context.Background(), &out)
+	if !healthy {
+		t.Errorf("Doctor failed against the fake bucket:\n%s", &out)
+	}
+	for _, want := range []string{"✅ Settings: bucket specs, region us-east-1, endpoint http://", "path-style addressing", "✅ Credentials: resolved from", "✅ List: ", "✅ Specs: "} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Doctor output lacks %q:\n%s", want, &out)
+		}
+	}
+
+	t.Setenv("S3_BUCKET", "missing")
+	srv, err = newServer(loadConfig(t), strings.NewReader(""), &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("newServer: %v", err)
+	}
+	out.Reset()
+	if srv.Doctor(context.Background(), &out) {
+		t.Errorf("Doctor passed for a missing bucket:\n%s", &out)
+	}
+	if !strings.Contains(out.String(), "❌ Reachable: ") || !strings.Contains(out.String(), "→ The bucket was not found: check S3_BUCKET") || strings.Contains(out.String(), "List:") {
+		t.Errorf("Doctor output for a missing bucket:\n%s", &out)
+	}
+
+	t.Setenv("S3_ENDPOINT", "localhost:9000")
+	srv, err = newServer(loadConfig(t), strings.NewReader(""), &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("newServer: %v", err)
+	}
+	out.Reset()
+	if srv.Doctor(context.Background(), &out) || !strings.Contains(out.String(), "→ The endpoint must be a URL") {
+		t.Errorf("Doctor output for an endpoint without a scheme:\n%s", &out)
+	}
+}
+
+func TestServerTracing(t *testing.T) {
+	setupFakeS3(t)
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	runSession(t, []string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_endpoint_details","arguments":{"path":"/cards","method":"POST"},"_meta":{"traceparent":"00-` + traceID + `-00f067aa0ba902b7-01"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"export_collection","arguments":{"key":"missing.yaml"}}}`,
+	})
+
+	spans := recorder.Ended()
+	byName := make(map[string][]sdktrace.ReadOnlySpan)
+	for _, span := range spans {
+		byName[span.Name()] = append(byName[span.Name()], span)
+	}
+
+	details := byName["tools/call get_endpoint_details"]
+	if len(details) != 1 {
+		t.Fatalf("got spans %v, want one for get_endpoint_details", names(spans))
+	}
+	request := details[0]
+	if request.SpanContext().TraceID().String() != traceID || request.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("request span is not a child of the client span: trace %s, parent %s", request.SpanContext().TraceID(), request.Parent().SpanID())
+	}
+	for _, name := range []string{"S3.ListObjectsV2", "S3.GetObject", "parse openapi"} {
+		found := false
+		for _, span := range byName[name] {
+			if span.Parent().SpanID() == request.SpanContext().SpanID() {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("no %s span under the request span; got %v", name, names(spans))
+		}
+	}
+
+	export := byName["tools/call export_collection"]
+	if len(export) != 1 || export[0].Status().Code != codes.Error {
+		t.Errorf("export_collection span = %+v, want one failed span", export)
+	}
+	if export[0].SpanContext().TraceID().String() == traceID {
+		t.Error("second request continued the trace of the first")
+	}
+}
+
+// names lists the names of spans
+func names(spans []sdktrace.ReadOnlySpan) []string {
+	var out []string
+	for _, span := range spans {
+		out = append(out, span.Name())
+	}
+	return out
+}
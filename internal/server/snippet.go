@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/codegen"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/pkg/mcp"
+)
+
+// handleGenerateRequestSnippet handles the generate_request_snippet tool
+func (s *Server) handleGenerateRequestSnippet(ctx context.Context, request *mcp.RequestMessage, args map[string]interface{}) error {
+	key, ok := args["key"].(string)
+	if !ok {
+		return s.sendError(request.ID, -32602, "Key parameter is required and must be a string")
+	}
+	path, ok := args["path"].(string)
+	if !ok {
+		return s.sendError(request.ID, -32602, "Path parameter is required and must be a string")
+	}
+	method, ok := args["method"].(string)
+	if !ok {
+		return s.sendError(request.ID, -32602, "Method parameter is required and must be a string")
+	}
+
+	serverIndex := 0
+	if idx, ok := args["server"].(float64); ok {
+		serverIndex = int(idx)
+	}
+
+	format := "curl"
+	if f, ok := args["format"].(string); ok && f != "" {
+		format = strings.ToLower(f)
+	}
+	if format != "curl" && format != "httpie" && format != "both" {
+		return s.sendError(request.ID, -32602, fmt.Sprintf("Unsupported format: %s", format))
+	}
+
+	ref, err := s.specRefArg(ctx, args, key)
+	if err != nil {
+		return s.sendError(request.ID, -32602, err.Error())
+	}
+	doc, err := s.loadSpec(ctx, ref)
+	if err != nil {
+		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to load spec: %v", err))
+	}
+
+	op, err := doc.FindOperation(path, method)
+	if err != nil {
+		return s.sendError(request.ID, -32602, err.Error())
+	}
+
+	baseURL, err := codegen.ServerURL(doc, serverIndex)
+	if err != nil {
+		return s.sendError(request.ID, -32602, err.Error())
+	}
+
+	req := codegen.BuildRequest(doc, *op, baseURL)
+
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("Request snippet for %s %s (server: %s):\n\n", op.Method, op.Path, baseURL))
+	if format == "curl" || format == "both" {
+		resultText.WriteString("```bash\n")
+		resultText.WriteString(codegen.Curl(req))
+		resultText.WriteString("\n```\n\n")
+	}
+	if format == "httpie" || format == "both" {
+		resultText.WriteString("```bash\n")
+		resultText.WriteString(codegen.HTTPie(req))
+		resultText.WriteString("\n```\n\n")
+	}
+	resultText.WriteString("Replace $VARIABLES with your credentials before running.\n")
+
+	result := &mcp.ToolResult{
+		Content: []mcp.ToolContent{
+			{
+				Type: "text",
+				Text: resultText.String(),
+			},
+		},
+	}
+
+	return s.sendResponse(request.ID, result)
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/config"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/s3"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/source"
+)
+
+// sourceProperty is the input property every tool accepts to pick a source
+var sourceProperty = map[string]interface{}{
+	"type":        "string",
+	"description": "Name of the spec source to read from; listings and searches cover every source by default, a key is read from the one source that has it",
+}
+
+// newSource creates the spec source for a configured source
+func newSource(cfg config.Source) (source.Source, error) {
+	if cfg.Dir != "" {
+		return source.NewDir(cfg.Name, cfg.Dir)
+	}
+	if cfg.Git != "" {
+		return source.NewGit(cfg.Name, cfg.Git, cfg.Ref)
+	}
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("source %s has no bucket, directory or git repository", cfg.Name)
+	}
+	client, err := s3.New(s3.Options{
+		Region:               cfg.Region,
+		Bucket:               cfg.Bucket,
+		Endpoint:             cfg.Endpoint,
+		AccessKey:            cfg.AccessKey,
+		SecretKey:            cfg.SecretKey,
+		Profile:              cfg.Auth.Profile,
+		RoleARN:              cfg.Auth.RoleARN,
+		ExternalID:           cfg.Auth.ExternalID,
+		SessionName:          cfg.Auth.SessionName,
+		WebIdentityTokenFile: cfg.Auth.WebIdentityTokenFile,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("source %s: %w", cfg.Name, err)
+	}
+	return s3.NewSource(cfg.Name, cfg.Prefix, client), nil
+}
+
+// specRef identifies a spec file in a source, and optionally one of its
+// versions
+type specRef struct {
+	Source  source.Source
+	Key     string
+	Version string
+}
+
+// sourceFile is a listed spec file and the source it came from
+type sourceFile struct {
+	source.File
+	Source source.Source
+}
+
+// findSource returns the source with the given name
+func (s *Server) findSource(name string) (source.Source, error) {
+	for _, src := range s.sources {
+		if src.Name() == name {
+			return src, nil
+		}
+	}
+	names := make([]string, 0, len(s.sources))
+	for _, src := range s.sources {
+		names = append(names, src.Name())
+	}
+	return nil, fmt.Errorf("unknown source %s (configured: %s)", name, strings.Join(names, ", "))
+}
+
+// keySource returns the source named by the source argument or, when it is
+// not given, the one configured source that has key
+func (s *Server) keySource(ctx context.Context, args map[string]interface{}, key string) (source.Source, error) {
+	if name, ok := args["source"].(string); ok && name != "" {
+		return s.findSource(name)
+	}
+	if len(s.sources) == 1 {
+		return s.sources[0], nil
+	}
+
+	var found []source.Source
+	var names, failures []string
+	for _, src := range s.sources {
+		if _, err := src.Head(ctx, key); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", src.Name(), err))
+			continue
+		}
+		found = append(found, src)
+		names = append(names, src.Name())
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("%s not found in any source (%s)", key, strings.Join(failures, "; "))
+	case 1:
+		return found[0], nil
+	}
+	return nil, fmt.Errorf("%s exists in several sources (%s); pass source to pick one", key, strings.Join(names, ", "))
+}
+
+// sourcesArg returns the source named by the source argument, or every
+// configured source when it is not given
+func (s *Server) sourcesArg(args map[string]interface{}) ([]source.Source, error) {
+	if name, ok := args["source"].(string); ok && name != "" {
+		src, err := s.findSource(name)
+		if err != nil {
+			return nil, err
+		}
+		return []source.Source{src}, nil
+	}
+	return s.sources, nil
+}
+
+// listFiles lists the spec files under prefix across sources
+func (s *Server) listFiles(ctx context.Context, sources []source.Source, prefix string) ([]sourceFile, error) {
+	var files []sourceFile
+	for _, src := range sources {
+		listed, err := src.List(ctx, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", src.Name(), err)
+		}
+		for _, file := range listed {
+			files = append(files, sourceFile{File: file, Source: src})
+		}
+	}
+	return files, nil
+}
+
+// specRefArg names the spec at key in the source selected by the arguments
+func (s *Server) specRefArg(ctx context.Context, args map[string]interface{}, key string) (specRef, error) {
+	src, err := s.keySource(ctx, args, key)
+	if err != nil {
+		return specRef{}, err
+	}
+	return specRef{Source: src, Key: key}, nil
+}
+
+// specRefs returns the spec named by the key argument, or every spec under
+// prefix in the selected sources when no key is given
+func (s *Server) specRefs(ctx context.Context, args map[string]interface{}, prefix string) ([]specRef, error) {
+	if key, ok := args["key"].(string); ok && key != "" {
+		ref, err := s.specRefArg(ctx, args, key)
+		if err != nil {
+			return nil, err
+		}
+		return []specRef{ref}, nil
+	}
+
+	sources, err := s.sourcesArg(args)
+	if err != nil {
+		return nil, err
+	}
+	files, err := s.listFiles(ctx, sources, prefix)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]specRef, 0, len(files))
+	for _, file := range files {
+		refs = append(refs, specRef{Source: file.Source, Key: file.Key})
+	}
+	return refs, nil
+}
+
+// resolveURI finds the source and key a resource URI points at, preferring
+// the most specific source when several contain it
+func (s *Server) resolveURI(uri string) (source.Source, string) {
+	var found source.Source
+	var foundKey string
+	for _, src := range s.sources {
+		key, ok := src.Key(uri)
+		if ok && (found == nil || len(src.Root()) > len(found.Root())) {
+			found, foundKey = src, key
+		}
+	}
+	return found, foundKey
+}
+
+// location names a spec file in results
+func (s *Server) location(ref specRef) string {
+	return ref.Key + s.sourceSuffix(ref.Source)
+}
+
+// sourceSuffix names the source of a result when several are configured
+func (s *Server) sourceSuffix(src source.Source) string {
+	if len(s.sources) == 1 {
+		return ""
+	}
+	return fmt.Sprintf(" (source %s)", src.Name())
+}
@@ -0,0 +1,673 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/asyncapi"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/budget"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/openapi"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/pkg/mcp"
+)
+
+// protocolVersions are the MCP versions the server speaks, newest first
+var protocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// structuredProtocol is the first MCP version with tool output schemas and
+// structuredContent
+const structuredProtocol = "2025-06-18"
+
+// negotiateProtocol answers the version a client asks for with the same
+// version when the server speaks it, and with the newest one otherwise
+func negotiateProtocol(requested string) string {
+	for _, version := range protocolVersions {
+		if version == requested {
+			return version
+		}
+	}
+	return protocolVersions[0]
+}
+
+// structuredOutput reports whether the agreed protocol version carries
+// structured tool results. Versions are dates, so they compare as strings.
+func (s *Server) structuredOutput() bool {
+	return s.protocolVersion >= structuredProtocol
+}
+
+// Structured results, returned as MCP structuredContent next to the text
+
+// truncation marks a structured result whose lists were cut to fit the
+// output budget, counting the entries left out
+type truncation struct {
+	Truncated bool `json:"truncated,omitempty"`
+	Omitted   int  `json:"omitted,omitempty"`
+}
+
+// newTruncation marks a result keeping kept of total entries
+func newTruncation(total, kept int) truncation {
+	return truncation{Truncated: true, Omitted: total - kept}
+}
+
+type fileOutput struct {
+	Source       string `json:"source"`
+	Key          string `json:"key"`
+	Name         string `json:"name"`
+	URI          string `json:"uri"`
+	Size         int64  `json:"size"`
+	LastModified string `json:"lastModified"`
+	MimeType     string `json:"mimeType"`
+}
+
+type fileListOutput struct {
+	Pattern    string       `json:"pattern,omitempty"`
+	Prefix     string       `json:"prefix,omitempty"`
+	Count      int          `json:"count"`
+	Offset     int          `json:"offset,omitempty"`
+	NextOffset int          `json:"nextOffset,omitempty"`
+	Files      []fileOutput `json:"files"`
+	truncation
+}
+
+type endpointListOutput struct {
+	Path      string           `json:"path"`
+	Method    string           `json:"method,omitempty"`
+	Count     int              `json:"count"`
+	Endpoints []endpointOutput `json:"endpoints"`
+	truncation
+}
+
+type endpointOutput struct {
+	Source      string             `json:"source"`
+	Key         string             `json:"key"`
+	Method      string             `json:"method"`
+	Path        string             `json:"path"`
+	OperationID string             `json:"operationId,omitempty"`
+	Summary     string             `json:"summary,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Tags        []string           `json:"tags,omitempty"`
+	Deprecated  bool               `json:"deprecated"`
+	Parameters  []parameterOutput  `json:"parameters"`
+	RequestBody *requestBodyOutput `json:"requestBody,omitempty"`
+	Responses   []responseOutput   `json:"responses"`
+	Auth        authOutput         `json:"auth"`
+}
+
+type parameterOutput struct {
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Type        string `json:"type,omitempty"`
+	Required    bool   `json:"required"`
+	Deprecated  bool   `json:"deprecated"`
+	Description string `json:"description,omitempty"`
+}
+
+type requestBodyOutput struct {
+	ContentType string `json:"contentType,omitempty"`
+	Schema      string `json:"schema,omitempty"`
+	Required    bool   `json:"required"`
+}
+
+type responseOutput struct {
+	Status      string `json:"status"`
+	Description string `json:"description,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Schema      string `json:"schema,omitempty"`
+}
+
+type authListOutput struct {
+	Path      string               `json:"path"`
+	Method    string               `json:"method,omitempty"`
+	Count     int                  `json:"count"`
+	Endpoints []endpointAuthOutput `json:"endpoints"`
+	truncation
+}
+
+type endpointAuthOutput struct {
+	Source string     `json:"source"`
+	Key    string     `json:"key"`
+	Method string     `json:"method"`
+	Path   string     `json:"path"`
+	Auth   authOutput `json:"auth"`
+}
+
+type authOutput struct {
+	Source       string               `json:"source"`
+	Alternatives [][]authSchemeOutput `json:"alternatives"`
+}
+
+type authSchemeOutput struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type,omitempty"`
+	Scheme      string   `json:"scheme,omitempty"`
+	In          string   `json:"in,omitempty"`
+	KeyName     string   `json:"keyName,omitempty"`
+	Description string   `json:"description"`
+	Scopes      []string `json:"scopes"`
+	Flows       []string `json:"flows,omitempty"`
+}
+
+type errorListOutput struct {
+	Count      int                    `json:"count"`
+	Operations []errorOperationOutput `json:"operations"`
+	truncation
+}
+
+type errorOperationOutput struct {
+	Source string                `json:"source"`
+	Key    string                `json:"key"`
+	Method string                `json:"method"`
+	Path   string                `json:"path"`
+	Errors []errorResponseOutput `json:"errors"`
+}
+
+type errorResponseOutput struct {
+	Status      string             `json:"status"`
+	Description string             `json:"description,omitempty"`
+	ContentType string             `json:"contentType,omitempty"`
+	Schema      string             `json:"schema,omitempty"`
+	Fields      []errorFieldOutput `json:"fields"`
+}
+
+type errorFieldOutput struct {
+	Path        string        `json:"path"`
+	Type        string        `json:"type,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Highlighted bool          `json:"highlighted"`
+}
+
+type deprecationListOutput struct {
+	Count int                     `json:"count"`
+	Specs []deprecationSpecOutput `json:"specs"`
+	truncation
+}
+
+type deprecationSpecOutput struct {
+	Source string              `json:"source"`
+	Key    string              `json:"key"`
+	Title  string              `json:"title"`
+	Items  []deprecationOutput `json:"items"`
+}
+
+type deprecationOutput struct {
+	Kind     string `json:"kind"`
+	Location string `json:"location"`
+	Sunset   string `json:"sunset,omitempty"`
+	PastDue  bool   `json:"pastDue"`
+	Note     string `json:"note,omitempty"`
+}
+
+type channelListOutput struct {
+	Specs []channelSpecOutput `json:"specs"`
+	truncation
+}
+
+type channelSpecOutput struct {
+	Source   string          `json:"source"`
+	Key      string          `json:"key"`
+	Title    string          `json:"title"`
+	Version  string          `json:"asyncapi"`
+	Channels []channelOutput `json:"channels"`
+}
+
+type channelOutput struct {
+	Name        string                   `json:"name"`
+	Address     string                   `json:"address"`
+	Description string                   `json:"description,omitempty"`
+	Operations  []channelOperationOutput `json:"operations"`
+	Messages    []string                 `json:"messages"`
+}
+
+type channelOperationOutput struct {
+	ID      string `json:"id,omitempty"`
+	Action  string `json:"action"`
+	Keyword string `json:"keyword"`
+}
+
+type messageListOutput struct {
+	Channel  string          `json:"channel"`
+	Messages []messageOutput `json:"messages"`
+	truncation
+}
+
+type messageOutput struct {
+	Source      string      `json:"source"`
+	Key         string      `json:"key"`
+	Channel     string      `json:"channel"`
+	Name        string      `json:"name"`
+	Title       string      `json:"title,omitempty"`
+	Summary     string      `json:"summary,omitempty"`
+	ContentType string      `json:"contentType,omitempty"`
+	Payload     interface{} `json:"payload,omitempty"`
+	Headers     interface{} `json:"headers,omitempty"`
+}
+
+type participantsOutput struct {
+	Channel     string              `json:"channel"`
+	Publishers  []participantOutput `json:"publishers"`
+	Subscribers []participantOutput `json:"subscribers"`
+	truncation
+}
+
+type participantOutput struct {
+	Source  string `json:"source"`
+	Service string `json:"service"`
+	Key     string `json:"key"`
+	Channel string `json:"channel"`
+}
+
+type versionListOutput struct {
+	Source   string          `json:"source"`
+	Key      string          `json:"key"`
+	URI      string          `json:"uri"`
+	Versions []versionOutput `json:"versions"`
+	truncation
+}
+
+type versionOutput struct {
+	ID           string `json:"id"`
+	Size         int64  `json:"size"`
+	LastModified string `json:"lastModified"`
+	Current      bool   `json:"current"`
+	Author       string `json:"author,omitempty"`
+	Message      string `json:"message,omitempty"`
+}
+
+type compareOutput struct {
+	Base      compareSideOutput `json:"base"`
+	Head      compareSideOutput `json:"head"`
+	Identical bool              `json:"identical"`
+	Compared  string            `json:"compared"`
+	Breaking  int               `json:"breaking"`
+	Changes   []changeOutput    `json:"changes"`
+	truncation
+}
+
+type compareSideOutput struct {
+	Source  string `json:"source"`
+	Key     string `json:"key"`
+	Version string `json:"version,omitempty"`
+	URI     string `json:"uri"`
+	Size    int64  `json:"size"`
+}
+
+type changeOutput struct {
+	Kind     string `json:"kind"`
+	Location string `json:"location"`
+	Detail   string `json:"detail"`
+	Breaking bool   `json:"breaking"`
+}
+
+// sendToolResult sends a tool result with both text and structured content.
+// Over budget, the lists of the structured content lose their last entries.
+// With format "json" the text block carries the serialized structure instead
+// of the Markdown rendering, for clients that ignore structuredContent.
+// Clients on protocol versions without structuredContent get the text only.
+func (s *Server) sendToolResult(request *mcp.RequestMessage, args map[string]interface{}, text string, structured interface{}) error {
+	format, _ := args["format"].(string)
+	switch format {
+	case "", "text":
+		structured = s.fitStructured(structured, json.Marshal)
+	case "json":
+		indented := func(v interface{}) ([]byte, error) { return json.MarshalIndent(v, "", "  ") }
+		structured = s.fitStructured(structured, indented)
+		data, err := indented(structured)
+		if err != nil {
+			return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to encode result: %v", err))
+		}
+		text = string(data)
+		if s.budget > 0 && len(text) > s.budget && s.structuredOutput() {
+			// Cutting JSON would leave it unparseable, so point at the
+			// structured content instead
+			text = "The JSON result is available as structuredContent." + budget.Notice(len(text), s.budget, toolBudgetHint)
+		}
+	default:
+		return s.sendError(request.ID, -32602, fmt.Sprintf("Unsupported format: %s (expected text or json)", format))
+	}
+
+	result := &mcp.ToolResult{
+		Content: []mcp.ToolContent{
+			{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+	if s.structuredOutput() {
+		result.StructuredContent = structured
+	}
+
+	return s.sendResponse(request.ID, result)
+}
+
+// newFileOutput converts an S3 file listing entry
+func newFileOutput(file sourceFile) fileOutput {
+	return fileOutput{
+		Source:       file.Source.Name(),
+		Key:          file.Key,
+		Name:         file.Name,
+		URI:          file.Source.URI(file.Key),
+		Size:         file.Size,
+		LastModified: file.LastModified,
+		MimeType:     file.MimeType,
+	}
+}
+
+// newEndpointOutput describes an operation from the parsed spec
+func newEndpointOutput(ref specRef, doc *openapi.Document, op openapi.OperationRef) endpointOutput {
+	out := endpointOutput{
+		Source:      ref.Source.Name(),
+		Key:         ref.Key,
+		Method:      op.Method,
+		Path:        op.Path,
+		OperationID: op.Operation.OperationID,
+		Summary:     op.Operation.Summary,
+		Description: op.Operation.Description,
+		Tags:        op.Operation.Tags,
+		Deprecated:  op.Operation.Deprecated,
+		Parameters:  []parameterOutput{},
+		Responses:   []responseOutput{},
+		Auth:        newAuthOutput(doc.ResolveAuth(op.Operation)),
+	}
+
+	for _, p := range doc.Parameters(op) {
+		out.Parameters = append(out.Parameters, parameterOutput{
+			Name:        p.Name,
+			In:          p.In,
+			Type:        schemaLabel(doc.ResolveSchema(p.Schema)),
+			Required:    p.Required,
+			Deprecated:  p.Deprecated,
+			Description: p.Description,
+		})
+	}
+
+	if body := doc.ResolveRequestBody(op.Operation.RequestBody); body != nil {
+		rb := &requestBodyOutput{Required: body.Required}
+		if contentType, mt := openapi.JSONMediaType(body.Content); mt != nil {
+			rb.ContentType = contentType
+			rb.Schema = schemaLabel(mt.Schema)
+		}
+		out.RequestBody = rb
+	}
+
+	for _, code := range sortedResponseCodes(op.Operation.Responses) {
+		resp := doc.ResolveResponse(op.Operation.Responses[code])
+		if resp == nil {
+			continue
+		}
+		ro := responseOutput{Status: code, Description: resp.Description}
+		if contentType, mt := openapi.JSONMediaType(resp.Content); mt != nil {
+			ro.ContentType = contentType
+			ro.Schema = schemaLabel(mt.Schema)
+		}
+		out.Responses = append(out.Responses, ro)
+	}
+
+	return out
+}
+
+// newAuthOutput converts resolved security requirements
+func newAuthOutput(auth openapi.Auth) authOutput {
+	out := authOutput{Source: auth.Source, Alternatives: [][]authSchemeOutput{}}
+	for _, alt := range auth.Alternatives {
+		schemes := []authSchemeOutput{}
+		for _, scheme := range alt.Schemes {
+			so := authSchemeOutput{
+				Name:        scheme.Name,
+				Description: scheme.Describe(),
+				Scopes:      scheme.Scopes,
+				Flows:       scheme.Flows(),
+			}
+			if so.Scopes == nil {
+				so.Scopes = []string{}
+			}
+			if scheme.Scheme != nil {
+				so.Type = scheme.Scheme.Type
+				so.Scheme = scheme.Scheme.Scheme
+				so.In = scheme.Scheme.In
+				so.KeyName = scheme.Scheme.Name
+			}
+			schemes = append(schemes, so)
+		}
+		out.Alternatives = append(out.Alternatives, schemes)
+	}
+	return out
+}
+
+// newErrorResponseOutput converts an error response, marking highlighted fields
+func newErrorResponseOutput(resp openapi.ErrorResponse, highlight []string) errorResponseOutput {
+	out := errorResponseOutput{
+		Status:      resp.Status,
+		Description: resp.Description,
+		ContentType: resp.ContentType,
+		Schema:      resp.SchemaName,
+		Fields:      []errorFieldOutput{},
+	}
+	for _, f := range resp.Fields {
+		out.Fields = append(out.Fields, errorFieldOutput{
+			Path:        f.Path,
+			Type:        f.Type,
+			Description: f.Description,
+			Enum:        f.Enum,
+			Highlighted: isHighlighted(f.Path, highlight),
+		})
+	}
+	return out
+}
+
+// newCompareSideOutput describes one side of a comparison
+func newCompareSideOutput(ref specRef, size int64) compareSideOutput {
+	return compareSideOutput{
+		Source:  ref.Source.Name(),
+		Key:     ref.Key,
+		Version: ref.Version,
+		URI:     ref.Source.URI(ref.Key),
+		Size:    size,
+	}
+}
+
+// newDeprecationOutput converts a deprecation entry
+func newDeprecationOutput(item openapi.Deprecation, now time.Time) deprecationOutput {
+	return deprecationOutput{
+		Kind:     item.Kind,
+		Location: item.Location,
+		Sunset:   item.Sunset,
+		PastDue:  !item.Date.IsZero() && item.Date.Before(now),
+		Note:     item.Note,
+	}
+}
+
+// newChannelOutput converts an AsyncAPI channel
+func newChannelOutput(ch *asyncapi.Channel) channelOutput {
+	out := channelOutput{
+		Name:        ch.Name,
+		Address:     ch.Address,
+		Description: ch.Description,
+		Operations:  []channelOperationOutput{},
+		Messages:    []string{},
+	}
+	for _, op := range ch.Operations {
+		out.Operations = append(out.Operations, channelOperationOutput{ID: op.ID, Action: op.Action, Keyword: op.Keyword})
+	}
+	for _, m := range ch.Messages {
+		out.Messages = append(out.Messages, m.Name)
+	}
+	return out
+}
+
+// schemaLabel names a schema for structured output: the component name for
+// references, Name[] for arrays of references, otherwise its type
+func schemaLabel(schema *openapi.Schema) string {
+	if schema == nil {
+		return ""
+	}
+	if schema.Ref != "" {
+		return openapi.RefName(schema.Ref)
+	}
+	if schema.Type == "array" && schema.Items != nil {
+		if item := schemaLabel(schema.Items); item != "" {
+			return item + "[]"
+		}
+	}
+	return schema.Type
+}
+
+// sortedResponseCodes returns response status codes in ascending order
+func sortedResponseCodes(responses map[string]*openapi.Response) []string {
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// formatProperty is the input argument selecting the text rendering
+var formatProperty = map[string]interface{}{
+	"type":        "string",
+	"enum":        []string{"text", "json"},
+	"description": "Output format of the text content: text (default) or json; structuredContent is always included on protocol 2025-06-18 and later",
+}
+
+// JSON Schema helpers for output schemas
+
+func objectOf(properties map[string]interface{}, required ...string) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func arrayOf(items map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "array", "items": items}
+}
+
+func typed(t string) map[string]interface{} {
+	return map[string]interface{}{"type": t}
+}
+
+// toolOutputSchemas returns the output schema of every tool with structured
+// results, keyed by tool name
+func toolOutputSchemas() map[string]map[string]interface{} {
+	str, integer, boolean := typed("string"), typed("integer"), typed("boolean")
+
+	file := objectOf(map[string]interface{}{
+		"source": str, "key": str, "name": str, "uri": str, "size": integer, "lastModified": str, "mimeType": str,
+	}, "source", "key", "name", "uri")
+	compareSide := objectOf(map[string]interface{}{
+		"source": str, "key": str, "version": str, "uri": str, "size": integer,
+	}, "source", "key", "uri", "size")
+	fileList := objectOf(map[string]interface{}{
+		"pattern": str, "prefix": str, "count": integer, "offset": integer, "nextOffset": integer, "files": arrayOf(file),
+	}, "count", "files")
+
+	auth := objectOf(map[string]interface{}{
+		"source": map[string]interface{}{"type": "string", "enum": []string{openapi.SecurityFromOperation, openapi.SecurityFromGlobal, openapi.SecurityNone}},
+		"alternatives": arrayOf(arrayOf(objectOf(map[string]interface{}{
+			"name": str, "type": str, "scheme": str, "in": str, "keyName": str,
+			"description": str, "scopes": arrayOf(str), "flows": arrayOf(str),
+		}, "name", "description", "scopes"))),
+	}, "source", "alternatives")
+
+	endpoint := objectOf(map[string]interface{}{
+		"source": str, "key": str, "method": str, "path": str, "operationId": str, "summary": str,
+		"description": str, "tags": arrayOf(str), "deprecated": boolean,
+		"parameters": arrayOf(objectOf(map[string]interface{}{
+			"name": str, "in": str, "type": str, "required": boolean, "deprecated": boolean, "description": str,
+		}, "name", "in", "required")),
+		"requestBody": objectOf(map[string]interface{}{"contentType": str, "schema": str, "required": boolean}, "required"),
+		"responses": arrayOf(objectOf(map[string]interface{}{
+			"status": str, "description": str, "contentType": str, "schema": str,
+		}, "status")),
+		"auth": auth,
+	}, "source", "key", "method", "path", "parameters", "responses", "auth")
+
+	errorResponse := objectOf(map[string]interface{}{
+		"status": str, "description": str, "contentType": str, "schema": str,
+		"fields": arrayOf(objectOf(map[string]interface{}{
+			"path": str, "type": str, "description": str, "enum": typed("array"), "highlighted": boolean,
+		}, "path", "highlighted")),
+	}, "status", "fields")
+
+	channel := objectOf(map[string]interface{}{
+		"name": str, "address": str, "description": str,
+		"operations": arrayOf(objectOf(map[string]interface{}{
+			"id": str, "action": map[string]interface{}{"type": "string", "enum": []string{asyncapi.ActionSend, asyncapi.ActionReceive}}, "keyword": str,
+		}, "action", "keyword")),
+		"messages": arrayOf(str),
+	}, "name", "address", "operations", "messages")
+
+	participant := objectOf(map[string]interface{}{"service": str, "source": str, "key": str, "channel": str}, "service", "source", "key", "channel")
+
+	schemas := map[string]map[string]interface{}{
+		"search_yaml_files": fileList,
+		"list_yaml_files":   fileList,
+		"get_endpoint_details": objectOf(map[string]interface{}{
+			"path": str, "method": str, "count": integer, "endpoints": arrayOf(endpoint),
+		}, "path", "count", "endpoints"),
+		"get_endpoint_auth": objectOf(map[string]interface{}{
+			"path": str, "method": str, "count": integer,
+			"endpoints": arrayOf(objectOf(map[string]interface{}{
+				"source": str, "key": str, "method": str, "path": str, "auth": auth,
+			}, "source", "key", "method", "path", "auth")),
+		}, "path", "count", "endpoints"),
+		"list_error_responses": objectOf(map[string]interface{}{
+			"count": integer,
+			"operations": arrayOf(objectOf(map[string]interface{}{
+				"source": str, "key": str, "method": str, "path": str, "errors": arrayOf(errorResponse),
+			}, "source", "key", "method", "path", "errors")),
+		}, "count", "operations"),
+		"list_deprecated": objectOf(map[string]interface{}{
+			"count": integer,
+			"specs": arrayOf(objectOf(map[string]interface{}{
+				"source": str, "key": str, "title": str,
+				"items": arrayOf(objectOf(map[string]interface{}{
+					"kind": str, "location": str, "sunset": str, "pastDue": boolean, "note": str,
+				}, "kind", "location", "pastDue")),
+			}, "source", "key", "title", "items")),
+		}, "count", "specs"),
+		"list_channels": objectOf(map[string]interface{}{
+			"specs": arrayOf(objectOf(map[string]interface{}{
+				"source": str, "key": str, "title": str, "asyncapi": str, "channels": arrayOf(channel),
+			}, "source", "key", "title", "asyncapi", "channels")),
+		}, "specs"),
+		"get_message_schema": objectOf(map[string]interface{}{
+			"channel": str,
+			"messages": arrayOf(objectOf(map[string]interface{}{
+				"source": str, "key": str, "channel": str, "name": str, "title": str, "summary": str,
+				"contentType": str, "payload": map[string]interface{}{}, "headers": map[string]interface{}{},
+			}, "source", "key", "channel", "name")),
+		}, "channel", "messages"),
+		"find_channel_participants": objectOf(map[string]interface{}{
+			"channel": str, "publishers": arrayOf(participant), "subscribers": arrayOf(participant),
+		}, "channel", "publishers", "subscribers"),
+		"list_spec_versions": objectOf(map[string]interface{}{
+			"source": str, "key": str, "uri": str,
+			"versions": arrayOf(objectOf(map[string]interface{}{
+				"id": str, "size": integer, "lastModified": str, "current": boolean, "author": str, "message": str,
+			}, "id", "size", "lastModified", "current")),
+		}, "source", "key", "uri", "versions"),
+		"compare_spec": objectOf(map[string]interface{}{
+			"base": compareSide, "head": compareSide, "identical": boolean,
+			"compared": map[string]interface{}{"type": "string", "enum": []string{"openapi", "text"}},
+			"breaking": integer,
+			"changes": arrayOf(objectOf(map[string]interface{}{
+				"kind":     map[string]interface{}{"type": "string", "enum": []string{openapi.ChangeAdded, openapi.ChangeRemoved, openapi.ChangeModified}},
+				"location": str, "detail": str, "breaking": boolean,
+			}, "kind", "location", "detail", "breaking")),
+		}, "base", "head", "identical", "compared", "breaking", "changes"),
+	}
+
+	// Every result may have its lists cut to fit the output budget
+	for _, schema := range schemas {
+		properties := schema["properties"].(map[string]interface{})
+		properties["truncated"], properties["omitted"] = boolean, integer
+	}
+	return schemas
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/asyncapi"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/metrics"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/openapi"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/tracing"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/pkg/mcp"
+)
+
+// startRequestSpan starts the span of a JSON-RPC request. A client can put
+// the traceparent and tracestate of its own trace in the _meta of the
+// params, which is where MCP carries request metadata over stdio.
+func (s *Server) startRequestSpan(ctx context.Context, request *mcp.RequestMessage) (context.Context, trace.Span) {
+	if params, ok := request.Params.(map[string]interface{}); ok {
+		if meta, ok := params["_meta"].(map[string]interface{}); ok {
+			carrier := make(map[string]string)
+			for key, value := range meta {
+				if str, ok := value.(string); ok {
+					carrier[key] = str
+				}
+			}
+			ctx = tracing.Extract(ctx, carrier)
+		}
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("rpc.system", "jsonrpc"),
+		attribute.String("rpc.method", request.Method),
+		attribute.String("mcp.request_id", s.request.ID),
+	}
+	if request.ID != nil {
+		attrs = append(attrs, attribute.String("rpc.jsonrpc.request_id", fmt.Sprint(request.ID)))
+	}
+	return tracing.Tracer().Start(ctx, request.Method, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+}
+
+// endRequestSpan ends the span of the request in progress with its tool,
+// S3 call count and outcome
+func (s *Server) endRequestSpan(span trace.Span, err error) {
+	if tool := s.request.Tool; tool != "" {
+		span.SetName(s.request.Method + " " + tool)
+		span.SetAttributes(attribute.String("mcp.tool", tool))
+	}
+	span.SetAttributes(attribute.Int64("mcp.s3_calls", s.request.S3Calls()))
+	switch {
+	case err != nil:
+		tracing.RecordError(span, err)
+	case s.failure != nil:
+		span.SetAttributes(attribute.Int("rpc.jsonrpc.error_code", s.failure.Code))
+		tracing.RecordError(span, errors.New(s.failure.Message))
+	}
+	span.End()
+}
+
+// parseOpenAPI parses the OpenAPI document read from ref in a span of its
+// own. Documents that fail to parse count in the metrics; documents that are
+// not OpenAPI at all do not.
+func (s *Server) parseOpenAPI(ctx context.Context, ref specRef, content []byte) (*openapi.Document, error) {
+	_, span := startParseSpan(ctx, "openapi", ref, content)
+	defer span.End()
+
+	doc, err := openapi.Parse(content)
+	if err != nil && !errors.Is(err, openapi.ErrNotOpenAPI) {
+		metrics.ParseFailures.WithLabelValues("openapi").Inc()
+		tracing.RecordError(span, err)
+	}
+	return doc, err
+}
+
+// parseAsyncAPI parses the AsyncAPI document read from ref in a span of its
+// own, like parseOpenAPI
+func (s *Server) parseAsyncAPI(ctx context.Context, ref specRef, content []byte) (*asyncapi.Document, error) {
+	_, span := startParseSpan(ctx, "asyncapi", ref, content)
+	defer span.End()
+
+	doc, err := asyncapi.Parse(content)
+	if err != nil && !errors.Is(err, asyncapi.ErrNotAsyncAPI) {
+		metrics.ParseFailures.WithLabelValues("asyncapi").Inc()
+		tracing.RecordError(span, err)
+	}
+	return doc, err
+}
+
+// startParseSpan starts the span of parsing a spec in format
+func startParseSpan(ctx context.Context, format string, ref specRef, content []byte) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "parse "+format, trace.WithAttributes(
+		attribute.String("spec.source", ref.Source.Name()),
+		attribute.String("spec.key", ref.Key),
+		attribute.String("spec.version", ref.Version),
+		attribute.Int("spec.size", len(content)),
+	))
+}
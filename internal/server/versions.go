@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/pkg/mcp"
+)
+
+// handleListSpecVersions handles the list_spec_versions tool
+func (s *Server) handleListSpecVersions(ctx context.Context, request *mcp.RequestMessage, args map[string]interface{}) error {
+	key, ok := args["key"].(string)
+	if !ok {
+		return s.sendError(request.ID, -32602, "Key parameter is required and must be a string")
+	}
+
+	ref, err := s.specRefArg(ctx, args, key)
+	if err != nil {
+		return s.sendError(request.ID, -32602, err.Error())
+	}
+
+	file, err := ref.Source.Head(ctx, key)
+	if err != nil {
+		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to read file: %v", err))
+	}
+	versions, err := ref.Source.Versions(ctx, key)
+	if err != nil {
+		return s.sendError(request.ID, -32603, fmt.Sprintf("Failed to list versions: %v", err))
+	}
+
+	structured := versionListOutput{Source: ref.Source.Name(), Key: key, URI: ref.Source.URI(key), Versions: []versionOutput{}}
+	var resultText strings.Builder
+	resultText.WriteString(fmt.Sprintf("🕒 %d version(s) of %s (current: %d bytes, modified %s):\n\n", len(versions), s.location(ref), file.Size, file.LastModified))
+	for _, v := range versions {
+		structured.Versions = append(structured.Versions, versionOutput{
+			ID:           v.ID,
+			Size:         v.Size,
+			LastModified: v.LastModified,
+			Current:      v.Current,
+			Author:       v.Author,
+			Message:      v.Message,
+		})
+
+		resultText.WriteString(fmt.Sprintf("- `%s` · %s · %d bytes", v.ID, v.LastModified, v.Size))
+		if v.Current {
+			resultText.WriteString(" (current)")
+		}
+		if v.Author != "" {
+			resultText.WriteString(" · " + v.Author)
+		}
+		if v.Message != "" {
+			resultText.WriteString(" — " + firstLine(v.Message))
+		}
+		resultText.WriteString("\n")
+	}
+	resultText.WriteString(fmt.Sprintf("\nRead a version as the resource %s?version=<id>\n", ref.Source.URI(key)))
+
+	return s.sendToolResult(request, args, resultText.String(), structured)
+}
@@ -0,0 +1,186 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Dir is a source reading spec files from a local directory, such as a
+// checked-out repository of specs
+type Dir struct {
+	name string
+	root string
+}
+
+var _ Source = (*Dir)(nil)
+
+// currentVersion is the only version a directory has
+const currentVersion = "current"
+
+// NewDir creates a source for the directory at root
+func NewDir(name, root string) (*Dir, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", root, err)
+	}
+	return &Dir{name: name, root: abs}, nil
+}
+
+// Name is the configured name of the source
+func (d *Dir) Name() string {
+	return d.name
+}
+
+// Root is the file:// URI of the directory
+func (d *Dir) Root() string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(d.root) + "/"}).String()
+}
+
+// URI returns the file:// URI of a key
+func (d *Dir) URI(key string) string {
+	return (&url.URL{Scheme: "file", Path: path.Join(filepath.ToSlash(d.root), key)}).String()
+}
+
+// Key returns the key of a file:// URI inside the directory
+func (d *Dir) Key(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", false
+	}
+	key, ok := strings.CutPrefix(u.Path, filepath.ToSlash(d.root)+"/")
+	if !ok || key == "" {
+		return "", false
+	}
+	return key, true
+}
+
+// List lists the YAML files and JSON specs under prefix
+func (d *Dir) List(ctx context.Context, prefix string) ([]File, error) {
+	var files []File
+	err := filepath.WalkDir(d.root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			// Skip hidden directories such as .git
+			if p != d.root && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		key := d.key(p)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		if !IsYAMLFile(key) && !(IsJSONFile(key) && d.isJSONSpec(p)) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, d.file(key, info))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", d.root, err)
+	}
+	return files, nil
+}
+
+// Get reads a spec file; directories only have the current version
+func (d *Dir) Get(ctx context.Context, key, version string) (*File, error) {
+	if version != "" && version != currentVersion {
+		return nil, fmt.Errorf("source %s has no version %s of %s; local directories only have the current version", d.name, version, key)
+	}
+	file, err := d.Head(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	file.Content = string(content)
+	return file, nil
+}
+
+// Head returns the metadata of a spec file
+func (d *Dir) Head(ctx context.Context, key string) (*File, error) {
+	if !IsYAMLFile(key) && !IsJSONFile(key) {
+		return nil, fmt.Errorf("file %s is not a YAML or JSON file", key)
+	}
+	if !fs.ValidPath(key) {
+		return nil, fmt.Errorf("invalid key %s", key)
+	}
+	info, err := os.Stat(d.path(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", key, err)
+	}
+	file := d.file(key, info)
+	return &file, nil
+}
+
+// Versions reports the file as it is on disk
+func (d *Dir) Versions(ctx context.Context, key string) ([]Version, error) {
+	file, err := d.Head(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return []Version{{ID: currentVersion, Size: file.Size, LastModified: file.LastModified, Current: true}}, nil
+}
+
+// Check verifies that the root is a readable directory
+func (d *Dir) Check(ctx context.Context) error {
+	info, err := os.Stat(d.root)
+	if err != nil {
+		return fmt.Errorf("failed to access directory %s: %w", d.root, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", d.root)
+	}
+	return nil
+}
+
+func (d *Dir) key(p string) string {
+	rel, _ := filepath.Rel(d.root, p)
+	return filepath.ToSlash(rel)
+}
+
+func (d *Dir) path(key string) string {
+	return filepath.Join(d.root, filepath.FromSlash(key))
+}
+
+func (d *Dir) file(key string, info fs.FileInfo) File {
+	return File{
+		Key:          key,
+		Name:         path.Base(key),
+		Size:         info.Size(),
+		LastModified: info.ModTime().Format("2006-01-02 15:04:05"),
+		MimeType:     MimeType(key),
+	}
+}
+
+// isJSONSpec reads the start of a JSON file and reports whether it is a spec
+func (d *Dir) isJSONSpec(p string) bool {
+	f, err := os.Open(p)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	head, err := io.ReadAll(io.LimitReader(f, SniffBytes))
+	if err != nil {
+		return false
+	}
+	return LooksLikeSpec(head)
+}
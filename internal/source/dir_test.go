@@ -0,0 +1,138 @@
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles creates files under root from slash-separated keys
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for key, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(key))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func newTestDir(t *testing.T) (*Dir, string) {
+	t.Helper()
+	parent := t.TempDir()
+	root := filepath.Join(parent, "specs")
+	writeFiles(t, root, map[string]string{
+		"cards.yaml":           "openapi: 3.0.3\n",
+		"events/users.yml":     "asyncapi: 3.0.0\n",
+		"events/schema.json":   `{"openapi": "3.1.0"}`,
+		"package.json":         `{"name": "specs"}`,
+		"README.md":            "# Specs\n",
+		".git/config.yaml":     "hidden: true\n",
+		"events/.drafts/x.yml": "openapi: 3.0.3\n",
+	})
+	// A spec next to the root, reachable only by escaping it
+	writeFiles(t, parent, map[string]string{"secret.yaml": "password: hunter2\n"})
+
+	dir, err := NewDir("local", root)
+	if err != nil {
+		t.Fatalf("NewDir: %v", err)
+	}
+	return dir, root
+}
+
+func TestDirList(t *testing.T) {
+	dir, _ := newTestDir(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{prefix: "", want: []string{"cards.yaml", "events/schema.json", "events/users.yml"}},
+		{prefix: "events/", want: []string{"events/schema.json", "events/users.yml"}},
+		{prefix: "missing/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			files, err := dir.List(ctx, tt.prefix)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			var keys []string
+			for _, file := range files {
+				keys = append(keys, file.Key)
+			}
+			if !reflect.DeepEqual(keys, tt.want) {
+				t.Errorf("keys = %v, want %v", keys, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirGet(t *testing.T) {
+	dir, root := newTestDir(t)
+	ctx := context.Background()
+
+	file, err := dir.Get(ctx, "events/users.yml", "")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if file.Content != "asyncapi: 3.0.0\n" || file.Name != "users.yml" || file.Size != 16 || file.MimeType != "application/x-yaml" {
+		t.Errorf("file = %+v", file)
+	}
+	if _, err := dir.Get(ctx, "events/users.yml", currentVersion); err != nil {
+		t.Errorf("Get current version: %v", err)
+	}
+	if _, err := dir.Get(ctx, "events/users.yml", "v1"); err == nil {
+		t.Error("Get accepted a version a directory does not have")
+	}
+
+	head, err := dir.Head(ctx, "cards.yaml")
+	if err != nil {
+		t.Fatalf("Head: %v", err)
+	}
+	if head.Content != "" || head.Size != 15 {
+		t.Errorf("head = %+v", head)
+	}
+
+	if uri := dir.URI("events/users.yml"); uri != "file://"+filepath.ToSlash(root)+"/events/users.yml" {
+		t.Errorf("URI = %s", uri)
+	}
+	if key, ok := dir.Key(dir.URI("events/users.yml")); !ok || key != "events/users.yml" {
+		t.Errorf("Key = %s, %v", key, ok)
+	}
+}
+
+func TestDirRejectsKeysOutsideRoot(t *testing.T) {
+	dir, root := newTestDir(t)
+	ctx := context.Background()
+
+	for _, key := range []string{
+		"../secret.yaml",
+		"events/../../secret.yaml",
+		"/" + filepath.ToSlash(filepath.Join(filepath.Dir(root), "secret.yaml")),
+		"README.md",
+	} {
+		t.Run(key, func(t *testing.T) {
+			if file, err := dir.Get(ctx, key, ""); err == nil {
+				t.Errorf("Get read %s: %q", key, file.Content)
+			}
+			if _, err := dir.Head(ctx, key); err == nil {
+				t.Errorf("Head accepted %s", key)
+			}
+		})
+	}
+
+	// URIs escaping the root resolve to keys that are then rejected
+	uri := "file://" + filepath.ToSlash(root) + "/../secret.yaml"
+	if key, ok := dir.Key(uri); ok {
+		if _, err := dir.Get(ctx, key, ""); err == nil {
+			t.Errorf("Get read %s through %s", key, uri)
+		}
+	}
+}
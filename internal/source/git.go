@@ -0,0 +1,376 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/metrics"
+)
+
+// Git is a source reading spec files from a local clone of a git repository
+// at a branch, tag or commit. Versions of a file are the commits that
+// changed it, and any revision can be read as a version.
+type Git struct {
+	name string
+	repo string
+	ref  string
+
+	// mu guards the caches of List, which only hold facts about immutable
+	// objects: the last change of every path as of commit changesAt, and
+	// whether a JSON blob is a spec
+	mu        sync.Mutex
+	changesAt string
+	changes   map[string]string
+	sniffed   map[string]bool
+}
+
+var _ Source = (*Git)(nil)
+
+// NewGit creates a source for the repository at repo, reading ref
+func NewGit(name, repo, ref string) (*Git, error) {
+	abs, err := filepath.Abs(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", repo, err)
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	if err := validRevision(ref); err != nil {
+		return nil, err
+	}
+	return &Git{name: name, repo: abs, ref: ref}, nil
+}
+
+// Name is the configured name of the source
+func (g *Git) Name() string {
+	return g.name
+}
+
+// Root is the git+file:// URI of the repository
+func (g *Git) Root() string {
+	return (&url.URL{Scheme: "git+file", Path: filepath.ToSlash(g.repo) + "/"}).String()
+}
+
+// URI returns the git+file:// URI of a key
+func (g *Git) URI(key string) string {
+	return (&url.URL{Scheme: "git+file", Path: path.Join(filepath.ToSlash(g.repo), key)}).String()
+}
+
+// Key returns the key of a git+file:// URI inside the repository
+func (g *Git) Key(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "git+file" {
+		return "", false
+	}
+	key, ok := strings.CutPrefix(u.Path, filepath.ToSlash(g.repo)+"/")
+	if !ok || key == "" {
+		return "", false
+	}
+	return key, true
+}
+
+// List lists the YAML files and JSON specs under prefix at the source ref
+func (g *Git) List(ctx context.Context, prefix string) ([]File, error) {
+	commit, err := g.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out, err := g.git(ctx, "ls-tree", "-r", "-l", "-z", commit)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []File
+	objects := make(map[string]string)
+	var jsonObjects []string
+	for _, entry := range strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00") {
+		// <mode> <type> <object> <size>\t<path>
+		meta, key, ok := strings.Cut(entry, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 4 || fields[1] != "blob" || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if !IsYAMLFile(key) && !IsJSONFile(key) {
+			continue
+		}
+		if IsJSONFile(key) {
+			objects[key] = fields[2]
+			jsonObjects = append(jsonObjects, fields[2])
+		}
+		size, _ := strconv.ParseInt(fields[3], 10, 64)
+		candidates = append(candidates, File{Key: key, Name: path.Base(key), Size: size, MimeType: MimeType(key)})
+	}
+
+	specs, err := g.jsonSpecs(ctx, jsonObjects)
+	if err != nil {
+		return nil, err
+	}
+	var files []File
+	for _, file := range candidates {
+		if object, ok := objects[file.Key]; !ok || specs[object] {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return files, nil
+	}
+
+	modified, err := g.lastChanges(ctx, commit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
+		files[i].LastModified = modified[files[i].Key]
+	}
+	return files, nil
+}
+
+// Get reads a spec file at the source ref, or at the commit, branch or tag
+// given as version
+func (g *Git) Get(ctx context.Context, key, version string) (*File, error) {
+	rev := g.ref
+	if version != "" {
+		if err := validRevision(version); err != nil {
+			return nil, err
+		}
+		rev = version
+	}
+	file, err := g.head(ctx, rev, key)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := g.git(ctx, "cat-file", "blob", rev+":"+key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", key, rev, err)
+	}
+	file.Content = string(content)
+	return file, nil
+}
+
+// Head returns the metadata of a spec file at the source ref
+func (g *Git) Head(ctx context.Context, key string) (*File, error) {
+	return g.head(ctx, g.ref, key)
+}
+
+// Versions lists the commits reachable from the source ref that changed the
+// file, newest first
+func (g *Git) Versions(ctx context.Context, key string) ([]Version, error) {
+	if _, err := g.Head(ctx, key); err != nil {
+		return nil, err
+	}
+	out, err := g.git(ctx, "log", "--format=%H%x1f%ct%x1f%an%x1f%s", g.ref, "--", key)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []Version
+	var objects strings.Builder
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		versions = append(versions, Version{
+			ID:           fields[0],
+			LastModified: formatUnix(fields[1]),
+			Author:       fields[2],
+			Message:      fields[3],
+		})
+		objects.WriteString(fields[0] + ":" + key + "\n")
+	}
+
+	// Commits that deleted the file have no blob and are left out
+	sizes, err := g.gitInput(ctx, objects.String(), "cat-file", "--batch-check")
+	if err != nil {
+		return nil, err
+	}
+	var kept []Version
+	for i, line := range strings.Split(strings.TrimSpace(string(sizes)), "\n") {
+		fields := strings.Fields(line)
+		if i >= len(versions) || len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+		v := versions[i]
+		v.Size, _ = strconv.ParseInt(fields[2], 10, 64)
+		v.Current = len(kept) == 0
+		kept = append(kept, v)
+	}
+	return kept, nil
+}
+
+// Check verifies that the repository can be read at the source ref
+func (g *Git) Check(ctx context.Context) error {
+	_, err := g.resolve(ctx)
+	return err
+}
+
+// head returns the metadata of a file at a revision, dated by the last
+// commit that changed it
+func (g *Git) head(ctx context.Context, rev, key string) (*File, error) {
+	if !IsYAMLFile(key) && !IsJSONFile(key) {
+		return nil, fmt.Errorf("file %s is not a YAML or JSON file", key)
+	}
+	if !fs.ValidPath(key) {
+		return nil, fmt.Errorf("invalid key %s", key)
+	}
+	out, err := g.git(ctx, "cat-file", "-s", rev+":"+key)
+	if err != nil {
+		return nil, fmt.Errorf("file %s not found at %s: %w", key, rev, err)
+	}
+	size, _ := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+
+	date, err := g.git(ctx, "log", "-1", "--format=%ct", rev, "--", key)
+	if err != nil {
+		return nil, err
+	}
+	return &File{
+		Key:          key,
+		Name:         path.Base(key),
+		Size:         size,
+		LastModified: formatUnix(strings.TrimSpace(string(date))),
+		MimeType:     MimeType(key),
+	}, nil
+}
+
+// resolve returns the commit the source ref points at
+func (g *Git) resolve(ctx context.Context) (string, error) {
+	out, err := g.git(ctx, "rev-parse", "--verify", "--quiet", g.ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s in %s: %w", g.ref, g.repo, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// lastChanges maps every path to the date of the last commit that changed it,
+// walking the history of a commit once and reusing the result until the
+// source ref moves
+func (g *Git) lastChanges(ctx context.Context, commit string) (map[string]string, error) {
+	g.mu.Lock()
+	if g.changesAt == commit {
+		defer g.mu.Unlock()
+		return g.changes, nil
+	}
+	g.mu.Unlock()
+
+	out, err := g.git(ctx, "-c", "core.quotePath=false", "log", "--format=%x1e%ct", "--name-only", commit)
+	if err != nil {
+		return nil, err
+	}
+	modified := make(map[string]string)
+	for _, entry := range strings.Split(string(out), "\x1e") {
+		lines := strings.Split(strings.TrimSpace(entry), "\n")
+		if len(lines) < 2 {
+			continue
+		}
+		date := formatUnix(lines[0])
+		for _, key := range lines[1:] {
+			if _, seen := modified[key]; !seen && key != "" {
+				modified[key] = date
+			}
+		}
+	}
+
+	g.mu.Lock()
+	g.changesAt, g.changes = commit, modified
+	g.mu.Unlock()
+	return modified, nil
+}
+
+// jsonSpecs reports which JSON blobs are specs, reading the start of the
+// blobs not seen before in a single git process
+func (g *Git) jsonSpecs(ctx context.Context, objects []string) (map[string]bool, error) {
+	specs := make(map[string]bool, len(objects))
+	var missing []string
+	g.mu.Lock()
+	for _, object := range objects {
+		spec, ok := g.sniffed[object]
+		metrics.CacheLookups.WithLabelValues("git_sniff", metrics.Lookup(ok)).Inc()
+		if ok {
+			specs[object] = spec
+		} else {
+			missing = append(missing, object)
+		}
+	}
+	g.mu.Unlock()
+	if len(missing) == 0 {
+		return specs, nil
+	}
+
+	out, err := g.gitInput(ctx, strings.Join(missing, "\n")+"\n", "cat-file", "--batch")
+	if err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.sniffed == nil {
+		g.sniffed = make(map[string]bool)
+	}
+	// Each blob is a "<object> blob <size>" line, its content and a newline
+	for len(out) > 0 {
+		header, rest, _ := bytes.Cut(out, []byte("\n"))
+		fields := strings.Fields(string(header))
+		if len(fields) != 3 || fields[1] != "blob" {
+			return nil, fmt.Errorf("git cat-file: unexpected output %q", header)
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil || size > len(rest) {
+			return nil, fmt.Errorf("git cat-file: unexpected output %q", header)
+		}
+		content := rest[:size]
+		if len(content) > SniffBytes {
+			content = content[:SniffBytes]
+		}
+		specs[fields[0]] = LooksLikeSpec(content)
+		g.sniffed[fields[0]] = specs[fields[0]]
+		out = bytes.TrimPrefix(rest[size:], []byte("\n"))
+	}
+	return specs, nil
+}
+
+func (g *Git) git(ctx context.Context, args ...string) ([]byte, error) {
+	return g.gitInput(ctx, "", args...)
+}
+
+// gitInput runs a git command in the repository, feeding it input
+func (g *Git) gitInput(ctx context.Context, input string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", g.repo}, args...)...)
+	cmd.Stdin = strings.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// validRevision rejects revisions git would read as options
+func validRevision(rev string) error {
+	if strings.HasPrefix(rev, "-") || strings.ContainsAny(rev, " \t\n\x00") {
+		return fmt.Errorf("invalid git revision %q", rev)
+	}
+	return nil
+}
+
+// formatUnix renders a unix timestamp the way other sources date files
+func formatUnix(value string) string {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return ""
+	}
+	return time.Unix(seconds, 0).UTC().Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,221 @@
+package source
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// gitRepo is a throwaway repository whose commits are dated by the test
+type gitRepo struct {
+	t   *testing.T
+	dir string
+}
+
+func newGitRepo(t *testing.T) *gitRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := &gitRepo{t: t, dir: t.TempDir()}
+	repo.run("", "init", "--quiet", "--initial-branch=main")
+	return repo
+}
+
+// run runs git in the repository, dating commits at date
+func (r *gitRepo) run(date string, args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", r.dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL="+os.DevNull, "GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=Ana", "GIT_AUTHOR_EMAIL=ana@example.com",
+		"GIT_COMMITTER_NAME=Ana", "GIT_COMMITTER_EMAIL=ana@example.com",
+		"GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date,
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commit writes the files and commits them, returning the commit hash
+func (r *gitRepo) commit(date, message string, files map[string]string) string {
+	r.t.Helper()
+	writeFiles(r.t, r.dir, files)
+	r.run(date, "add", "--all")
+	r.run(date, "commit", "--quiet", "--message", message)
+	return r.run(date, "rev-parse", "HEAD")
+}
+
+// newSpecRepo holds cards.yaml in two versions, tagged v1 and v2, and a
+// draft branch adding a spec
+func newSpecRepo(t *testing.T) (*gitRepo, string) {
+	t.Helper()
+	repo := newGitRepo(t)
+	first := repo.commit("2024-01-01T10:00:00Z", "Add cards", map[string]string{
+		"cards.yaml":         "openapi: 3.0.3\ninfo: {version: 1.0.0}\n",
+		"events/schema.json": `{"asyncapi": "3.0.0"}`,
+		"package.json":       `{"name": "specs"}`,
+		"README.md":          "# Specs\n",
+	})
+	repo.run("", "tag", "v1")
+	repo.commit("2024-02-01T10:00:00Z", "Bump cards", map[string]string{
+		"cards.yaml": "openapi: 3.0.3\ninfo: {version: 2.0.0}\n",
+	})
+	repo.run("", "tag", "v2")
+
+	repo.run("", "checkout", "--quiet", "-b", "draft")
+	repo.commit("2024-03-01T10:00:00Z", "Draft accounts", map[string]string{
+		"accounts.yml": "openapi: 3.0.3\n",
+	})
+	repo.run("", "checkout", "--quiet", "main")
+	return repo, first
+}
+
+func newTestGit(t *testing.T, repo *gitRepo, ref string) *Git {
+	t.Helper()
+	g, err := NewGit("git", repo.dir, ref)
+	if err != nil {
+		t.Fatalf("NewGit: %v", err)
+	}
+	return g
+}
+
+func TestGitList(t *testing.T) {
+	repo, _ := newSpecRepo(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		ref    string
+		prefix string
+		want   map[string]string // key to last modified
+	}{
+		{ref: "main", want: map[string]string{"cards.yaml": "2024-02-01 10:00:00", "events/schema.json": "2024-01-01 10:00:00"}},
+		{ref: "main", prefix: "events/", want: map[string]string{"events/schema.json": "2024-01-01 10:00:00"}},
+		{ref: "v1", want: map[string]string{"cards.yaml": "2024-01-01 10:00:00", "events/schema.json": "2024-01-01 10:00:00"}},
+		{ref: "draft", want: map[string]string{"accounts.yml": "2024-03-01 10:00:00", "cards.yaml": "2024-02-01 10:00:00", "events/schema.json": "2024-01-01 10:00:00"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref+"/"+tt.prefix, func(t *testing.T) {
+			files, err := newTestGit(t, repo, tt.ref).List(ctx, tt.prefix)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			got := make(map[string]string)
+			for _, file := range files {
+				got[file.Key] = file.LastModified
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("files = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitListFollowsRef(t *testing.T) {
+	repo, _ := newSpecRepo(t)
+	ctx := context.Background()
+	g := newTestGit(t, repo, "main")
+
+	for i := 0; i < 2; i++ {
+		files, err := g.List(ctx, "cards")
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(files) != 1 || files[0].LastModified != "2024-02-01 10:00:00" {
+			t.Fatalf("listing %d = %+v", i, files)
+		}
+	}
+
+	// Cached dates are dropped once the ref moves
+	repo.commit("2024-04-01T10:00:00Z", "Bump cards again", map[string]string{
+		"cards.yaml": "openapi: 3.0.3\ninfo: {version: 3.0.0}\n",
+	})
+	files, err := g.List(ctx, "cards")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(files) != 1 || files[0].LastModified != "2024-04-01 10:00:00" {
+		t.Errorf("listing after a commit = %+v", files)
+	}
+}
+
+func TestGitVersions(t *testing.T) {
+	repo, first := newSpecRepo(t)
+	ctx := context.Background()
+	g := newTestGit(t, repo, "main")
+
+	versions, err := g.Versions(ctx, "cards.yaml")
+	if err != nil {
+		t.Fatalf("Versions: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("versions = %+v", versions)
+	}
+	if v := versions[0]; !v.Current || v.Message != "Bump cards" || v.Author != "Ana" || v.LastModified != "2024-02-01 10:00:00" {
+		t.Errorf("newest version = %+v", v)
+	}
+	if v := versions[1]; v.Current || v.ID != first || v.Size != 38 {
+		t.Errorf("oldest version = %+v", v)
+	}
+
+	if _, err := g.Versions(ctx, "accounts.yml"); err == nil {
+		t.Error("Versions found a file only on another branch")
+	}
+}
+
+func TestGitGet(t *testing.T) {
+	repo, first := newSpecRepo(t)
+	ctx := context.Background()
+	g := newTestGit(t, repo, "main")
+
+	tests := []struct {
+		name    string
+		key     string
+		version string
+		want    string
+		wantErr bool
+	}{
+		{name: "source ref", key: "cards.yaml", want: "version: 2.0.0"},
+		{name: "tag", key: "cards.yaml", version: "v1", want: "version: 1.0.0"},
+		{name: "commit", key: "cards.yaml", version: first, want: "version: 1.0.0"},
+		{name: "branch", key: "accounts.yml", version: "draft", want: "openapi: 3.0.3"},
+		{name: "missing at ref", key: "accounts.yml", wantErr: true},
+		{name: "unknown revision", key: "cards.yaml", version: "v9", wantErr: true},
+		{name: "option as revision", key: "cards.yaml", version: "--output=/tmp/x", wantErr: true},
+		{name: "path outside repository", key: "../cards.yaml", wantErr: true},
+		{name: "not a spec", key: "README.md", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := g.Get(ctx, tt.key, tt.version)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Get succeeded: %+v", file)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if !strings.Contains(file.Content, tt.want) {
+				t.Errorf("content = %q, want %q", file.Content, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewGit("git", repo.dir, "-c core.pager=x"); err == nil {
+		t.Error("NewGit accepted an option as ref")
+	}
+	if err := newTestGit(t, repo, "missing").Check(ctx); err == nil {
+		t.Error("Check resolved a missing ref")
+	}
+	if uri := g.URI("cards.yaml"); uri != "git+file://"+filepath.ToSlash(repo.dir)+"/cards.yaml" {
+		t.Errorf("URI = %s", uri)
+	}
+}
@@ -0,0 +1,94 @@
+package source
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Source is a place spec files are read from, such as an S3 bucket or a
+// local directory. Keys are slash-separated paths within the source.
+type Source interface {
+	// Name is the configured name of the source
+	Name() string
+	// Root is the URI prefix every file of the source lives under
+	Root() string
+	// URI returns the resource URI of a key
+	URI(key string) string
+	// Key returns the key a resource URI points at, if it belongs to the source
+	Key(uri string) (string, bool)
+
+	// List lists the spec files under prefix
+	List(ctx context.Context, prefix string) ([]File, error)
+	// Get reads a spec file; an empty version is the current one
+	Get(ctx context.Context, key, version string) (*File, error)
+	// Head returns the metadata of a spec file without its content
+	Head(ctx context.Context, key string) (*File, error)
+	// Versions lists the versions of a spec file, newest first
+	Versions(ctx context.Context, key string) ([]Version, error)
+
+	// Check verifies that the source can be read
+	Check(ctx context.Context) error
+}
+
+// File is a YAML or JSON spec file in a source
+type File struct {
+	Key          string
+	Name         string
+	Size         int64
+	LastModified string
+	MimeType     string
+	Content      string
+}
+
+// Version is one version of a spec file
+type Version struct {
+	ID           string
+	Size         int64
+	LastModified string
+	Current      bool
+	// Author and Message describe the change, when the source records them
+	Author  string
+	Message string
+}
+
+// SniffBytes is how much of a JSON file is read to decide whether it is a spec
+const SniffBytes = 4096
+
+// specKeyPattern matches the top-level version key of an OpenAPI, Swagger or
+// AsyncAPI document
+var specKeyPattern = regexp.MustCompile(`"(openapi|swagger|asyncapi)"\s*:`)
+
+// Matches reports whether the name or key of a file contains pattern,
+// ignoring case
+func Matches(file File, pattern string) bool {
+	pattern = strings.ToLower(pattern)
+	return strings.Contains(strings.ToLower(file.Name), pattern) ||
+		strings.Contains(strings.ToLower(file.Key), pattern)
+}
+
+// IsYAMLFile checks if a file is a YAML file based on its extension
+func IsYAMLFile(key string) bool {
+	ext := strings.ToLower(path.Ext(key))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// IsJSONFile checks if a file is a JSON file based on its extension
+func IsJSONFile(key string) bool {
+	return strings.ToLower(path.Ext(key)) == ".json"
+}
+
+// LooksLikeSpec reports whether the start of a JSON file declares an
+// openapi, swagger or asyncapi version
+func LooksLikeSpec(head []byte) bool {
+	return specKeyPattern.Match(head)
+}
+
+// MimeType returns the content type a spec file is served with
+func MimeType(key string) string {
+	if IsJSONFile(key) {
+		return "application/json"
+	}
+	return "application/x-yaml"
+}
@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestSetupFile(t *testing.T) {
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+	path := filepath.Join(t.TempDir(), "spans.json")
+	ctx := context.Background()
+
+	shutdown, err := Setup(ctx, Options{Exporter: ExporterFile, File: path, ServiceVersion: "1.2.3"})
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+
+	parent := Extract(ctx, map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"})
+	_, span := Tracer().Start(parent, "tools/call")
+	span.End()
+	if err := shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var exported struct {
+		Name        string
+		SpanContext struct{ TraceID string }
+		Resource    []struct {
+			Key   string
+			Value struct{ Value interface{} }
+		}
+	}
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatalf("spans file %q: %v", data, err)
+	}
+	if exported.Name != "tools/call" || exported.SpanContext.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("exported span %s in trace %s", exported.Name, exported.SpanContext.TraceID)
+	}
+	if !strings.Contains(string(data), `"s3-mcp-server"`) || !strings.Contains(string(data), `"1.2.3"`) {
+		t.Errorf("exported span lacks the service name and version: %s", data)
+	}
+}
+
+func TestSetupErrors(t *testing.T) {
+	ctx := context.Background()
+	shutdown, err := Setup(ctx, Options{})
+	if err != nil || shutdown(ctx) != nil {
+		t.Errorf("Setup without an exporter = %v", err)
+	}
+	if _, err := Setup(ctx, Options{Exporter: ExporterFile}); err == nil {
+		t.Error("Setup accepted the file exporter without a file")
+	}
+	if _, err := Setup(ctx, Options{Exporter: "jaeger"}); err == nil {
+		t.Error("Setup accepted an unknown exporter")
+	}
+}
@@ -2,18 +2,47 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"gopkg.in/yaml.v3"
+
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/config"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/logging"
 	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/server"
+	"github.com/ander-castiblanco-stori/s3-mcp-server/internal/tracing"
 )
 
 func main() {
+	// Dispatch subcommands before parsing global flags
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "mock":
+			runMock(os.Args[2:])
+			return
+		case "export-collection":
+			runExportCollection(os.Args[2:])
+			return
+		case "config":
+			runConfig(os.Args[2:])
+			return
+		case "doctor":
+			runDoctor(os.Args[2:])
+			return
+		}
+	}
+
 	// Parse command line flags
 	var showVersion = flag.Bool("version", false, "Show version information")
 	var showHelp = flag.Bool("help", false, "Show help information")
+	loadConfig := configFlags(flag.CommandLine)
 	flag.Parse()
 
 	if *showVersion {
@@ -26,31 +55,237 @@ func main() {
 
 	if *showHelp {
 		fmt.Printf("S3 MCP Server - Model Context Protocol server for S3 YAML files\n\n")
-		fmt.Printf("Usage: %s [options]\n\n", os.Args[0])
+		fmt.Printf("Usage: %s [options]\n", os.Args[0])
+		fmt.Printf("       %s mock --key <spec key> [--source name] [--addr host:port]\n", os.Args[0])
+		fmt.Printf("       %s export-collection (--key <spec key> | --prefix <prefix>) [--source name] [--out file]\n", os.Args[0])
+		fmt.Printf("       %s config print [options]\n", os.Args[0])
+		fmt.Printf("       %s doctor [options]\n\n", os.Args[0])
 		fmt.Printf("Options:\n")
 		flag.PrintDefaults()
 		fmt.Printf("\nEnvironment Variables:\n")
-		fmt.Printf("  S3_BUCKET      S3 bucket name (required)\n")
+		fmt.Printf("  S3_BUCKET      S3 bucket name (required unless SPECS_DIR, SPECS_GIT or S3_SOURCES is set)\n")
+		fmt.Printf("  S3_PREFIX      Prefix within S3_BUCKET (optional)\n")
+		fmt.Printf("  S3_SOURCES     Comma-separated names of more sources, each set with\n")
+		fmt.Printf("                 S3_SOURCE_<NAME>_BUCKET, _PREFIX, _REGION, _ACCESS_KEY_ID,\n")
+		fmt.Printf("                 _SECRET_ACCESS_KEY, _ENDPOINT, _PROFILE, _ROLE_ARN, _EXTERNAL_ID,\n")
+		fmt.Printf("                 _ROLE_SESSION_NAME and _WEB_IDENTITY_TOKEN_FILE, or _DIR,\n")
+		fmt.Printf("                 or _GIT and _REF\n")
+		fmt.Printf("  SPECS_DIR      Local directory of specs (optional)\n")
+		fmt.Printf("  SPECS_GIT      Local clone of a git repository of specs (optional)\n")
+		fmt.Printf("  SPECS_GIT_REF  Branch, tag or commit read from SPECS_GIT (default: HEAD)\n")
 		fmt.Printf("  S3_REGION      AWS region (default: us-east-1)\n")
 		fmt.Printf("  S3_ACCESS_KEY  AWS access key (optional)\n")
 		fmt.Printf("  S3_SECRET_KEY  AWS secret key (optional)\n")
 		fmt.Printf("  S3_ENDPOINT    Custom S3 endpoint (optional)\n")
-		fmt.Printf("  LOG_LEVEL      Log level (default: info)\n")
+		fmt.Printf("  S3_PROFILE     Named AWS profile, used instead of static keys (optional)\n")
+		fmt.Printf("  S3_ROLE_ARN    Role to assume, with S3_EXTERNAL_ID, S3_ROLE_SESSION_NAME\n")
+		fmt.Printf("                 and S3_WEB_IDENTITY_TOKEN_FILE (optional)\n")
+		fmt.Printf("  LOG_LEVEL      Log level: debug, info, warn or error (default: info)\n")
+		fmt.Printf("  LOG_FORMAT     Log format: text or json (default: text)\n")
+		fmt.Printf("  LOG_FILE       File logs are appended to (default: stderr)\n")
+		fmt.Printf("  METRICS_ADDR   Address /metrics, /healthz and /readyz are served on (optional)\n")
+		fmt.Printf("  TRACING_EXPORTER  Export OpenTelemetry traces to otlp or file (optional), with\n")
+		fmt.Printf("                 TRACING_ENDPOINT (OTLP/HTTP URL) or TRACING_FILE\n")
+		fmt.Printf("\nSettings come from flags, then environment variables, then the config file\n")
+		fmt.Printf("(--config, default: %s when present).\n", config.DefaultPath())
 		fmt.Printf("\nFor more information, visit:\n")
 		fmt.Printf("https://github.com/andersoncastiblanco/s3-mcp-server\n")
 		os.Exit(0)
 	}
 
+	if err := run(loadConfig()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run serves MCP over stdio until the client disconnects. Spans are flushed
+// before it returns, also on failure, since log.Fatal skips deferred calls.
+func run(cfg *config.Config) error {
 	ctx := context.Background()
 
+	// Export spans when tracing is configured, flushing them on exit
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Options{
+		Exporter:       cfg.TracingExporter,
+		Endpoint:       cfg.TracingEndpoint,
+		File:           cfg.TracingFile,
+		ServiceVersion: Version,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("Failed to flush traces", "error", err)
+		}
+	}()
+
 	// Initialize the MCP server
-	mcpServer, err := server.New()
+	mcpServer, err := server.New(cfg)
 	if err != nil {
-		log.Fatalf("Failed to create MCP server: %v", err)
+		return fmt.Errorf("failed to create MCP server: %w", err)
 	}
 
 	// Start the server
 	if err := mcpServer.Start(ctx); err != nil {
-		log.Fatalf("Failed to start MCP server: %v", err)
+		return fmt.Errorf("failed to start MCP server: %w", err)
+	}
+	return nil
+}
+
+// runMock serves a mock HTTP server for a spec until interrupted
+func runMock(args []string) {
+	mockFlags := flag.NewFlagSet("mock", flag.ExitOnError)
+	key := mockFlags.String("key", "", "S3 key of the OpenAPI spec to mock (required)")
+	source := mockFlags.String("source", "", "Name of the source holding the spec (default: the one source that has it)")
+	addr := mockFlags.String("addr", "127.0.0.1:4010", "Listen address")
+	loadConfig := configFlags(mockFlags)
+	mockFlags.Parse(args)
+
+	if *key == "" {
+		fmt.Fprintln(os.Stderr, "mock: --key is required")
+		mockFlags.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	mcpServer, err := server.New(loadConfig())
+	if err != nil {
+		log.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	if err := mcpServer.ServeMock(ctx, *source, *key, *addr); err != nil {
+		log.Fatalf("Mock server failed: %v", err)
+	}
+}
+
+// runExportCollection writes a Postman collection for one or more specs
+func runExportCollection(args []string) {
+	exportFlags := flag.NewFlagSet("export-collection", flag.ExitOnError)
+	key := exportFlags.String("key", "", "S3 key of a single OpenAPI spec")
+	prefix := exportFlags.String("prefix", "", "Export every spec under this prefix")
+	source := exportFlags.String("source", "", "Only read specs from this source (default: the one source holding --key, every source for --prefix)")
+	out := exportFlags.String("out", "", "Output file (default: stdout)")
+	loadConfig := configFlags(exportFlags)
+	exportFlags.Parse(args)
+
+	if *key == "" && *prefix == "" {
+		fmt.Fprintln(os.Stderr, "export-collection: --key or --prefix is required")
+		exportFlags.Usage()
+		os.Exit(2)
+	}
+
+	mcpServer, err := server.New(loadConfig())
+	if err != nil {
+		log.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	collection, err := mcpServer.ExportCollection(context.Background(), *source, *key, *prefix)
+	if err != nil {
+		log.Fatalf("Failed to export collection: %v", err)
+	}
+
+	data, err := json.MarshalIndent(collection, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode collection: %v", err)
+	}
+
+	if *out == "" {
+		fmt.Println(string(data))
+		return
+	}
+	if err := os.WriteFile(*out, append(data, '\n'), 0o644); err != nil {
+		log.Fatalf("Failed to write %s: %v", *out, err)
+	}
+	log.Printf("Collection written to %s", *out)
+}
+
+// runConfig handles the config subcommands
+func runConfig(args []string) {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "usage: config print [options]")
+		os.Exit(2)
+	}
+
+	printFlags := flag.NewFlagSet("config print", flag.ExitOnError)
+	loadConfig := configFlags(printFlags)
+	printFlags.Parse(args[1:])
+
+	cfg := loadConfig()
+	if cfg.File != "" {
+		fmt.Printf("# Effective configuration, including %s\n", cfg.File)
+	} else {
+		fmt.Printf("# Effective configuration (no config file)\n")
+	}
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg.Redacted()); err != nil {
+		log.Fatalf("Failed to encode configuration: %v", err)
+	}
+}
+
+// runDoctor checks every source and prints diagnostics, exiting non-zero
+// when a check fails
+func runDoctor(args []string) {
+	doctorFlags := flag.NewFlagSet("doctor", flag.ExitOnError)
+	timeout := doctorFlags.Duration("timeout", 30*time.Second, "Time allowed for all checks")
+	loadConfig := configFlags(doctorFlags)
+	doctorFlags.Parse(args)
+
+	mcpServer, err := server.New(loadConfig())
+	if err != nil {
+		log.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	if !mcpServer.Doctor(ctx, os.Stdout) {
+		cancel()
+		os.Exit(1)
+	}
+}
+
+// configFlags registers the --config flag and the flags overriding single
+// settings, and returns a function loading the configuration and setting up
+// logging once the flags are parsed
+func configFlags(flags *flag.FlagSet) func() *config.Config {
+	path := flags.String("config", "", "Config file (default: "+config.DefaultPath()+" when present)")
+	overrides := map[string]*string{
+		"S3_BUCKET":        flags.String("bucket", "", "S3 bucket of the default source (overrides S3_BUCKET)"),
+		"S3_PREFIX":        flags.String("s3-prefix", "", "Prefix within the default bucket (overrides S3_PREFIX)"),
+		"S3_REGION":        flags.String("region", "", "AWS region (overrides S3_REGION)"),
+		"S3_ENDPOINT":      flags.String("endpoint", "", "Custom S3 endpoint (overrides S3_ENDPOINT)"),
+		"S3_PROFILE":       flags.String("profile", "", "Named AWS profile (overrides S3_PROFILE)"),
+		"S3_ROLE_ARN":      flags.String("role-arn", "", "Role to assume for S3 access (overrides S3_ROLE_ARN)"),
+		"SPECS_DIR":        flags.String("specs-dir", "", "Local directory of specs (overrides SPECS_DIR)"),
+		"SPECS_GIT":        flags.String("specs-git", "", "Local clone of a git repository of specs (overrides SPECS_GIT)"),
+		"SPECS_GIT_REF":    flags.String("specs-git-ref", "", "Branch, tag or commit read from the git repository (overrides SPECS_GIT_REF)"),
+		"LOG_LEVEL":        flags.String("log-level", "", "Log level (overrides LOG_LEVEL)"),
+		"LOG_FORMAT":       flags.String("log-format", "", "Log format, text or json (overrides LOG_FORMAT)"),
+		"LOG_FILE":         flags.String("log-file", "", "File logs are appended to instead of stderr (overrides LOG_FILE)"),
+		"METRICS_ADDR":     flags.String("metrics-addr", "", "Address /metrics, /healthz and /readyz are served on, such as :9090 (overrides METRICS_ADDR)"),
+		"TRACING_EXPORTER": flags.String("tracing-exporter", "", "Export traces to otlp or file (overrides TRACING_EXPORTER)"),
+		"MAX_OUTPUT_CHARS": flags.String("max-output-chars", "", "Default output budget in characters (overrides MAX_OUTPUT_CHARS)"),
+	}
+
+	return func() *config.Config {
+		set := make(map[string]string)
+		for key, value := range overrides {
+			if *value != "" {
+				set[key] = *value
+			}
+		}
+		cfg, err := config.Load(config.Options{File: *path, Flags: set})
+		if err != nil {
+			log.Fatalf("Failed to load configuration: %v", err)
+		}
+
+		logger, err := logging.New(logging.Options{Level: cfg.LogLevel, Format: cfg.LogFormat, File: cfg.LogFile})
+		if err != nil {
+			log.Fatalf("Failed to set up logging: %v", err)
+		}
+		slog.SetDefault(logger)
+		return cfg
 	}
 }
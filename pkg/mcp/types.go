@@ -22,6 +22,14 @@ type ResponseMessage struct {
 	Error   *ErrorObj   `json:"error,omitempty"`
 }
 
+// NotificationMessage represents an MCP notification, which has no ID and
+// gets no response
+type NotificationMessage struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
 // ErrorObj represents an MCP error
 type ErrorObj struct {
 	Code    int         `json:"code"`
@@ -61,6 +69,7 @@ type ServerCapabilities struct {
 	Resources *ResourceCapabilities `json:"resources,omitempty"`
 	Tools     *ToolCapabilities     `json:"tools,omitempty"`
 	Prompts   *PromptCapabilities   `json:"prompts,omitempty"`
+	Logging   *LoggingCapabilities  `json:"logging,omitempty"`
 }
 
 // ResourceCapabilities represents resource capabilities
@@ -79,6 +88,9 @@ type PromptCapabilities struct {
 	ListChanged bool `json:"listChanged,omitempty"`
 }
 
+// LoggingCapabilities represents logging capabilities
+type LoggingCapabilities struct{}
+
 // ServerInfo represents server information
 type ServerInfo struct {
 	Name    string `json:"name"`
@@ -104,15 +116,17 @@ type ResourceContent struct {
 
 // Tool represents an MCP tool
 type Tool struct {
-	Name        string                 `json:"name"`
-	Description string                 `json:"description,omitempty"`
-	InputSchema map[string]interface{} `json:"inputSchema"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description,omitempty"`
+	InputSchema  map[string]interface{} `json:"inputSchema"`
+	OutputSchema map[string]interface{} `json:"outputSchema,omitempty"`
 }
 
 // ToolResult represents a tool execution result
 type ToolResult struct {
-	Content []ToolContent `json:"content,omitempty"`
-	IsError bool          `json:"isError,omitempty"`
+	Content           []ToolContent `json:"content,omitempty"`
+	StructuredContent interface{}   `json:"structuredContent,omitempty"`
+	IsError           bool          `json:"isError,omitempty"`
 }
 
 // ToolContent represents tool content
@@ -148,6 +162,18 @@ type CallToolParams struct {
 	Arguments map[string]interface{} `json:"arguments,omitempty"`
 }
 
+// SetLevelParams represents parameters for setting the log level
+type SetLevelParams struct {
+	Level string `json:"level"`
+}
+
+// LoggingMessageParams represents a log message sent to the client
+type LoggingMessageParams struct {
+	Level  string      `json:"level"`
+	Logger string      `json:"logger,omitempty"`
+	Data   interface{} `json:"data"`
+}
+
 // Helper functions
 
 // NewRequestMessage creates a new request message
@@ -169,6 +195,15 @@ func NewResponseMessage(id interface{}, result interface{}) *ResponseMessage {
 	}
 }
 
+// NewNotification creates a new notification message
+func NewNotification(method string, params interface{}) *NotificationMessage {
+	return &NotificationMessage{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	}
+}
+
 // NewErrorResponse creates a new error response
 func NewErrorResponse(id interface{}, code int, message string) *ResponseMessage {
 	return &ResponseMessage{